@@ -0,0 +1,248 @@
+// Package adapter implements the agentx out-of-process adapter protocol for
+// adapters written in Go.
+//
+// An adapter is an executable named agentx-adapter-<name> on PATH. agentx
+// sends it JSON-RPC 2.0 requests, one per line on stdin, and reads one
+// response line per request from stdout. A Go adapter implements Handler
+// plus the capability handlers it supports and calls Main:
+//
+//	func main() {
+//		adapter.Main(&myAgent{})
+//	}
+package adapter
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/agentsdance/agentx/adapter/protocol"
+)
+
+// Protocol types shared with agentx
+type (
+	MCPServerSpec = protocol.MCPServerSpec
+	Capabilities  = protocol.Capabilities
+	MCPScope      = protocol.MCPScope
+	Description   = protocol.Description
+	Params        = protocol.Params
+	Request       = protocol.Request
+	Response      = protocol.Response
+	Error         = protocol.Error
+)
+
+// ErrUnsupported may be returned (or wrapped) by handlers for operations
+// the agent can't perform; agentx reports it as an unsupported capability
+var ErrUnsupported = errors.New("operation not supported")
+
+// Handler is implemented by every adapter
+type Handler interface {
+	// Describe identifies the agent. Capabilities left empty are filled in
+	// from the capability handlers the adapter implements.
+	Describe() (Description, error)
+}
+
+// Config scopes of MCP operations
+const (
+	MCPScopeUser    = protocol.MCPScopeUser
+	MCPScopeLocal   = protocol.MCPScopeLocal
+	MCPScopeProject = protocol.MCPScopeProject
+)
+
+// MCPHandler is implemented by adapters that manage MCP servers. Scopes
+// other than those in Description.MCPScopes (by default only user) should
+// return ErrUnsupported.
+type MCPHandler interface {
+	HasMCP(name string, scope MCPScope) (bool, error)
+	InstallMCP(name string, spec MCPServerSpec, scope MCPScope) error
+	RemoveMCP(name string, scope MCPScope) error
+	ListMCPs(scope MCPScope) (map[string]MCPServerSpec, error)
+}
+
+// SkillHandler is implemented by adapters that manage skills
+type SkillHandler interface {
+	HasSkill(name string) (bool, error)
+	InstallSkill(name, source string) error
+	RemoveSkill(name string) error
+}
+
+// PluginHandler is implemented by adapters that manage plugins
+type PluginHandler interface {
+	HasPlugin(name string) (bool, error)
+	InstallPlugin(name, source string) error
+	RemovePlugin(name string) error
+}
+
+// InstructionsHandler is implemented by adapters with an instructions file
+type InstructionsHandler interface {
+	ReadInstructions() (string, error)
+	WriteInstructions(content string) error
+}
+
+// HomeDir returns the home directory the adapter should resolve the agent's
+// config against. It is the user's home directory unless agentx runs
+// re-rooted (--root or AGENTX_HOME), in which case it is the root.
+func HomeDir() (string, error) {
+	if root := os.Getenv(protocol.HomeEnvVar); root != "" {
+		return filepath.Abs(root)
+	}
+	return os.UserHomeDir()
+}
+
+// Main serves h on stdin and stdout, exiting on error
+func Main(h Handler) {
+	if err := Serve(h, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// Serve answers requests read from r until r is exhausted
+func Serve(h Handler, r io.Reader, w io.Writer) error {
+	reader := bufio.NewReader(r)
+	enc := json.NewEncoder(w)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if encErr := enc.Encode(handle(h, line)); encErr != nil {
+				return encErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func handle(h Handler, line []byte) Response {
+	var req Request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return Response{JSONRPC: "2.0", Error: &Error{Code: protocol.ErrCodeParse, Message: err.Error()}}
+	}
+	resp := Response{JSONRPC: "2.0", ID: req.ID}
+
+	var params Params
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			resp.Error = &Error{Code: protocol.ErrCodeInvalidParams, Message: err.Error()}
+			return resp
+		}
+	}
+
+	result, err := dispatch(h, req.Method, params)
+	if err != nil {
+		resp.Error = toRPCError(err)
+		return resp
+	}
+	raw, err := json.Marshal(result)
+	if err != nil {
+		resp.Error = &Error{Code: protocol.ErrCodeInternal, Message: err.Error()}
+		return resp
+	}
+	resp.Result = raw
+	return resp
+}
+
+func toRPCError(err error) *Error {
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+	if errors.Is(err, ErrUnsupported) {
+		return &Error{Code: protocol.ErrCodeUnsupported, Message: err.Error()}
+	}
+	return &Error{Code: protocol.ErrCodeInternal, Message: err.Error()}
+}
+
+func unsupported(method string) error {
+	return &Error{Code: protocol.ErrCodeUnsupported, Message: method + " is not supported"}
+}
+
+func dispatch(h Handler, method string, p Params) (interface{}, error) {
+	mcp, hasMCP := h.(MCPHandler)
+	skill, hasSkill := h.(SkillHandler)
+	plugin, hasPlugin := h.(PluginHandler)
+	instructions, hasInstructions := h.(InstructionsHandler)
+
+	switch method {
+	case protocol.MethodDescribe:
+		desc, err := h.Describe()
+		if err != nil {
+			return nil, err
+		}
+		if desc.Capabilities == (Capabilities{}) {
+			desc.Capabilities = Capabilities{
+				MCP:          hasMCP,
+				Skills:       hasSkill,
+				Plugins:      hasPlugin,
+				Instructions: hasInstructions,
+			}
+		}
+		return desc, nil
+
+	case protocol.MethodHasMCP, protocol.MethodInstallMCP, protocol.MethodRemoveMCP, protocol.MethodListMCPs:
+		if !hasMCP {
+			return nil, unsupported(method)
+		}
+		scope := p.Scope
+		if scope == "" {
+			scope = MCPScopeUser
+		}
+		switch method {
+		case protocol.MethodHasMCP:
+			return mcp.HasMCP(p.Name, scope)
+		case protocol.MethodInstallMCP:
+			if p.Spec == nil {
+				return nil, &Error{Code: protocol.ErrCodeInvalidParams, Message: "spec is required"}
+			}
+			return nil, mcp.InstallMCP(p.Name, *p.Spec, scope)
+		case protocol.MethodRemoveMCP:
+			return nil, mcp.RemoveMCP(p.Name, scope)
+		default:
+			return mcp.ListMCPs(scope)
+		}
+
+	case protocol.MethodHasSkill, protocol.MethodInstallSkill, protocol.MethodRemoveSkill:
+		if !hasSkill {
+			return nil, unsupported(method)
+		}
+		switch method {
+		case protocol.MethodHasSkill:
+			return skill.HasSkill(p.Name)
+		case protocol.MethodInstallSkill:
+			return nil, skill.InstallSkill(p.Name, p.Source)
+		default:
+			return nil, skill.RemoveSkill(p.Name)
+		}
+
+	case protocol.MethodHasPlugin, protocol.MethodInstallPlugin, protocol.MethodRemovePlugin:
+		if !hasPlugin {
+			return nil, unsupported(method)
+		}
+		switch method {
+		case protocol.MethodHasPlugin:
+			return plugin.HasPlugin(p.Name)
+		case protocol.MethodInstallPlugin:
+			return nil, plugin.InstallPlugin(p.Name, p.Source)
+		default:
+			return nil, plugin.RemovePlugin(p.Name)
+		}
+
+	case protocol.MethodReadInstructions, protocol.MethodWriteInstructions:
+		if !hasInstructions {
+			return nil, unsupported(method)
+		}
+		if method == protocol.MethodReadInstructions {
+			return instructions.ReadInstructions()
+		}
+		return nil, instructions.WriteInstructions(p.Content)
+	}
+	return nil, &Error{Code: protocol.ErrCodeMethodNotFound, Message: "unknown method " + method}
+}
@@ -0,0 +1,124 @@
+package adapter
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/agentsdance/agentx/adapter/protocol"
+)
+
+type mcpOnly struct {
+	servers map[string]MCPServerSpec
+}
+
+func (h *mcpOnly) Describe() (Description, error) {
+	return Description{Name: "Test"}, nil
+}
+
+func (h *mcpOnly) HasMCP(name string, scope MCPScope) (bool, error) {
+	_, ok := h.servers[name]
+	return ok, nil
+}
+
+func (h *mcpOnly) InstallMCP(name string, spec MCPServerSpec, scope MCPScope) error {
+	h.servers[name] = spec
+	return nil
+}
+
+func (h *mcpOnly) RemoveMCP(name string, scope MCPScope) error {
+	if name == "builtin" {
+		return fmt.Errorf("removing %s: %w", name, ErrUnsupported)
+	}
+	delete(h.servers, name)
+	return nil
+}
+
+func (h *mcpOnly) ListMCPs(scope MCPScope) (map[string]MCPServerSpec, error) {
+	return h.servers, nil
+}
+
+func serve(t *testing.T, h Handler, requests ...string) []Response {
+	t.Helper()
+	var out strings.Builder
+	if err := Serve(h, strings.NewReader(strings.Join(requests, "\n")), &out); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+	var responses []Response
+	scanner := bufio.NewScanner(strings.NewReader(out.String()))
+	for scanner.Scan() {
+		var resp Response
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid response %q: %v", scanner.Text(), err)
+		}
+		responses = append(responses, resp)
+	}
+	if len(responses) != len(requests) {
+		t.Fatalf("got %d responses, want %d", len(responses), len(requests))
+	}
+	return responses
+}
+
+func TestServeDescribeFillsCapabilities(t *testing.T) {
+	resp := serve(t, &mcpOnly{}, `{"jsonrpc":"2.0","id":7,"method":"describe"}`)[0]
+	if resp.Error != nil || resp.ID != 7 {
+		t.Fatalf("describe response = %+v", resp)
+	}
+	var desc Description
+	if err := json.Unmarshal(resp.Result, &desc); err != nil {
+		t.Fatal(err)
+	}
+	want := Capabilities{MCP: true}
+	if desc.Name != "Test" || desc.Capabilities != want {
+		t.Errorf("describe = %+v, want MCP-only Test", desc)
+	}
+}
+
+func TestServeMCP(t *testing.T) {
+	h := &mcpOnly{servers: map[string]MCPServerSpec{}}
+	responses := serve(t, h,
+		`{"jsonrpc":"2.0","id":1,"method":"mcp/install","params":{"name":"ctx","spec":{"command":"npx","args":["-y","pkg"]}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"mcp/has","params":{"name":"ctx"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"mcp/list"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"mcp/install","params":{"name":"bad"}}`,
+	)
+	if responses[0].Error != nil {
+		t.Fatalf("install error = %v", responses[0].Error)
+	}
+	if string(responses[1].Result) != "true" {
+		t.Errorf("has = %s, want true", responses[1].Result)
+	}
+	var servers map[string]MCPServerSpec
+	if err := json.Unmarshal(responses[2].Result, &servers); err != nil {
+		t.Fatal(err)
+	}
+	if got := servers["ctx"]; got.Command != "npx" || len(got.Args) != 2 {
+		t.Errorf("list = %+v", servers)
+	}
+	if err := responses[3].Error; err == nil || err.Code != protocol.ErrCodeInvalidParams {
+		t.Errorf("install without spec error = %v, want invalid params", err)
+	}
+}
+
+func TestServeErrors(t *testing.T) {
+	h := &mcpOnly{servers: map[string]MCPServerSpec{}}
+	responses := serve(t, h,
+		`{"jsonrpc":"2.0","id":1,"method":"skills/has","params":{"name":"x"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"mcp/remove","params":{"name":"builtin"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"bogus"}`,
+		`not json`,
+	)
+	wantCodes := []int{
+		protocol.ErrCodeUnsupported,
+		protocol.ErrCodeUnsupported,
+		protocol.ErrCodeMethodNotFound,
+		protocol.ErrCodeParse,
+	}
+	for i, code := range wantCodes {
+		if err := responses[i].Error; err == nil || err.Code != code {
+			t.Errorf("response %d error = %v, want code %d", i, err, code)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Package protocol defines the messages agentx exchanges with out-of-process
+// adapters. It depends only on the standard library, so that adapters can
+// use it without linking agentx itself.
+//
+// Adapters are executables named agentx-adapter-<name> on PATH. agentx
+// starts the adapter for each call, writes one JSON-RPC 2.0 request as a
+// single line on its stdin, closes stdin and reads the response line from
+// its stdout. Adapters may serve several requests per process; they should
+// exit when stdin is closed.
+package protocol
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// AdapterPrefix is the executable name prefix of out-of-process adapters
+const AdapterPrefix = "agentx-adapter-"
+
+// HomeEnvVar is set to the root agentx runs re-rooted at (--root or
+// AGENTX_HOME); adapters resolve the agent's config against it instead of
+// the user's home directory
+const HomeEnvVar = "AGENTX_HOME"
+
+// Adapter protocol methods. Capability methods are named
+// "<capability>/<operation>".
+const (
+	MethodDescribe          = "describe"
+	MethodHasMCP            = "mcp/has"
+	MethodInstallMCP        = "mcp/install"
+	MethodRemoveMCP         = "mcp/remove"
+	MethodListMCPs          = "mcp/list"
+	MethodHasSkill          = "skills/has"
+	MethodInstallSkill      = "skills/install"
+	MethodRemoveSkill       = "skills/remove"
+	MethodHasPlugin         = "plugins/has"
+	MethodInstallPlugin     = "plugins/install"
+	MethodRemovePlugin      = "plugins/remove"
+	MethodReadInstructions  = "instructions/read"
+	MethodWriteInstructions = "instructions/write"
+)
+
+// Adapter protocol error codes. The first four are the standard JSON-RPC
+// codes; ErrCodeUnsupported reports a capability the adapter lacks.
+const (
+	ErrCodeParse          = -32700
+	ErrCodeInvalidParams  = -32602
+	ErrCodeMethodNotFound = -32601
+	ErrCodeInternal       = -32603
+	ErrCodeUnsupported    = -32000
+)
+
+// MCPScope indicates which config file an MCP operation targets
+type MCPScope string
+
+const (
+	// MCPScopeUser is the agent's global config (~/.claude.json, ...)
+	MCPScopeUser MCPScope = "user"
+	// MCPScopeLocal is private per-project config kept in the agent's
+	// global config (Claude Code's projects["/abs/path"].mcpServers)
+	MCPScopeLocal MCPScope = "local"
+	// MCPScopeProject is the config committed in the project (.mcp.json, ...)
+	MCPScopeProject MCPScope = "project"
+)
+
+// MCPServerSpec is the agent-independent description of an MCP server
+type MCPServerSpec struct {
+	// Command, Args, Env and Cwd describe a local (stdio) server
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	Cwd     string            `json:"cwd,omitempty"`
+
+	// URL and Headers describe a remote server
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Transport is "stdio", "sse" or "http", or empty when the source
+	// config left it implicit
+	Transport string `json:"transport,omitempty"`
+	// TimeoutMS is the startup/request timeout in milliseconds, zero when
+	// unset
+	TimeoutMS int64 `json:"timeout_ms,omitempty"`
+	// Disabled marks servers that are configured but switched off
+	Disabled bool `json:"disabled,omitempty"`
+	// AutoApprove lists the tools the agent may call without asking
+	AutoApprove []string `json:"auto_approve,omitempty"`
+
+	// Scope is the config the server was listed from; it is set by
+	// mcp/list and ignored when installing
+	Scope MCPScope `json:"scope,omitempty"`
+}
+
+// Capabilities describes which capabilities an adapter supports
+type Capabilities struct {
+	MCP          bool `json:"mcp"`
+	Skills       bool `json:"skills"`
+	Plugins      bool `json:"plugins"`
+	Instructions bool `json:"instructions"`
+}
+
+// Request is a JSON-RPC request sent to an adapter
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int64           `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a JSON-RPC response returned by an adapter
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int64           `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is a JSON-RPC error returned by an adapter
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("adapter error %d: %s", e.Code, e.Message)
+}
+
+// Params carries the arguments of every adapter method; each method uses
+// the fields it needs
+type Params struct {
+	// Name is the MCP server, skill or plugin name
+	Name string `json:"name,omitempty"`
+	// Scope is the config scope of mcp/* methods, "user" when omitted
+	Scope MCPScope `json:"scope,omitempty"`
+	// Spec is the server to install for mcp/install
+	Spec *MCPServerSpec `json:"spec,omitempty"`
+	// Source is the skill or plugin source for */install
+	Source string `json:"source,omitempty"`
+	// Content is the new instructions for instructions/write
+	Content string `json:"content,omitempty"`
+}
+
+// Description is the result of the describe method
+type Description struct {
+	Name             string       `json:"name"`
+	Aliases          []string     `json:"aliases,omitempty"`
+	ConfigPath       string       `json:"config_path"`
+	Exists           bool         `json:"exists"`
+	Capabilities     Capabilities `json:"capabilities"`
+	MCPScopes        []MCPScope   `json:"mcp_scopes,omitempty"`
+	InstructionsPath string       `json:"instructions_path,omitempty"`
+	// Binary and Version describe the agent executable, when the adapter
+	// found one
+	Binary  string `json:"binary,omitempty"`
+	Version string `json:"version,omitempty"`
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/agentsdance/agentx/internal/agent"
+	"github.com/spf13/cobra"
+)
+
+var agentsJSON bool
+
+var agentsCmd = &cobra.Command{
+	Use:   "agents",
+	Short: "Detect installed agents and their versions",
+	Long: `Detect installed agents by looking for their executables on PATH (claude,
+codex, cn, gemini, goose, opencode, droid, cursor-agent, code, windsurf, zed)
+and their config files, and report the version each executable prints for
+--version.
+
+An agent whose config exists but whose executable was not found is reported
+as config-only: either a leftover config or an agent installed outside PATH.
+Agents without an executable (Claude Desktop, Cline, Roo Code) are reported
+as installed when their config exists. User definitions and adapters that
+fail to load are skipped with a warning on stderr.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		agents, err := agent.LoadAllAgents()
+		if err != nil {
+			for _, line := range strings.Split(err.Error(), "\n") {
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", line)
+			}
+		}
+		results := agent.DetectAll(agents)
+
+		if agentsJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(results); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "AGENT\tSTATUS\tVERSION\tBINARY\tCONFIG")
+		fmt.Fprintln(w, "-----\t------\t-------\t------\t------")
+		for _, r := range results {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Agent, r.Status, versionLabel(r), dash(r.Binary), r.ConfigPath)
+		}
+		w.Flush()
+	},
+}
+
+var (
+	instanceFrom string
+	instanceDir  string
+	instanceEnv  []string
+)
+
+var agentsAddCmd = &cobra.Command{
+	Use:   "add <agent>@<profile>",
+	Short: "Register a named instance of an agent",
+	Long: `Register a named instance of an agent that uses another config directory,
+such as a second Claude Code profile:
+
+  agentx agents add claude@work --dir ~/.claude-work
+
+--dir sets the agent's config directory variable (CLAUDE_CONFIG_DIR,
+CODEX_HOME, GEMINI_CLI_HOME or XDG_CONFIG_HOME for opencode); use --env for
+anything else. The instance is saved to ~/.agentx/agents.d and shows up as
+its own agent everywhere, including --agent claude@work.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		env := make(map[string]string, len(instanceEnv))
+		for _, kv := range instanceEnv {
+			key, value, ok := strings.Cut(kv, "=")
+			if !ok || key == "" {
+				fmt.Fprintf(os.Stderr, "Error: --env %q is not KEY=VALUE\n", kv)
+				os.Exit(1)
+			}
+			env[key] = value
+		}
+		path, err := agent.AddInstance(agent.Definition{
+			Name:    args[0],
+			Extends: instanceFrom,
+			Dir:     instanceDir,
+			Env:     env,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Added %s (%s)\n", args[0], path)
+	},
+}
+
+var agentsRemoveCmd = &cobra.Command{
+	Use:   "remove <agent>@<profile>",
+	Short: "Remove a named agent instance",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := agent.RemoveInstance(args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Removed %s\n", args[0])
+	},
+}
+
+// versionLabel returns the detected version, or what the binary printed
+// when it held no recognisable version
+func versionLabel(r agent.DetectionResult) string {
+	switch {
+	case r.Version != "":
+		return r.Version
+	case r.VersionOutput != "":
+		return r.VersionOutput
+	case r.VersionError != "":
+		return "unknown"
+	}
+	return "-"
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func init() {
+	agentsCmd.Flags().BoolVar(&agentsJSON, "json", false, "Print detection results as JSON")
+
+	agentsAddCmd.Flags().StringVar(&instanceFrom, "from", "", "Agent to extend (default: the name before @)")
+	agentsAddCmd.Flags().StringVar(&instanceDir, "dir", "", "Config directory for the instance")
+	agentsAddCmd.Flags().StringArrayVar(&instanceEnv, "env", nil, "Environment override as KEY=VALUE (repeatable)")
+	agentsCmd.AddCommand(agentsAddCmd)
+	agentsCmd.AddCommand(agentsRemoveCmd)
+}
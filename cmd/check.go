@@ -25,11 +25,16 @@ var checkCmd = &cobra.Command{
 			agents = agent.GetAllAgents()
 		}
 
+		width := nameWidth(agents)
 		fmt.Println("Playwright MCP Status")
 		fmt.Println("---------------------")
 		for _, a := range agents {
 			status := "not configured"
-			has, err := a.HasPlaywright()
+			has := false
+			host, err := agent.AsMCPHost(a)
+			if err == nil {
+				has, err = host.HasMCP("playwright", agent.MCPScopeUser)
+			}
 			if err != nil {
 				status = fmt.Sprintf("error: %v", err)
 			} else if has {
@@ -37,7 +42,7 @@ var checkCmd = &cobra.Command{
 			} else if !a.Exists() {
 				status = "config not found"
 			}
-			fmt.Printf("%-12s %s\n", a.Name(), status)
+			fmt.Printf("%-*s %s\n", width, a.Name(), status)
 		}
 	},
 }
@@ -2,22 +2,55 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/agentsdance/agentx/internal/agent"
+	"github.com/agentsdance/agentx/internal/backup"
 	"github.com/spf13/cobra"
 )
 
-var agentFlag string
+var (
+	agentFlag string
+	scopeFlag string
+)
+
+// addScopeFlag registers --scope on an MCP command
+func addScopeFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&scopeFlag, "scope", "s", "user", "MCP config scope (user, local, project)")
+}
+
+// nameWidth returns the width of the agent name column of per-agent
+// output: the longest name
+func nameWidth(agents []agent.Agent) int {
+	width := 0
+	for _, a := range agents {
+		width = max(width, len(a.Name()))
+	}
+	return width
+}
 
 var installCmd = &cobra.Command{
 	Use:   "install [mcp-server]",
 	Short: "Install an MCP server to agents",
-	Long:  `Install an MCP server to all agents or a specific agent.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Install an MCP server to every installed agent, or to the agent named by
+--agent whether or not it is installed.
+
+With --scope project the server is written to the project's shared config
+(.mcp.json, .cursor/mcp.json, ...) at the repository root. With --scope local
+it is written to Claude Code's private section of ~/.claude.json for the
+current directory.`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		serverName := args[0]
-		if serverName != "playwright" && serverName != "context7" && serverName != "remix-icon" {
-			fmt.Printf("Unknown MCP server: %s (supported: playwright, context7, remix-icon)\n", serverName)
+		scope, err := agent.ParseMCPScope(scopeFlag)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		discovered := agent.CollectMCPConfigs(agent.MCPHosts(agent.GetAllAgents()))
+		spec, ok := agent.ResolveMCPSpec(serverName, discovered)
+		if !ok {
+			fmt.Printf("Unknown MCP server: %s (supported: %s)\n", serverName, strings.Join(agent.BuiltinMCPServerNames(), ", "))
 			return
 		}
 
@@ -30,47 +63,55 @@ var installCmd = &cobra.Command{
 			}
 			agents = []agent.Agent{a}
 		} else {
-			agents = agent.GetAllAgents()
+			// Only agents that are installed, so that no config is
+			// created for an agent the user doesn't have
+			for _, a := range agent.GetAllAgents() {
+				if a.Exists() {
+					agents = append(agents, a)
+				}
+			}
+			if len(agents) == 0 {
+				fmt.Println("No installed agents found; use --agent to install to one anyway")
+				return
+			}
 		}
 
+		width := nameWidth(agents)
+		defer commitBackup(backup.Begin("install " + serverName))
 		for _, a := range agents {
-			var has bool
-			var err error
-			switch serverName {
-			case "playwright":
-				has, err = a.HasPlaywright()
-			case "context7":
-				has, err = a.HasContext7()
-			case "remix-icon":
-				has, err = a.HasRemixIcon()
+			host, err := agent.AsMCPHost(a)
+			if err != nil {
+				fmt.Printf("%-*s skipped: %v\n", width, a.Name(), err)
+				continue
 			}
+			if !agent.SupportsMCPScope(host, scope) {
+				fmt.Printf("%-*s skipped: no %s-scoped MCP config\n", width, a.Name(), scope)
+				continue
+			}
+			has, err := host.HasMCP(serverName, scope)
 			if err != nil {
-				fmt.Printf("%-12s error: %v\n", a.Name(), err)
+				fmt.Printf("%-*s error: %v\n", width, a.Name(), err)
 				continue
 			}
 			if has {
-				fmt.Printf("%-12s already installed\n", a.Name())
+				fmt.Printf("%-*s already installed\n", width, a.Name())
 				continue
 			}
 
-			switch serverName {
-			case "playwright":
-				err = a.InstallPlaywright()
-			case "context7":
-				err = a.InstallContext7()
-			case "remix-icon":
-				err = a.InstallRemixIcon()
+			if err := agent.CheckMCPFeatures(a, spec, scope); err != nil {
+				fmt.Printf("%-*s skipped: %v\n", width, a.Name(), err)
+				continue
 			}
-
-			if err != nil {
-				fmt.Printf("%-12s failed: %v\n", a.Name(), err)
+			if err := host.InstallMCP(serverName, spec, scope); err != nil {
+				fmt.Printf("%-*s failed: %v\n", width, a.Name(), err)
 			} else {
-				fmt.Printf("%-12s installed\n", a.Name())
+				fmt.Printf("%-*s installed\n", width, a.Name())
 			}
 		}
 	},
 }
 
 func init() {
-	installCmd.Flags().StringVarP(&agentFlag, "agent", "a", "", "Target agent (claude, codex, cursor, gemini, opencode)")
+	installCmd.Flags().StringVarP(&agentFlag, "agent", "a", "", "Target agent (claude, desktop, cline, codex, continue, cursor, gemini, goose, opencode, roo, vscode, windsurf, zed)")
+	addScopeFlag(installCmd)
 }
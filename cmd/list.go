@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -12,6 +13,11 @@ var listCmd = &cobra.Command{
 	Short: "List Playwright MCP status across all agents",
 	Long:  `Display Playwright MCP installation status for all supported agents.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		scope, err := agent.ParseMCPScope(scopeFlag)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		agents := agent.GetAllAgents()
 
 		fmt.Println()
@@ -20,16 +26,31 @@ var listCmd = &cobra.Command{
 
 		for _, a := range agents {
 			status := "○ not configured"
-			has, err := a.HasPlaywright()
-			if err != nil {
+			configPath := a.ConfigPath()
+			has := false
+			host, err := agent.AsMCPHost(a)
+			if err == nil {
+				configPath = agent.MCPConfigPath(host, scope)
+				has, err = host.HasMCP("playwright", scope)
+			}
+			if errors.Is(err, agent.ErrUnsupported) {
+				status = "○ n/a"
+			} else if err != nil {
 				status = fmt.Sprintf("✗ error: %v", err)
 			} else if has {
 				status = "✓ installed"
+				if specs, err := host.ListMCPs(scope); err == nil && specs["playwright"].Disabled {
+					status = "◌ disabled"
+				}
 			} else if !a.Exists() {
 				status = "○ config not found"
 			}
-			fmt.Printf("  %-14s  %-17s  %s\n", a.Name(), status, a.ConfigPath())
+			fmt.Printf("  %-14s  %-17s  %s\n", a.Name(), status, configPath)
 		}
 		fmt.Println()
 	},
 }
+
+func init() {
+	addScopeFlag(listCmd)
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/agentsdance/agentx/internal/agent"
+	"github.com/agentsdance/agentx/internal/backup"
+	"github.com/spf13/cobra"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate [agent]",
+	Short: "Migrate MCP servers out of legacy agent configs",
+	Long: `Move MCP servers from config layouts an agent no longer reads into its
+current config, e.g. opencode's ~/.opencode/config.json into opencode.json.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var agents []agent.Agent
+		if len(args) > 0 {
+			a := agent.GetAgentByName(args[0])
+			if a == nil {
+				fmt.Printf("Unknown agent: %s\n", args[0])
+				return
+			}
+			agents = []agent.Agent{a}
+		} else {
+			agents = agent.GetAllAgents()
+		}
+
+		width := nameWidth(agents)
+		defer commitBackup(backup.Begin("migrate"))
+		found := false
+		for _, a := range agents {
+			m, ok := a.(agent.LegacyMigrator)
+			if !ok {
+				continue
+			}
+			found = true
+
+			legacy, err := m.LegacyMCPs()
+			if err != nil {
+				fmt.Printf("%-*s error: %v\n", width, a.Name(), err)
+				continue
+			}
+			if len(legacy) == 0 {
+				fmt.Printf("%-*s nothing to migrate\n", width, a.Name())
+				continue
+			}
+
+			migrated, err := m.MigrateLegacy()
+			if err != nil {
+				fmt.Printf("%-*s failed: %v\n", width, a.Name(), err)
+				continue
+			}
+			if len(migrated) == 0 {
+				fmt.Printf("%-*s nothing migrated (names already in use)\n", width, a.Name())
+				continue
+			}
+			fmt.Printf("%-*s migrated: %s\n", width, a.Name(), strings.Join(migrated, ", "))
+		}
+		if !found {
+			fmt.Println("No agents with legacy configs")
+		}
+	},
+}
@@ -7,6 +7,7 @@ import (
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
+	"github.com/agentsdance/agentx/internal/agent"
 	"github.com/agentsdance/agentx/internal/plugins"
 )
 
@@ -78,8 +79,18 @@ The source can be:
 	Run: func(cmd *cobra.Command, args []string) {
 		source := args[0]
 
+		if err := checkAgentFeature("claude", agent.FeaturePlugins); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
 		mgr := plugins.NewPluginManager()
-		plugin, err := mgr.Install(source)
+		var plugin *plugins.Plugin
+		err := withBackup("plugins install "+source, func() error {
+			var err error
+			plugin, err = mgr.Install(source)
+			return err
+		})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
@@ -119,7 +130,10 @@ var pluginsRemoveCmd = &cobra.Command{
 		name := args[0]
 
 		mgr := plugins.NewPluginManager()
-		if err := mgr.Remove(name); err != nil {
+		err := withBackup("plugins remove "+name, func() error {
+			return mgr.Remove(name)
+		})
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
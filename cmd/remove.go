@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/agentsdance/agentx/internal/agent"
+	"github.com/agentsdance/agentx/internal/backup"
 	"github.com/spf13/cobra"
 )
 
@@ -14,47 +15,39 @@ var removeCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		serverName := args[0]
-		if serverName != "playwright" && serverName != "context7" && serverName != "remix-icon" {
-			fmt.Printf("Unknown MCP server: %s (supported: playwright, context7, remix-icon)\n", serverName)
+		scope, err := agent.ParseMCPScope(scopeFlag)
+		if err != nil {
+			fmt.Println(err)
 			return
 		}
-
-		agents := agent.GetAllAgents()
+		all := agent.GetAllAgents()
+		width := nameWidth(all)
+		agents := agent.MCPHosts(all)
+		defer commitBackup(backup.Begin("remove " + serverName))
 
 		for _, a := range agents {
-			var has bool
-			var err error
-			switch serverName {
-			case "playwright":
-				has, err = a.HasPlaywright()
-			case "context7":
-				has, err = a.HasContext7()
-			case "remix-icon":
-				has, err = a.HasRemixIcon()
+			if !agent.SupportsMCPScope(a, scope) {
+				continue
 			}
+			has, err := a.HasMCP(serverName, scope)
 			if err != nil {
-				fmt.Printf("%-12s error: %v\n", a.Name(), err)
+				fmt.Printf("%-*s error: %v\n", width, a.Name(), err)
 				continue
 			}
 			if !has {
-				fmt.Printf("%-12s not installed\n", a.Name())
+				fmt.Printf("%-*s not installed\n", width, a.Name())
 				continue
 			}
 
-			switch serverName {
-			case "playwright":
-				err = a.RemovePlaywright()
-			case "context7":
-				err = a.RemoveContext7()
-			case "remix-icon":
-				err = a.RemoveRemixIcon()
-			}
-
-			if err != nil {
-				fmt.Printf("%-12s failed: %v\n", a.Name(), err)
+			if err := a.RemoveMCP(serverName, scope); err != nil {
+				fmt.Printf("%-*s failed: %v\n", width, a.Name(), err)
 			} else {
-				fmt.Printf("%-12s removed\n", a.Name())
+				fmt.Printf("%-*s removed\n", width, a.Name())
 			}
 		}
 	},
 }
+
+func init() {
+	addScopeFlag(removeCmd)
+}
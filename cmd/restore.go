@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/agentsdance/agentx/internal/backup"
+	"github.com/spf13/cobra"
+)
+
+var (
+	restoreList  bool
+	restoreTo    string
+	restoreForce bool
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Undo the last change agentx made",
+	Long: `Put back the files the last install, remove, migrate, skills or plugins
+command changed. Running undo again undoes the change before that.
+
+The undo is recorded as an operation of its own, so it can be rolled back
+with agentx restore --to. If a file changed after the command wrote it,
+for example because an agent rewrote its config, undo refuses to overwrite
+it unless --force is given.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		snap, err := backup.Undo(restoreForce)
+		if errors.Is(err, backup.ErrNothingToUndo) {
+			fmt.Println("Nothing to undo")
+			return
+		}
+		if err != nil {
+			exitRestoreError(err)
+		}
+		fmt.Printf("Undid %s (%s)\n", snap.Operation, snap.ID)
+		printEntries(snap.Entries)
+	},
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "List backups or roll back to one",
+	Long: `Before changing a file or directory, agentx saves a copy of it under
+~/.agentx/backups/<id>/ and records the operation in
+~/.agentx/backups/journal.jsonl.
+
+  agentx restore --list         list the operations, newest first
+  agentx restore --to <id>      put everything back the way it was before
+                                operation <id>, rolling back it and every
+                                later operation
+
+An id may be shortened to any unique prefix. Files that changed after the
+last operation that wrote them are not overwritten unless --force is given.
+Old backups are pruned after
+each operation: agentx keeps the last 50 operations from the last 30 days.
+Set AGENTX_BACKUP_KEEP (a count, 0 turns backups off) and
+AGENTX_BACKUP_MAX_AGE (such as 72h or 14d, 0 for no limit) to change that.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if restoreTo == "" {
+			listBackups()
+			return
+		}
+		rolledBack, err := backup.RestoreTo(restoreTo, restoreForce)
+		if err != nil {
+			exitRestoreError(err)
+		}
+		for _, snap := range rolledBack {
+			fmt.Printf("Rolled back %s (%s)\n", snap.Operation, snap.ID)
+			printEntries(snap.Entries)
+		}
+	},
+}
+
+// exitRestoreError reports a failed undo or restore and exits
+func exitRestoreError(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	var modified *backup.ModifiedError
+	if errors.As(err, &modified) {
+		fmt.Fprintln(os.Stderr, "Nothing was rolled back; use --force to overwrite those changes")
+	}
+	os.Exit(1)
+}
+
+func listBackups() {
+	records, err := backup.List()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(records) == 0 {
+		fmt.Println("No backups")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTIME\tOPERATION\tPATHS\tSTATUS")
+	fmt.Fprintln(w, "--\t----\t---------\t-----\t------")
+	for _, r := range records {
+		status := ""
+		if r.Undone {
+			status = "undone"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", r.ID, r.Time.Local().Format("2006-01-02 15:04:05"), r.Operation, len(r.Entries), status)
+	}
+	w.Flush()
+}
+
+func printEntries(entries []backup.Entry) {
+	for _, e := range entries {
+		if e.Existed {
+			fmt.Printf("  restored %s\n", e.Path)
+		} else {
+			fmt.Printf("  removed  %s\n", e.Path)
+		}
+	}
+}
+
+// withBackup runs fn as one backup operation, so that agentx undo rolls
+// back everything fn changed
+func withBackup(description string, fn func() error) error {
+	op := backup.Begin(description)
+	err := fn()
+	commitBackup(op)
+	return err
+}
+
+// commitBackup records a command's backup operation in the journal
+func commitBackup(op *backup.Operation) {
+	if err := op.Commit(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not record the backup: %v\n", err)
+	}
+}
+
+func init() {
+	restoreCmd.Flags().BoolVarP(&restoreList, "list", "l", false, "List the backed up operations (the default)")
+	restoreCmd.Flags().StringVar(&restoreTo, "to", "", "Roll back to the state before this operation")
+	restoreCmd.Flags().BoolVarP(&restoreForce, "force", "f", false, "Roll back even files that changed since")
+	restoreCmd.MarkFlagsMutuallyExclusive("list", "to")
+	undoCmd.Flags().BoolVarP(&restoreForce, "force", "f", false, "Undo even files that changed since")
+}
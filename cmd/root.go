@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/agentsdance/agentx/internal/home"
+	"github.com/agentsdance/agentx/internal/plan"
 	"github.com/agentsdance/agentx/internal/version"
 	"github.com/agentsdance/agentx/ui"
 	"github.com/spf13/cobra"
@@ -20,6 +22,23 @@ Run without arguments to launch the TUI interface.
 
 Aliases: agents, ax`,
 	Version: version.GetFullVersion(),
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if dryRun {
+			// The diff is the dry run's output; what the command prints
+			// about its progress goes to stderr instead
+			diffOut, os.Stdout = os.Stdout, os.Stderr
+			dryRunPlan = plan.Begin()
+		}
+		if rootDir != "" {
+			return home.SetRoot(rootDir)
+		}
+		return nil
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		if dryRunPlan != nil {
+			finishDryRun(dryRunPlan)
+		}
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		maybeHandleUpdateNotice()
 
@@ -31,6 +50,38 @@ Aliases: agents, ax`,
 	},
 }
 
+// rootDir re-roots every path agentx reads or writes; see internal/home
+var rootDir string
+
+// dryRun plans the command's changes in memory instead of making them;
+// dryRunPlan holds them and diffOut is where their diff goes
+var (
+	dryRun     bool
+	dryRunPlan *plan.Plan
+	diffOut    *os.File
+)
+
+// exitWouldChange is the exit status of a dry run that would change
+// something, so that a CI check can tell it from an error (1)
+const exitWouldChange = 2
+
+// finishDryRun prints a unified diff of every file the dry run would have
+// changed and exits with exitWouldChange if there is one
+func finishDryRun(p *plan.Plan) {
+	p.End()
+	changes := p.Changes()
+	if len(changes) == 0 {
+		fmt.Fprintln(os.Stderr, "Dry run: nothing would change")
+		return
+	}
+	if err := p.WriteDiff(diffOut); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Dry run: %d file(s) would change; nothing was written\n", len(changes))
+	os.Exit(exitWouldChange)
+}
+
 // Execute runs the root command
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
@@ -40,10 +91,17 @@ func Execute() {
 
 func init() {
 	rootCmd.SetVersionTemplate("{{.Version}}\n")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Show a unified diff of every file the command would change, without changing anything; exits 2 if something would change")
+	rootCmd.PersistentFlags().StringVar(&rootDir, "root", "", "Use this directory as the home directory for all agent configs, skills, plugins and caches (also AGENTX_HOME)")
 	rootCmd.AddCommand(installCmd)
 	rootCmd.AddCommand(checkCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(removeCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(restoreCmd)
+	rootCmd.AddCommand(agentsCmd)
+	rootCmd.AddCommand(sandboxCmd)
 	rootCmd.AddCommand(skillsCmd)
 	rootCmd.AddCommand(pluginsCmd)
 }
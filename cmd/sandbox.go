@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/agentsdance/agentx/internal/agent"
+	"github.com/agentsdance/agentx/internal/home"
+	"github.com/agentsdance/agentx/internal/plan"
+	"github.com/spf13/cobra"
+)
+
+var sandboxCmd = &cobra.Command{
+	Use:   "sandbox [dir]",
+	Short: "Create a sandbox home with empty agent configs",
+	Long: `Create a sandbox home directory and seed it with an empty config for every
+agent, then print how to point agentx at it. Without dir a temporary
+directory is created.
+
+Inside the sandbox agentx ignores config-dir environment overrides such as
+CLAUDE_CONFIG_DIR, so nothing outside the directory is touched:
+
+  eval "$(agentx sandbox /tmp/agentx-sandbox)"
+  agentx install context7
+
+With --dry-run the configs that would be seeded are shown and nothing is
+created, so a dir is required.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := home.Root()
+		if len(args) == 1 {
+			dir = args[0]
+		}
+		dryRun := plan.Active() != nil
+		if dir == "" {
+			if dryRun {
+				fmt.Fprintln(os.Stderr, "Error: --dry-run needs a sandbox dir")
+				os.Exit(1)
+			}
+			tmp, err := os.MkdirTemp("", "agentx-sandbox-")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			dir = tmp
+		}
+		if !dryRun {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if err := home.SetRoot(dir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		agents := agent.GetAllAgents()
+		width := nameWidth(agents)
+		for _, a := range agents {
+			initializer, ok := a.(agent.ConfigInitializer)
+			if !ok {
+				continue
+			}
+			created, err := initializer.InitConfig()
+			switch {
+			case err != nil:
+				fmt.Fprintf(os.Stderr, "# %-*s failed: %v\n", width, a.Name(), err)
+			case created:
+				fmt.Fprintf(os.Stderr, "# %-*s %s\n", width, a.Name(), a.ConfigPath())
+			}
+		}
+		fmt.Printf("export %s=%q\n", home.EnvVar, home.Root())
+	},
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"github.com/agentsdance/agentx/internal/agent"
 	"github.com/agentsdance/agentx/internal/skills"
 	"github.com/spf13/cobra"
 )
@@ -107,7 +108,15 @@ The source can be:
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		skill, err := mgr.Install(source, scope)
+		if err := checkAgentFeature(skillsAgent, agent.FeatureSkills); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		var skill *skills.Skill
+		err = withBackup("skills install "+source, func() error {
+			skill, err = mgr.Install(source, scope)
+			return err
+		})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
@@ -138,7 +147,10 @@ var skillsRemoveCmd = &cobra.Command{
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		if err := mgr.Remove(name, scope); err != nil {
+		err = withBackup("skills remove "+name, func() error {
+			return mgr.Remove(name, scope)
+		})
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -225,9 +237,27 @@ func resolveSkillsManager() (*skills.DefaultSkillManager, error) {
 		return skills.NewSkillManager(), nil
 	case "codex":
 		return skills.NewCodexSkillManager(), nil
-	default:
-		return nil, fmt.Errorf("unknown agent: %s (use 'claude' or 'codex')", skillsAgent)
 	}
+	// Named instances such as claude@work, and other agents with skills
+	if a, ok := agent.GetAgentByName(skillsAgent).(interface {
+		SkillManager() (*skills.DefaultSkillManager, error)
+	}); ok {
+		return a.SkillManager()
+	}
+	return nil, fmt.Errorf("unknown agent: %s (use 'claude', 'codex' or a named instance such as claude@work)", skillsAgent)
+}
+
+// checkAgentFeature returns a *agent.VersionError when the agent named
+// name (Claude Code when empty) is too old for feature
+func checkAgentFeature(name string, feature agent.Feature) error {
+	if name == "" {
+		name = "claude"
+	}
+	a := agent.GetAgentByName(name)
+	if a == nil {
+		return nil
+	}
+	return agent.CheckFeature(a, feature)
 }
 
 func normalizeSkillsAgent(name string) string {
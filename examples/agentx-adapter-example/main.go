@@ -0,0 +1,158 @@
+// Command agentx-adapter-example is a reference out-of-process agentx
+// adapter. Its agent keeps one JSON file per MCP server in
+// $EXAMPLE_AGENT_HOME/mcp (default ~/.example-agent/mcp), a layout a
+// declarative definition can't describe.
+//
+// Build it onto PATH and agentx picks it up as "Example Agent":
+//
+//	go build -o ~/bin/agentx-adapter-example ./examples/agentx-adapter-example
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/agentsdance/agentx/adapter"
+)
+
+// exampleAgent implements adapter.Handler, adapter.MCPHandler and
+// adapter.InstructionsHandler
+type exampleAgent struct {
+	home string
+}
+
+var (
+	_ adapter.MCPHandler          = (*exampleAgent)(nil)
+	_ adapter.InstructionsHandler = (*exampleAgent)(nil)
+)
+
+func newExampleAgent() *exampleAgent {
+	home := os.Getenv("EXAMPLE_AGENT_HOME")
+	if home == "" {
+		userHome, _ := adapter.HomeDir()
+		home = filepath.Join(userHome, ".example-agent")
+	}
+	return &exampleAgent{home: home}
+}
+
+func (a *exampleAgent) mcpDir() string {
+	return filepath.Join(a.home, "mcp")
+}
+
+// serverPath returns the file for a server. The example agent only has a
+// user-scoped config.
+func (a *exampleAgent) serverPath(name string, scope adapter.MCPScope) (string, error) {
+	if scope != adapter.MCPScopeUser {
+		return "", fmt.Errorf("%s scope: %w", scope, adapter.ErrUnsupported)
+	}
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid server name: %q", name)
+	}
+	return filepath.Join(a.mcpDir(), name+".json"), nil
+}
+
+func (a *exampleAgent) Describe() (adapter.Description, error) {
+	_, err := os.Stat(a.home)
+	return adapter.Description{
+		Name:             "Example Agent",
+		Aliases:          []string{"example"},
+		ConfigPath:       a.mcpDir(),
+		Exists:           err == nil,
+		InstructionsPath: filepath.Join(a.home, "AGENTS.md"),
+	}, nil
+}
+
+func (a *exampleAgent) HasMCP(name string, scope adapter.MCPScope) (bool, error) {
+	path, err := a.serverPath(name, scope)
+	if err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (a *exampleAgent) InstallMCP(name string, spec adapter.MCPServerSpec, scope adapter.MCPScope) error {
+	path, err := a.serverPath(name, scope)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(a.mcpDir(), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func (a *exampleAgent) RemoveMCP(name string, scope adapter.MCPScope) error {
+	path, err := a.serverPath(name, scope)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (a *exampleAgent) ListMCPs(scope adapter.MCPScope) (map[string]adapter.MCPServerSpec, error) {
+	if scope != adapter.MCPScopeUser {
+		return nil, fmt.Errorf("%s scope: %w", scope, adapter.ErrUnsupported)
+	}
+	result := map[string]adapter.MCPServerSpec{}
+	entries, err := os.ReadDir(a.mcpDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return result, nil
+		}
+		return nil, err
+	}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(a.mcpDir(), entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var spec adapter.MCPServerSpec
+		if err := json.Unmarshal(data, &spec); err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		result[name] = spec
+	}
+	return result, nil
+}
+
+func (a *exampleAgent) ReadInstructions() (string, error) {
+	data, err := os.ReadFile(filepath.Join(a.home, "AGENTS.md"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (a *exampleAgent) WriteInstructions(content string) error {
+	if err := os.MkdirAll(a.home, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(a.home, "AGENTS.md"), []byte(content), 0644)
+}
+
+func main() {
+	adapter.Main(newExampleAgent())
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentsdance/agentx/internal/agent"
+)
+
+// serveEnv makes the test binary act as the adapter, so the tests can run
+// it through agentx's real process-based client
+const serveEnv = "AGENTX_ADAPTER_EXAMPLE_SERVE"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(serveEnv) == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// installAdapter puts this test binary on PATH as agentx-adapter-example
+func installAdapter(t *testing.T) string {
+	t.Helper()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bin := t.TempDir()
+	if err := os.Symlink(exe, filepath.Join(bin, "agentx-adapter-example")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	home := t.TempDir()
+	t.Setenv("PATH", bin)
+	t.Setenv("HOME", home)
+	t.Setenv(serveEnv, "1")
+	t.Setenv("EXAMPLE_AGENT_HOME", filepath.Join(home, ".example-agent"))
+	return home
+}
+
+func TestDiscovery(t *testing.T) {
+	installAdapter(t)
+
+	external, err := agent.DiscoverExternalAgents()
+	if err != nil {
+		t.Fatalf("DiscoverExternalAgents() error = %v", err)
+	}
+	if len(external) != 1 || external[0].Name() != "Example Agent" {
+		t.Fatalf("DiscoverExternalAgents() = %v", external)
+	}
+	if external[0].Exists() {
+		t.Errorf("Exists() = true before the agent home was created")
+	}
+
+	a := agent.GetAgentByName("example")
+	if a == nil {
+		t.Fatal("GetAgentByName(example) = nil")
+	}
+	caps := a.Capabilities()
+	if !caps.MCP || !caps.Instructions || caps.Skills || caps.Plugins {
+		t.Errorf("Capabilities() = %+v", caps)
+	}
+	if _, err := agent.AsSkillHost(a); !errors.Is(err, agent.ErrUnsupported) {
+		t.Errorf("AsSkillHost() error = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestMCPOverProcess(t *testing.T) {
+	home := installAdapter(t)
+	a, err := agent.NewExternalAgent("agentx-adapter-example")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := agent.MCPServerSpec{Command: "npx", Args: []string{"-y", "pkg"}, Env: map[string]string{"TOKEN": "x"}, Timeout: 90 * time.Second}
+	if err := a.InstallMCP("ctx", spec, agent.MCPScopeUser); err != nil {
+		t.Fatalf("InstallMCP() error = %v", err)
+	}
+	// The adapter stores the spec as it received it
+	data, err := os.ReadFile(filepath.Join(home, ".example-agent", "mcp", "ctx.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"timeout_ms": 90000`) {
+		t.Errorf("installed spec = %s, want timeout_ms 90000", data)
+	}
+	if has, err := a.HasMCP("ctx", agent.MCPScopeUser); err != nil || !has {
+		t.Errorf("HasMCP() = %v, %v; want true", has, err)
+	}
+	servers, err := a.ListMCPs(agent.MCPScopeUser)
+	if err != nil {
+		t.Fatalf("ListMCPs() error = %v", err)
+	}
+	if got := servers["ctx"]; got.Command != "npx" || got.Env["TOKEN"] != "x" || got.Timeout != 90*time.Second {
+		t.Errorf("ListMCPs() = %+v", servers)
+	}
+	if err := a.InstallMCP("../escape", spec, agent.MCPScopeUser); err == nil {
+		t.Errorf("InstallMCP(../escape) error = nil")
+	}
+	if err := a.RemoveMCP("ctx", agent.MCPScopeUser); err != nil {
+		t.Fatalf("RemoveMCP() error = %v", err)
+	}
+	if has, _ := a.HasMCP("ctx", agent.MCPScopeUser); has {
+		t.Errorf("HasMCP() = true after remove")
+	}
+
+	var unsupported *agent.UnsupportedError
+	if _, err := a.ListMCPs(agent.MCPScopeProject); !errors.As(err, &unsupported) || unsupported.Scope != agent.MCPScopeProject {
+		t.Errorf("ListMCPs(project) error = %v, want project-scoped UnsupportedError", err)
+	}
+	if _, err := a.HasSkill("x"); !errors.As(err, &unsupported) || unsupported.Capability != agent.CapabilitySkills {
+		t.Errorf("HasSkill() error = %v, want skills UnsupportedError", err)
+	}
+}
+
+func TestInstructionsOverProcess(t *testing.T) {
+	home := installAdapter(t)
+	a, err := agent.NewExternalAgent("agentx-adapter-example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.WriteInstructions("be brief\n"); err != nil {
+		t.Fatalf("WriteInstructions() error = %v", err)
+	}
+	got, err := a.ReadInstructions()
+	if err != nil || got != "be brief\n" {
+		t.Errorf("ReadInstructions() = %q, %v", got, err)
+	}
+	if want := filepath.Join(home, ".example-agent", "AGENTS.md"); a.InstructionsPath() != want {
+		t.Errorf("InstructionsPath() = %q, want %q", a.InstructionsPath(), want)
+	}
+}
@@ -2,8 +2,10 @@ package main
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/agentsdance/agentx/internal/agent"
+	"github.com/agentsdance/agentx/internal/backup"
 	"github.com/agentsdance/agentx/internal/plugins"
 	"github.com/agentsdance/agentx/internal/skills"
 	"github.com/agentsdance/agentx/internal/version"
@@ -26,15 +28,18 @@ func (a *App) startup(ctx context.Context) {
 
 // AgentInfo represents agent information for the frontend
 type AgentInfo struct {
-	Name       string `json:"name"`
-	ConfigPath string `json:"configPath"`
-	Exists     bool   `json:"exists"`
+	Name         string                `json:"name"`
+	ConfigPath   string                `json:"configPath"`
+	Exists       bool                  `json:"exists"`
+	Capabilities agent.Capabilities    `json:"capabilities"`
+	Detection    agent.DetectionResult `json:"detection"`
 }
 
 // MCPInfo represents MCP server information
 type MCPInfo struct {
-	Name   string                 `json:"name"`
-	Config map[string]interface{} `json:"config"`
+	Name   string              `json:"name"`
+	Scope  agent.MCPScope      `json:"scope"`
+	Config agent.MCPServerSpec `json:"config"`
 }
 
 // SkillInfo represents skill information for the frontend
@@ -58,93 +63,120 @@ type PluginInfo struct {
 // GetAgents returns all available agents with their status
 func (a *App) GetAgents() []AgentInfo {
 	agents := agent.GetAllAgents()
+	detections := agent.DetectAll(agents)
 	result := make([]AgentInfo, len(agents))
 	for i, ag := range agents {
 		result[i] = AgentInfo{
-			Name:       ag.Name(),
-			ConfigPath: ag.ConfigPath(),
-			Exists:     ag.Exists(),
+			Name:         ag.Name(),
+			ConfigPath:   ag.ConfigPath(),
+			Exists:       detections[i].Found(),
+			Capabilities: ag.Capabilities(),
+			Detection:    detections[i],
 		}
 	}
 	return result
 }
 
-// GetMCPs returns all MCP servers for an agent
+// GetMCPs returns all MCP servers for an agent across its scopes
 func (a *App) GetMCPs(agentName string) ([]MCPInfo, error) {
-	ag := agent.GetAgentByName(agentName)
-	if ag == nil {
-		return nil, nil
-	}
-
-	mcps, err := ag.ListMCPs()
+	host, err := mcpHostByName(agentName)
 	if err != nil {
 		return nil, err
 	}
 
-	result := make([]MCPInfo, 0, len(mcps))
-	for name, config := range mcps {
-		result = append(result, MCPInfo{
-			Name:   name,
-			Config: config,
-		})
+	var result []MCPInfo
+	for _, scope := range host.MCPScopes() {
+		mcps, err := host.ListMCPs(scope)
+		if err != nil {
+			return nil, err
+		}
+		for name, config := range mcps {
+			result = append(result, MCPInfo{
+				Name:   name,
+				Scope:  scope,
+				Config: config,
+			})
+		}
 	}
 	return result, nil
 }
 
 // InstallMCP installs an MCP server for an agent
 func (a *App) InstallMCP(agentName, mcpName string) error {
-	ag := agent.GetAgentByName(agentName)
-	if ag == nil {
-		return nil
+	defer backup.Begin("install " + mcpName + " to " + agentName).Commit()
+	host, err := mcpHostByName(agentName)
+	if err != nil {
+		return err
 	}
 
-	switch mcpName {
-	case "playwright":
-		return ag.InstallPlaywright()
-	case "context7":
-		return ag.InstallContext7()
-	case "remix-icon":
-		return ag.InstallRemixIcon()
+	spec, err := resolveMCPSpec(mcpName)
+	if err != nil {
+		return err
+	}
+	if err := agent.CheckMCPFeatures(host, spec, agent.MCPScopeUser); err != nil {
+		return err
 	}
-	return nil
+	return host.InstallMCP(mcpName, spec, agent.MCPScopeUser)
 }
 
 // RemoveMCP removes an MCP server from an agent
 func (a *App) RemoveMCP(agentName, mcpName string) error {
-	ag := agent.GetAgentByName(agentName)
-	if ag == nil {
-		return nil
-	}
-
-	switch mcpName {
-	case "playwright":
-		return ag.RemovePlaywright()
-	case "context7":
-		return ag.RemoveContext7()
-	case "remix-icon":
-		return ag.RemoveRemixIcon()
-	default:
-		return ag.RemoveMCP(mcpName)
+	defer backup.Begin("remove " + mcpName + " from " + agentName).Commit()
+	host, err := mcpHostByName(agentName)
+	if err != nil {
+		return err
 	}
+	return host.RemoveMCP(mcpName, agent.MCPScopeUser)
 }
 
 // HasMCP checks if an MCP server is installed for an agent
 func (a *App) HasMCP(agentName, mcpName string) (bool, error) {
-	ag := agent.GetAgentByName(agentName)
+	host, err := mcpHostByName(agentName)
+	if err != nil {
+		return false, err
+	}
+	return host.HasMCP(mcpName, agent.MCPScopeUser)
+}
+
+func agentByName(name string) (agent.Agent, error) {
+	ag := agent.GetAgentByName(name)
 	if ag == nil {
-		return false, nil
+		return nil, fmt.Errorf("unknown agent: %s", name)
 	}
+	return ag, nil
+}
 
-	switch mcpName {
-	case "playwright":
-		return ag.HasPlaywright()
-	case "context7":
-		return ag.HasContext7()
-	case "remix-icon":
-		return ag.HasRemixIcon()
-	default:
-		return ag.HasMCP(mcpName)
+func mcpHostByName(name string) (agent.MCPHost, error) {
+	ag, err := agentByName(name)
+	if err != nil {
+		return nil, err
+	}
+	return agent.AsMCPHost(ag)
+}
+
+func skillHostByName(name string) (agent.SkillHost, error) {
+	ag, err := agentByName(name)
+	if err != nil {
+		return nil, err
 	}
+	return agent.AsSkillHost(ag)
+}
+
+func pluginHostByName(name string) (agent.PluginHost, error) {
+	ag, err := agentByName(name)
+	if err != nil {
+		return nil, err
+	}
+	return agent.AsPluginHost(ag)
+}
+
+func resolveMCPSpec(name string) (agent.MCPServerSpec, error) {
+	discovered := agent.CollectMCPConfigs(agent.MCPHosts(agent.GetAllAgents()))
+	spec, ok := agent.ResolveMCPSpec(name, discovered)
+	if !ok {
+		return agent.MCPServerSpec{}, fmt.Errorf("unknown MCP server: %s", name)
+	}
+	return spec, nil
 }
 
 // GetSkillsRegistry returns available skills from the registry
@@ -168,36 +200,34 @@ func (a *App) GetSkillsRegistry() ([]SkillInfo, error) {
 
 // InstallSkill installs a skill for an agent
 func (a *App) InstallSkill(agentName, skillSource string) error {
-	ag := agent.GetAgentByName(agentName)
-	if ag == nil {
-		return nil
+	defer backup.Begin("skills install " + skillSource + " to " + agentName).Commit()
+	host, err := skillHostByName(agentName)
+	if err != nil {
+		return err
 	}
-
-	if !ag.SupportsSkills() {
-		return nil
+	if err := agent.CheckFeature(host, agent.FeatureSkills); err != nil {
+		return err
 	}
-
-	return ag.InstallSkill("", skillSource)
+	return host.InstallSkill("", skillSource)
 }
 
 // RemoveSkill removes a skill from an agent
 func (a *App) RemoveSkill(agentName, skillName string) error {
-	ag := agent.GetAgentByName(agentName)
-	if ag == nil {
-		return nil
+	defer backup.Begin("skills remove " + skillName + " from " + agentName).Commit()
+	host, err := skillHostByName(agentName)
+	if err != nil {
+		return err
 	}
-
-	return ag.RemoveSkill(skillName)
+	return host.RemoveSkill(skillName)
 }
 
 // HasSkill checks if a skill is installed for an agent
 func (a *App) HasSkill(agentName, skillName string) (bool, error) {
-	ag := agent.GetAgentByName(agentName)
-	if ag == nil {
-		return false, nil
+	host, err := skillHostByName(agentName)
+	if err != nil {
+		return false, err
 	}
-
-	return ag.HasSkill(skillName)
+	return host.HasSkill(skillName)
 }
 
 // GetPluginsRegistry returns available plugins from the registry
@@ -223,36 +253,34 @@ func (a *App) GetPluginsRegistry() ([]PluginInfo, error) {
 
 // InstallPlugin installs a plugin for an agent
 func (a *App) InstallPlugin(agentName, pluginSource string) error {
-	ag := agent.GetAgentByName(agentName)
-	if ag == nil {
-		return nil
+	defer backup.Begin("plugins install " + pluginSource + " to " + agentName).Commit()
+	host, err := pluginHostByName(agentName)
+	if err != nil {
+		return err
 	}
-
-	if !ag.SupportsPlugins() {
-		return nil
+	if err := agent.CheckFeature(host, agent.FeaturePlugins); err != nil {
+		return err
 	}
-
-	return ag.InstallPlugin("", pluginSource)
+	return host.InstallPlugin("", pluginSource)
 }
 
 // RemovePlugin removes a plugin from an agent
 func (a *App) RemovePlugin(agentName, pluginName string) error {
-	ag := agent.GetAgentByName(agentName)
-	if ag == nil {
-		return nil
+	defer backup.Begin("plugins remove " + pluginName + " from " + agentName).Commit()
+	host, err := pluginHostByName(agentName)
+	if err != nil {
+		return err
 	}
-
-	return ag.RemovePlugin(pluginName)
+	return host.RemovePlugin(pluginName)
 }
 
 // HasPlugin checks if a plugin is installed for an agent
 func (a *App) HasPlugin(agentName, pluginName string) (bool, error) {
-	ag := agent.GetAgentByName(agentName)
-	if ag == nil {
-		return false, nil
+	host, err := pluginHostByName(agentName)
+	if err != nil {
+		return false, err
 	}
-
-	return ag.HasPlugin(pluginName)
+	return host.HasPlugin(pluginName)
 }
 
 // SupportsSkills checks if an agent supports skills
@@ -261,7 +289,7 @@ func (a *App) SupportsSkills(agentName string) bool {
 	if ag == nil {
 		return false
 	}
-	return ag.SupportsSkills()
+	return ag.Capabilities().Skills
 }
 
 // SupportsPlugins checks if an agent supports plugins
@@ -270,7 +298,7 @@ func (a *App) SupportsPlugins(agentName string) bool {
 	if ag == nil {
 		return false
 	}
-	return ag.SupportsPlugins()
+	return ag.Capabilities().Plugins
 }
 
 // MCPStatus represents MCP installation status across all agents
@@ -298,7 +326,7 @@ type PluginStatus struct {
 
 // GetMCPMatrix returns MCP installation status matrix (like TUI)
 func (a *App) GetMCPMatrix() []MCPStatus {
-	agents := agent.GetAllAgents()
+	agents := agent.MCPHosts(agent.GetAllAgents())
 	mcpServers := []struct {
 		name string
 		desc string
@@ -322,9 +350,11 @@ func (a *App) GetMCPMatrix() []MCPStatus {
 				continue
 			}
 
-			has, err := ag.HasMCP(mcp.name)
+			has, err := ag.HasMCP(mcp.name, agent.MCPScopeUser)
 			if err != nil {
 				status.Agents[ag.Name()] = "error"
+			} else if has && mcpDisabled(ag, mcp.name) {
+				status.Agents[ag.Name()] = "disabled"
 			} else if has {
 				status.Agents[ag.Name()] = "installed"
 			} else {
@@ -337,6 +367,12 @@ func (a *App) GetMCPMatrix() []MCPStatus {
 	return result
 }
 
+// mcpDisabled reports whether an agent has the MCP server switched off
+func mcpDisabled(host agent.MCPHost, name string) bool {
+	specs, err := host.ListMCPs(agent.MCPScopeUser)
+	return err == nil && specs[name].Disabled
+}
+
 // GetSkillsMatrix returns skills installation status matrix
 func (a *App) GetSkillsMatrix() []SkillStatus {
 	agents := agent.GetAllAgents()
@@ -364,12 +400,13 @@ func (a *App) GetSkillsMatrix() []SkillStatus {
 				status.Agents[ag.Name()] = "n/a"
 				continue
 			}
-			if !ag.SupportsSkills() {
+			host, err := agent.AsSkillHost(ag)
+			if err != nil {
 				status.Agents[ag.Name()] = "n/a"
 				continue
 			}
 
-			has, err := ag.HasSkill(skill.Name)
+			has, err := host.HasSkill(skill.Name)
 			if err != nil {
 				status.Agents[ag.Name()] = "error"
 			} else if has {
@@ -406,12 +443,13 @@ func (a *App) GetPluginsMatrix() []PluginStatus {
 				status.Agents[ag.Name()] = "n/a"
 				continue
 			}
-			if !ag.SupportsPlugins() {
+			host, err := agent.AsPluginHost(ag)
+			if err != nil {
 				status.Agents[ag.Name()] = "n/a"
 				continue
 			}
 
-			has, err := ag.HasPlugin(plugin.Name)
+			has, err := host.HasPlugin(plugin.Name)
 			if err != nil {
 				status.Agents[ag.Name()] = "error"
 			} else if has {
@@ -438,38 +476,38 @@ func (a *App) RemoveMCPFromAgent(agentName, mcpName string) error {
 
 // InstallSkillForAgent installs a skill for an agent
 func (a *App) InstallSkillForAgent(agentName, skillName, source string) error {
-	ag := agent.GetAgentByName(agentName)
-	if ag == nil || !ag.SupportsSkills() {
-		return nil
+	defer backup.Begin("skills install " + skillName + " to " + agentName).Commit()
+	host, err := skillHostByName(agentName)
+	if err != nil {
+		return err
 	}
-	return ag.InstallSkill(skillName, source)
+	if err := agent.CheckFeature(host, agent.FeatureSkills); err != nil {
+		return err
+	}
+	return host.InstallSkill(skillName, source)
 }
 
 // RemoveSkillFromAgent removes a skill from an agent
 func (a *App) RemoveSkillFromAgent(agentName, skillName string) error {
-	ag := agent.GetAgentByName(agentName)
-	if ag == nil {
-		return nil
-	}
-	return ag.RemoveSkill(skillName)
+	return a.RemoveSkill(agentName, skillName)
 }
 
 // InstallPluginForAgent installs a plugin for an agent
 func (a *App) InstallPluginForAgent(agentName, pluginName, source string) error {
-	ag := agent.GetAgentByName(agentName)
-	if ag == nil || !ag.SupportsPlugins() {
-		return nil
+	defer backup.Begin("plugins install " + pluginName + " to " + agentName).Commit()
+	host, err := pluginHostByName(agentName)
+	if err != nil {
+		return err
 	}
-	return ag.InstallPlugin(pluginName, source)
+	if err := agent.CheckFeature(host, agent.FeaturePlugins); err != nil {
+		return err
+	}
+	return host.InstallPlugin(pluginName, source)
 }
 
 // RemovePluginFromAgent removes a plugin from an agent
 func (a *App) RemovePluginFromAgent(agentName, pluginName string) error {
-	ag := agent.GetAgentByName(agentName)
-	if ag == nil {
-		return nil
-	}
-	return ag.RemovePlugin(pluginName)
+	return a.RemovePlugin(agentName, pluginName)
 }
 
 // GetVersion returns the current version of AgentX
@@ -479,22 +517,23 @@ func (a *App) GetVersion() string {
 
 // InstallMCPForAll installs an MCP server to all available agents
 func (a *App) InstallMCPForAll(mcpName string) error {
-	agents := agent.GetAllAgents()
+	defer backup.Begin("install " + mcpName + " to all agents").Commit()
+	spec, err := resolveMCPSpec(mcpName)
+	if err != nil {
+		return err
+	}
+
+	agents := agent.MCPHosts(agent.GetAllAgents())
 	var lastErr error
 	for _, ag := range agents {
 		if !ag.Exists() {
 			continue
 		}
-		var err error
-		switch mcpName {
-		case "playwright":
-			err = ag.InstallPlaywright()
-		case "context7":
-			err = ag.InstallContext7()
-		case "remix-icon":
-			err = ag.InstallRemixIcon()
+		if err := agent.CheckMCPFeatures(ag, spec, agent.MCPScopeUser); err != nil {
+			lastErr = err
+			continue
 		}
-		if err != nil {
+		if err := ag.InstallMCP(mcpName, spec, agent.MCPScopeUser); err != nil {
 			lastErr = err
 		}
 	}
@@ -503,13 +542,19 @@ func (a *App) InstallMCPForAll(mcpName string) error {
 
 // InstallSkillForAll installs a skill to all available agents that support skills
 func (a *App) InstallSkillForAll(skillName, source string) error {
+	defer backup.Begin("skills install " + skillName + " to all agents").Commit()
 	agents := agent.GetAllAgents()
 	var lastErr error
 	for _, ag := range agents {
-		if !ag.Exists() || !ag.SupportsSkills() {
+		host, err := agent.AsSkillHost(ag)
+		if err != nil || !ag.Exists() {
 			continue
 		}
-		if err := ag.InstallSkill(skillName, source); err != nil {
+		if err := agent.CheckFeature(ag, agent.FeatureSkills); err != nil {
+			lastErr = err
+			continue
+		}
+		if err := host.InstallSkill(skillName, source); err != nil {
 			lastErr = err
 		}
 	}
@@ -518,13 +563,19 @@ func (a *App) InstallSkillForAll(skillName, source string) error {
 
 // InstallPluginForAll installs a plugin to all available agents that support plugins
 func (a *App) InstallPluginForAll(pluginName, source string) error {
+	defer backup.Begin("plugins install " + pluginName + " to all agents").Commit()
 	agents := agent.GetAllAgents()
 	var lastErr error
 	for _, ag := range agents {
-		if !ag.Exists() || !ag.SupportsPlugins() {
+		host, err := agent.AsPluginHost(ag)
+		if err != nil || !ag.Exists() {
+			continue
+		}
+		if err := agent.CheckFeature(ag, agent.FeaturePlugins); err != nil {
+			lastErr = err
 			continue
 		}
-		if err := ag.InstallPlugin(pluginName, source); err != nil {
+		if err := host.InstallPlugin(pluginName, source); err != nil {
 			lastErr = err
 		}
 	}
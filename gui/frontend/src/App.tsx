@@ -146,7 +146,7 @@ function App() {
   const handleMCPAction = async (agentName: string, mcpName: string, status: string) => {
     setLoading(true);
     try {
-      if (status === 'installed') {
+      if (status === 'installed' || status === 'disabled') {
         await RemoveMCP(agentName, mcpName);
         setMessage(`Removed ${mcpName} from ${agentName}`);
       } else if (status === 'not_installed') {
@@ -246,6 +246,8 @@ function App() {
     switch (status) {
       case 'installed':
         return 'status-installed';
+      case 'disabled':
+        return 'status-disabled';
       case 'not_installed':
         return 'status-not-installed';
       case 'n/a':
@@ -261,6 +263,8 @@ function App() {
     switch (status) {
       case 'installed':
         return '✓ installed';
+      case 'disabled':
+        return '◌ disabled';
       case 'not_installed':
         return '○ ---';
       case 'n/a':
@@ -375,13 +379,13 @@ function App() {
                         </td>
                         {agents.map((agent) => {
                           const status = mcp.agents[agent.name] || 'n/a';
-                          const isClickable = status === 'installed' || status === 'not_installed';
+                          const isClickable = status === 'installed' || status === 'disabled' || status === 'not_installed';
                           return (
                             <td
                               key={agent.name}
                               className={`matrix-cell ${getStatusClass(status)} ${isClickable ? 'clickable' : ''}`}
                               onClick={() => isClickable && handleMCPAction(agent.name, mcp.name, status)}
-                              title={isClickable ? (status === 'installed' ? 'Click to remove' : 'Click to install') : ''}
+                              title={isClickable ? (status === 'not_installed' ? 'Click to install' : 'Click to remove') : ''}
                             >
                               {getStatusText(status)}
                             </td>
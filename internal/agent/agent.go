@@ -1,49 +1,56 @@
 package agent
 
-// Agent represents an AI coding agent that supports MCP servers
+import (
+	"errors"
+	"strings"
+)
+
+// Agent represents an AI coding agent managed by agentx. What an agent can
+// do beyond identifying itself is described by the host interfaces below;
+// callers discover them with Capabilities or the As* helpers.
 type Agent interface {
 	// Name returns the display name of the agent
 	Name() string
 	// ConfigPath returns the path to the agent's config file
 	ConfigPath() string
-	// Exists returns true if the agent's config file exists
+	// Exists returns true if the agent is installed: its executable is on
+	// PATH or its config file exists
 	Exists() bool
-	// HasPlaywright checks if Playwright MCP is configured
-	HasPlaywright() (bool, error)
-	// InstallPlaywright adds Playwright MCP to the config
-	InstallPlaywright() error
-	// RemovePlaywright removes Playwright MCP from the config
-	RemovePlaywright() error
-	// HasContext7 checks if Context7 MCP is configured
-	HasContext7() (bool, error)
-	// InstallContext7 adds Context7 MCP to the config
-	InstallContext7() error
-	// RemoveContext7 removes Context7 MCP from the config
-	RemoveContext7() error
-	// HasRemixIcon checks if Remix Icon MCP is configured
-	HasRemixIcon() (bool, error)
-	// InstallRemixIcon adds Remix Icon MCP to the config
-	InstallRemixIcon() error
-	// RemoveRemixIcon removes Remix Icon MCP from the config
-	RemoveRemixIcon() error
+	// Capabilities describes which host interfaces the agent implements
+	Capabilities() Capabilities
+}
+
+// MCPHost is implemented by agents that can manage MCP servers. Each
+// operation targets the config of one scope; scopes the agent has no
+// config for return an UnsupportedError.
+type MCPHost interface {
+	Agent
+	// MCPScopes returns the scopes the agent has MCP configs for
+	MCPScopes() []MCPScope
 	// HasMCP checks if a specific MCP server is configured
-	HasMCP(name string) (bool, error)
+	HasMCP(name string, scope MCPScope) (bool, error)
 	// InstallMCP adds a specific MCP server to the config
-	InstallMCP(name string, mcpConfig map[string]interface{}) error
+	InstallMCP(name string, spec MCPServerSpec, scope MCPScope) error
 	// RemoveMCP removes a specific MCP server from the config
-	RemoveMCP(name string) error
+	RemoveMCP(name string, scope MCPScope) error
 	// ListMCPs returns configured MCP servers
-	ListMCPs() (map[string]map[string]interface{}, error)
-	// SupportsSkills returns true if the agent supports skills
-	SupportsSkills() bool
+	ListMCPs(scope MCPScope) (map[string]MCPServerSpec, error)
+}
+
+// SkillHost is implemented by agents that can manage skills
+type SkillHost interface {
+	Agent
 	// HasSkill checks if a skill is installed
 	HasSkill(skillName string) (bool, error)
 	// InstallSkill installs a skill from a source URL
 	InstallSkill(skillName, source string) error
 	// RemoveSkill removes a skill by name
 	RemoveSkill(skillName string) error
-	// SupportsPlugins returns true if the agent supports plugins
-	SupportsPlugins() bool
+}
+
+// PluginHost is implemented by agents that can manage plugins
+type PluginHost interface {
+	Agent
 	// HasPlugin checks if a plugin is installed
 	HasPlugin(pluginName string) (bool, error)
 	// InstallPlugin installs a plugin from a source URL
@@ -52,56 +59,97 @@ type Agent interface {
 	RemovePlugin(pluginName string) error
 }
 
-// GetAllAgents returns all supported agents
+// InstructionsHost is implemented by agents that read a global
+// instructions file (CLAUDE.md, AGENTS.md, GEMINI.md, ...)
+type InstructionsHost interface {
+	Agent
+	// InstructionsPath returns the path to the instructions file
+	InstructionsPath() string
+	// ReadInstructions returns the file contents, empty if it doesn't exist
+	ReadInstructions() (string, error)
+	// WriteInstructions replaces the file contents
+	WriteInstructions(content string) error
+}
+
+// LegacyMigrator is implemented by agents that can move MCP servers out of
+// a config layout the agent itself no longer reads
+type LegacyMigrator interface {
+	Agent
+	// LegacyConfigPath returns the path of the legacy config
+	LegacyConfigPath() string
+	// LegacyMCPs returns the servers still in the legacy config
+	LegacyMCPs() (map[string]MCPServerSpec, error)
+	// MigrateLegacy moves legacy servers into the current config and
+	// returns their names
+	MigrateLegacy() ([]string, error)
+}
+
+// ConfigInitializer is implemented by agents that can create an empty
+// config, as agentx sandbox does to seed a tree
+type ConfigInitializer interface {
+	Agent
+	InitConfig() (bool, error)
+}
+
+// GetAllAgents returns all supported agents: the built-in definitions, any
+// user definitions in ~/.agentx/agents.d and any agentx-adapter-* adapters
+// on PATH. An adapter replaces a definition with the same name.
 func GetAllAgents() []Agent {
-	return []Agent{
-		NewClaudeAgent(),
-		NewCodexAgent(),
-		NewCursorAgent(),
-		NewDroidAgent(),
-		NewGeminiAgent(),
-		NewOpenCodeAgent(),
+	agents, _ := LoadAllAgents() // broken definitions and adapters are skipped
+	return agents
+}
+
+// LoadAllAgents returns the agents GetAllAgents does, along with the
+// errors of the user definitions and adapters that were skipped
+func LoadAllAgents() ([]Agent, error) {
+	defs, defErr := LoadDefinitions()
+	agents := make([]Agent, 0, len(defs))
+	for _, def := range defs {
+		agents = append(agents, NewAgent(def))
 	}
+
+	external, extErr := DiscoverExternalAgents()
+	for _, ext := range external {
+		replaced := false
+		for i, a := range agents {
+			if strings.EqualFold(a.Name(), ext.Name()) {
+				agents[i] = ext
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			agents = append(agents, ext)
+		}
+	}
+	return agents, errors.Join(defErr, extErr)
 }
 
-// GetAgentByName returns an agent by name (case-insensitive)
+// GetAgentByName returns an agent by name or alias (case-insensitive)
 func GetAgentByName(name string) Agent {
 	for _, a := range GetAllAgents() {
-		if matchAgentName(a.Name(), name) {
+		if matchAgentName(a, name) {
 			return a
 		}
 	}
 	return nil
 }
 
-func matchAgentName(agentName, input string) bool {
-	// Simple case-insensitive prefix matching
-	input = toLower(input)
-	switch input {
-	case "claude", "claudecode", "claude-code", "claude_code":
-		return agentName == "Claude Code"
-	case "codex", "codexcli", "codex-cli", "codex_cli":
-		return agentName == "Codex"
-	case "cursor":
-		return agentName == "Cursor"
-	case "droid", "factory", "factory-droid", "factory_droid":
-		return agentName == "Droid"
-	case "gemini", "geminicli", "gemini-cli", "gemini_cli":
-		return agentName == "Gemini cli"
-	case "opencode", "open-code", "open_code":
-		return agentName == "opencode"
-	}
-	return false
+// aliased is implemented by agents that answer to extra names
+type aliased interface {
+	Aliases() []string
 }
 
-func toLower(s string) string {
-	result := make([]byte, len(s))
-	for i := 0; i < len(s); i++ {
-		c := s[i]
-		if c >= 'A' && c <= 'Z' {
-			c += 'a' - 'A'
+func matchAgentName(a Agent, input string) bool {
+	if strings.EqualFold(a.Name(), input) {
+		return true
+	}
+	if al, ok := a.(aliased); ok {
+		for _, alias := range al.Aliases() {
+			if strings.EqualFold(alias, input) {
+				return true
+			}
 		}
-		result[i] = c
 	}
-	return string(result)
+	return false
 }
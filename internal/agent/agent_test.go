@@ -0,0 +1,59 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/agentsdance/agentx/adapter/protocol"
+)
+
+func TestLoadAllAgentsReportsBrokenAdapters(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	fakeBinary(t, "agentx-adapter-broken", "not json")
+
+	agents, err := LoadAllAgents()
+	if err == nil || !strings.Contains(err.Error(), "agentx-adapter-broken") {
+		t.Errorf("LoadAllAgents() error = %v, want the broken adapter", err)
+	}
+	if len(agents) != len(BuiltinDefinitions()) {
+		t.Errorf("LoadAllAgents() = %d agents, want the %d built-in ones", len(agents), len(BuiltinDefinitions()))
+	}
+}
+
+func TestAdaptersDiscoveredOnce(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake adapters are shell scripts")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(protocol.HomeEnvVar, "")
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	script := "#!/bin/sh\necho call >> '" + calls + "'\necho 'not json'\n"
+	if err := os.WriteFile(filepath.Join(dir, "agentx-adapter-counted"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+	countCalls := func() int {
+		data, _ := os.ReadFile(calls)
+		return strings.Count(string(data), "call")
+	}
+
+	GetAllAgents()
+	GetAgentByName("claude")
+	if _, err := DiscoverExternalAgents(); err == nil {
+		t.Error("DiscoverExternalAgents() lost the cached error")
+	}
+	if n := countCalls(); n != 1 {
+		t.Errorf("adapter started %d times, want once", n)
+	}
+
+	// Re-rooting changes what adapters describe
+	t.Setenv(protocol.HomeEnvVar, t.TempDir())
+	GetAllAgents()
+	if n := countCalls(); n != 2 {
+		t.Errorf("adapter started %d times after re-rooting, want twice", n)
+	}
+}
@@ -0,0 +1,107 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Capability names a host interface an agent may implement
+type Capability string
+
+const (
+	CapabilityMCP          Capability = "mcp"
+	CapabilitySkills       Capability = "skills"
+	CapabilityPlugins      Capability = "plugins"
+	CapabilityInstructions Capability = "instructions"
+)
+
+// Capabilities describes which host interfaces an agent supports
+type Capabilities struct {
+	MCP          bool `json:"mcp"`
+	Skills       bool `json:"skills"`
+	Plugins      bool `json:"plugins"`
+	Instructions bool `json:"instructions"`
+}
+
+// Has reports whether the capability is supported
+func (c Capabilities) Has(capability Capability) bool {
+	switch capability {
+	case CapabilityMCP:
+		return c.MCP
+	case CapabilitySkills:
+		return c.Skills
+	case CapabilityPlugins:
+		return c.Plugins
+	case CapabilityInstructions:
+		return c.Instructions
+	}
+	return false
+}
+
+// ErrUnsupported is matched by every UnsupportedError via errors.Is
+var ErrUnsupported = errors.New("operation not supported")
+
+// UnsupportedError is returned when an operation targets an agent that
+// lacks the required capability, or an MCP scope it has no config for
+type UnsupportedError struct {
+	Agent      string
+	Capability Capability
+	// Scope is set when the capability is supported but not in this scope
+	Scope MCPScope
+}
+
+func (e *UnsupportedError) Error() string {
+	if e.Scope != "" {
+		return fmt.Sprintf("%s does not support %s-scoped %s", e.Agent, e.Scope, e.Capability)
+	}
+	return fmt.Sprintf("%s does not support %s", e.Agent, e.Capability)
+}
+
+// Is makes errors.Is(err, ErrUnsupported) true for UnsupportedError
+func (e *UnsupportedError) Is(target error) bool {
+	return target == ErrUnsupported
+}
+
+// AsMCPHost returns the agent as an MCPHost or an UnsupportedError
+func AsMCPHost(a Agent) (MCPHost, error) {
+	if host, ok := a.(MCPHost); ok && a.Capabilities().MCP {
+		return host, nil
+	}
+	return nil, &UnsupportedError{Agent: a.Name(), Capability: CapabilityMCP}
+}
+
+// AsSkillHost returns the agent as a SkillHost or an UnsupportedError
+func AsSkillHost(a Agent) (SkillHost, error) {
+	if host, ok := a.(SkillHost); ok && a.Capabilities().Skills {
+		return host, nil
+	}
+	return nil, &UnsupportedError{Agent: a.Name(), Capability: CapabilitySkills}
+}
+
+// AsPluginHost returns the agent as a PluginHost or an UnsupportedError
+func AsPluginHost(a Agent) (PluginHost, error) {
+	if host, ok := a.(PluginHost); ok && a.Capabilities().Plugins {
+		return host, nil
+	}
+	return nil, &UnsupportedError{Agent: a.Name(), Capability: CapabilityPlugins}
+}
+
+// AsInstructionsHost returns the agent as an InstructionsHost or an
+// UnsupportedError
+func AsInstructionsHost(a Agent) (InstructionsHost, error) {
+	if host, ok := a.(InstructionsHost); ok && a.Capabilities().Instructions {
+		return host, nil
+	}
+	return nil, &UnsupportedError{Agent: a.Name(), Capability: CapabilityInstructions}
+}
+
+// MCPHosts filters agents down to those that can manage MCP servers
+func MCPHosts(agents []Agent) []MCPHost {
+	hosts := make([]MCPHost, 0, len(agents))
+	for _, a := range agents {
+		if host, err := AsMCPHost(a); err == nil {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
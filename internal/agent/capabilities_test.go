@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAsHostUnsupported(t *testing.T) {
+	cursor := builtinAgentAt(t, "Cursor", "")
+	if _, err := AsMCPHost(cursor); err != nil {
+		t.Fatalf("AsMCPHost(Cursor) error = %v", err)
+	}
+
+	_, err := AsSkillHost(cursor)
+	if !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("AsSkillHost(Cursor) error = %v, want ErrUnsupported", err)
+	}
+	var unsupported *UnsupportedError
+	if !errors.As(err, &unsupported) || unsupported.Capability != CapabilitySkills {
+		t.Errorf("AsSkillHost(Cursor) error = %#v, want skills UnsupportedError", err)
+	}
+
+	if _, err := AsPluginHost(builtinAgentAt(t, "Claude Code", "")); err != nil {
+		t.Errorf("AsPluginHost(Claude) error = %v", err)
+	}
+}
+
+func TestCapabilitiesMatchHostInterfaces(t *testing.T) {
+	for _, a := range GetAllAgents() {
+		caps := a.Capabilities()
+		if _, ok := a.(MCPHost); caps.MCP && !ok {
+			t.Errorf("%s reports MCP but is not an MCPHost", a.Name())
+		}
+		if _, ok := a.(SkillHost); caps.Skills && !ok {
+			t.Errorf("%s reports skills but is not a SkillHost", a.Name())
+		}
+		if _, ok := a.(PluginHost); caps.Plugins && !ok {
+			t.Errorf("%s reports plugins but is not a PluginHost", a.Name())
+		}
+		if _, ok := a.(InstructionsHost); caps.Instructions && !ok {
+			t.Errorf("%s reports instructions but is not an InstructionsHost", a.Name())
+		}
+	}
+}
@@ -1,243 +1,109 @@
 package agent
 
 import (
-	"fmt"
 	"os"
-	"path/filepath"
 
 	"github.com/agentsdance/agentx/internal/config"
-	"github.com/agentsdance/agentx/internal/plugins"
-	"github.com/agentsdance/agentx/internal/skills"
 )
 
-// ClaudeAgent represents Claude Code agent
+// ClaudeAgent extends the declarative Claude Code agent with the "local"
+// scope: servers added with `claude mcp add` inside a project, stored in
+// ~/.claude.json under projects["/abs/path"].mcpServers for the directory
+// claude was started in
 type ClaudeAgent struct {
-	configPath string
+	*DeclarativeAgent
 }
 
-// NewClaudeAgent creates a new Claude Code agent
-func NewClaudeAgent() *ClaudeAgent {
-	home, _ := os.UserHomeDir()
-	return &ClaudeAgent{
-		configPath: filepath.Join(home, ".claude.json"),
-	}
-}
-
-func (a *ClaudeAgent) Name() string {
-	return "Claude Code"
-}
-
-func (a *ClaudeAgent) ConfigPath() string {
-	return a.configPath
+func newClaudeAgent(base *DeclarativeAgent) Agent {
+	return &ClaudeAgent{DeclarativeAgent: base}
 }
 
-func (a *ClaudeAgent) Exists() bool {
-	_, err := os.Stat(a.configPath)
-	return err == nil
+func (a *ClaudeAgent) MCPScopes() []MCPScope {
+	return []MCPScope{MCPScopeUser, MCPScopeLocal, MCPScopeProject}
 }
 
-func (a *ClaudeAgent) HasPlaywright() (bool, error) {
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return false, nil
-		}
-		return false, err
+func (a *ClaudeAgent) MCPConfigPath(scope MCPScope) (string, error) {
+	if scope == MCPScopeLocal {
+		return a.configPath, nil
 	}
-	return config.HasPlaywrightMCP(cfg), nil
+	return a.DeclarativeAgent.MCPConfigPath(scope)
 }
 
-func (a *ClaudeAgent) InstallPlaywright() error {
-	cfg, err := config.ReadConfig(a.configPath)
+// localKey returns the key path of the current project's servers. Claude
+// Code keys projects by the directory it was started in, which need not be
+// the repository root.
+func (a *ClaudeAgent) localKey() ([]string, error) {
+	cwd, err := os.Getwd()
 	if err != nil {
-		if os.IsNotExist(err) {
-			cfg = make(map[string]interface{})
-		} else {
-			return err
-		}
+		return nil, err
 	}
-	config.AddPlaywrightMCP(cfg)
-	return config.WriteConfig(a.configPath, cfg)
+	return []string{"projects", cwd, "mcpServers"}, nil
 }
 
-func (a *ClaudeAgent) RemovePlaywright() error {
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return err
+func (a *ClaudeAgent) HasMCP(name string, scope MCPScope) (bool, error) {
+	if scope != MCPScopeLocal {
+		return a.DeclarativeAgent.HasMCP(name, scope)
 	}
-	config.RemovePlaywrightMCP(cfg)
-	return config.WriteConfig(a.configPath, cfg)
-}
-
-func (a *ClaudeAgent) HasContext7() (bool, error) {
-	cfg, err := config.ReadConfig(a.configPath)
+	servers, err := a.localMCPs()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return false, nil
-		}
 		return false, err
 	}
-	return config.HasContext7MCP(cfg), nil
+	_, ok := servers[name]
+	return ok, nil
 }
 
-func (a *ClaudeAgent) InstallContext7() error {
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			cfg = make(map[string]interface{})
-		} else {
-			return err
-		}
+func (a *ClaudeAgent) InstallMCP(name string, spec MCPServerSpec, scope MCPScope) error {
+	if scope != MCPScopeLocal {
+		return a.DeclarativeAgent.InstallMCP(name, spec, scope)
 	}
-	config.AddContext7MCP(cfg)
-	return config.WriteConfig(a.configPath, cfg)
-}
-
-func (a *ClaudeAgent) RemoveContext7() error {
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
+	if err := spec.Validate(); err != nil {
 		return err
 	}
-	config.RemoveContext7MCP(cfg)
-	return config.WriteConfig(a.configPath, cfg)
-}
-
-func (a *ClaudeAgent) HasRemixIcon() (bool, error) {
-	cfg, err := config.ReadConfig(a.configPath)
+	key, err := a.localKey()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return false, nil
-		}
-		return false, err
+		return err
 	}
-	return config.HasRemixIconMCP(cfg), nil
+	entry := a.translator.fromSpec(spec)
+	return a.updateConfig(a.configPath, func(cfg map[string]interface{}) error {
+		config.EnsureMap(cfg, key)[name] = entry
+		return nil
+	})
 }
 
-func (a *ClaudeAgent) InstallRemixIcon() error {
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			cfg = make(map[string]interface{})
-		} else {
-			return err
-		}
+func (a *ClaudeAgent) RemoveMCP(name string, scope MCPScope) error {
+	if scope != MCPScopeLocal {
+		return a.DeclarativeAgent.RemoveMCP(name, scope)
 	}
-	config.AddRemixIconMCP(cfg)
-	return config.WriteConfig(a.configPath, cfg)
-}
-
-func (a *ClaudeAgent) RemoveRemixIcon() error {
-	cfg, err := config.ReadConfig(a.configPath)
+	key, err := a.localKey()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
 		return err
 	}
-	config.RemoveRemixIconMCP(cfg)
-	return config.WriteConfig(a.configPath, cfg)
-}
-
-func (a *ClaudeAgent) HasMCP(name string) (bool, error) {
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return false, nil
-		}
-		return false, err
-	}
-	return config.HasMCP(cfg, name), nil
-}
-
-func (a *ClaudeAgent) InstallMCP(name string, mcpConfig map[string]interface{}) error {
-	if mcpConfig == nil {
-		return fmt.Errorf("missing mcp config")
-	}
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			cfg = make(map[string]interface{})
-		} else {
-			return err
+	return a.updateConfig(a.configPath, func(cfg map[string]interface{}) error {
+		servers := config.GetMap(cfg, key)
+		if _, ok := servers[name]; !ok {
+			return config.ErrUnchanged
 		}
-	}
-	config.AddMCP(cfg, name, cloneMCPConfig(mcpConfig))
-	return config.WriteConfig(a.configPath, cfg)
+		delete(servers, name)
+		return nil
+	})
 }
 
-func (a *ClaudeAgent) RemoveMCP(name string) error {
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return err
+func (a *ClaudeAgent) ListMCPs(scope MCPScope) (map[string]MCPServerSpec, error) {
+	if scope != MCPScopeLocal {
+		return a.DeclarativeAgent.ListMCPs(scope)
 	}
-	config.RemoveMCP(cfg, name)
-	return config.WriteConfig(a.configPath, cfg)
-}
-
-func (a *ClaudeAgent) ListMCPs() (map[string]map[string]interface{}, error) {
-	cfg, err := config.ReadConfig(a.configPath)
+	servers, err := a.localMCPs()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return map[string]map[string]interface{}{}, nil
-		}
 		return nil, err
 	}
-	return config.GetMCPServers(cfg), nil
+	return a.specsFromNative(servers, MCPScopeLocal), nil
 }
 
-func (a *ClaudeAgent) SupportsSkills() bool {
-	return true
-}
-
-func (a *ClaudeAgent) HasSkill(skillName string) (bool, error) {
-	mgr := skills.NewSkillManager()
-	skill, err := mgr.Get(skillName)
-	if err != nil {
-		return false, nil // Not found is not an error
-	}
-	return skill != nil, nil
-}
-
-func (a *ClaudeAgent) InstallSkill(skillName, source string) error {
-	mgr := skills.NewSkillManager()
-	_, err := mgr.Install(source, skills.ScopePersonal)
-	return err
-}
-
-func (a *ClaudeAgent) RemoveSkill(skillName string) error {
-	mgr := skills.NewSkillManager()
-	return mgr.Remove(skillName, skills.ScopePersonal)
-}
-
-func (a *ClaudeAgent) SupportsPlugins() bool {
-	return true
-}
-
-func (a *ClaudeAgent) HasPlugin(pluginName string) (bool, error) {
-	mgr := plugins.NewPluginManager()
-	plugin, err := mgr.Get(pluginName)
+// localMCPs returns the raw servers of the current project
+func (a *ClaudeAgent) localMCPs() (map[string]map[string]interface{}, error) {
+	key, err := a.localKey()
 	if err != nil {
-		return false, nil // Not found is not an error
+		return nil, err
 	}
-	return plugin != nil, nil
-}
-
-func (a *ClaudeAgent) InstallPlugin(pluginName, source string) error {
-	mgr := plugins.NewPluginManager()
-	_, err := mgr.Install(source)
-	return err
-}
-
-func (a *ClaudeAgent) RemovePlugin(pluginName string) error {
-	mgr := plugins.NewPluginManager()
-	return mgr.Remove(pluginName)
+	return a.nativeMCPsAt(a.configPath, key)
 }
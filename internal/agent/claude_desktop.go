@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"sort"
+	"strings"
+)
+
+// mcpRemotePackage is the npm package Claude Desktop runs to reach remote
+// servers over stdio
+const mcpRemotePackage = "mcp-remote"
+
+// ClaudeDesktopAgent extends the declarative Claude Desktop agent, which
+// only runs stdio servers. Remote servers are installed as an npx
+// mcp-remote command that bridges stdio to the server's URL, and such
+// bridge commands are listed as the remote servers they stand for, so a
+// server copied from Desktop to another agent arrives as a plain URL.
+type ClaudeDesktopAgent struct {
+	*DeclarativeAgent
+}
+
+func newClaudeDesktopAgent(base *DeclarativeAgent) Agent {
+	return &ClaudeDesktopAgent{DeclarativeAgent: base}
+}
+
+func (a *ClaudeDesktopAgent) InstallMCP(name string, spec MCPServerSpec, scope MCPScope) error {
+	if spec.IsRemote() {
+		spec = mcpRemoteBridge(spec)
+	}
+	return a.DeclarativeAgent.InstallMCP(name, spec, scope)
+}
+
+func (a *ClaudeDesktopAgent) ListMCPs(scope MCPScope) (map[string]MCPServerSpec, error) {
+	specs, err := a.DeclarativeAgent.ListMCPs(scope)
+	if err != nil {
+		return nil, err
+	}
+	for name, spec := range specs {
+		if remote, ok := fromMCPRemoteBridge(spec); ok {
+			specs[name] = remote
+		}
+	}
+	return specs, nil
+}
+
+// mcpRemoteBridge returns the stdio spec that runs mcp-remote for a remote
+// server: npx -y mcp-remote <url> [--header Name:value]... [--transport ...]
+func mcpRemoteBridge(remote MCPServerSpec) MCPServerSpec {
+	args := []string{"-y", mcpRemotePackage, remote.URL}
+	names := make([]string, 0, len(remote.Headers))
+	for name := range remote.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, "--header", name+":"+remote.Headers[name])
+	}
+	switch remote.Transport {
+	case TransportSSE:
+		args = append(args, "--transport", "sse-only")
+	case TransportHTTP:
+		args = append(args, "--transport", "http-only")
+	}
+	return MCPServerSpec{
+		Command:  "npx",
+		Args:     args,
+		Env:      cloneStringMap(remote.Env),
+		Timeout:  remote.Timeout,
+		Disabled: remote.Disabled,
+		Scope:    remote.Scope,
+	}
+}
+
+// fromMCPRemoteBridge recognises an mcp-remote bridge command and returns
+// the remote server it reaches
+func fromMCPRemoteBridge(spec MCPServerSpec) (MCPServerSpec, bool) {
+	if spec.Command != "npx" {
+		return spec, false
+	}
+	args := spec.Args
+	if len(args) > 0 && args[0] == "-y" {
+		args = args[1:]
+	}
+	if len(args) < 2 || (args[0] != mcpRemotePackage && !strings.HasPrefix(args[0], mcpRemotePackage+"@")) {
+		return spec, false
+	}
+	remote := MCPServerSpec{
+		URL:      args[1],
+		Env:      cloneStringMap(spec.Env),
+		Timeout:  spec.Timeout,
+		Disabled: spec.Disabled,
+		Scope:    spec.Scope,
+	}
+	for i := 2; i < len(args); i++ {
+		switch {
+		case args[i] == "--header" && i+1 < len(args):
+			i++
+			name, value, ok := strings.Cut(args[i], ":")
+			if !ok {
+				return spec, false
+			}
+			if remote.Headers == nil {
+				remote.Headers = make(map[string]string)
+			}
+			remote.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		case args[i] == "--transport" && i+1 < len(args):
+			i++
+			switch args[i] {
+			case "sse-only", "sse-first":
+				remote.Transport = TransportSSE
+			case "http-only", "http-first":
+				remote.Transport = TransportHTTP
+			}
+		default:
+			// Flags agentx doesn't model (ports, --allow-http, ...) would
+			// be lost, so keep the command as it is
+			return spec, false
+		}
+	}
+	return remote, true
+}
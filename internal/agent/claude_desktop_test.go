@@ -0,0 +1,90 @@
+package agent
+
+import (
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/agentsdance/agentx/internal/config"
+)
+
+func TestClaudeDesktopDefinition(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("user config dir is platform specific")
+	}
+	t.Setenv("HOME", "/home/dev")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	a := NewAgent(builtinDefinition(t, "Claude Desktop"))
+	if _, ok := a.(*ClaudeDesktopAgent); !ok {
+		t.Fatal("Claude Desktop definition did not use the claude-desktop adapter")
+	}
+	if got, want := a.ConfigPath(), "/home/dev/.config/Claude/claude_desktop_config.json"; got != want {
+		t.Errorf("ConfigPath() = %q, want %q", got, want)
+	}
+	for _, name := range []string{"desktop", "claude-desktop", "Claude Desktop"} {
+		if !matchAgentName(a, name) {
+			t.Errorf("matchAgentName(%q) = false", name)
+		}
+	}
+	if matchAgentName(a, "claude") {
+		t.Error(`"claude" matched Claude Desktop instead of Claude Code`)
+	}
+}
+
+func TestClaudeDesktopRemoteBridge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "claude_desktop_config.json")
+	host := builtinAgentAt(t, "Claude Desktop", path).(MCPHost)
+
+	remote := MCPServerSpec{
+		URL:       "https://mcp.example.com/sse",
+		Headers:   map[string]string{"Authorization": "Bearer abc", "X-Team": "core"},
+		Transport: TransportSSE,
+	}
+	if err := host.InstallMCP("example", remote, MCPScopeUser); err != nil {
+		t.Fatalf("InstallMCP() error = %v", err)
+	}
+	local := MCPServerSpec{Command: "npx", Args: []string{"-y", "@playwright/mcp@latest"}}
+	if err := host.InstallMCP("playwright", local, MCPScopeUser); err != nil {
+		t.Fatalf("InstallMCP() error = %v", err)
+	}
+
+	cfg, err := config.ReadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := config.GetMap(cfg, []string{"mcpServers", "example"})
+	want := map[string]interface{}{
+		"command": "npx",
+		"args": []interface{}{
+			"-y", "mcp-remote", "https://mcp.example.com/sse",
+			"--header", "Authorization:Bearer abc",
+			"--header", "X-Team:core",
+			"--transport", "sse-only",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("example entry = %#v\nwant %#v", got, want)
+	}
+
+	specs, err := host.ListMCPs(MCPScopeUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote.Scope = MCPScopeUser
+	local.Scope = MCPScopeUser
+	if !reflect.DeepEqual(specs, map[string]MCPServerSpec{"example": remote, "playwright": local}) {
+		t.Errorf("ListMCPs() = %#v", specs)
+	}
+}
+
+func TestFromMCPRemoteBridgeKeepsUnknownFlags(t *testing.T) {
+	spec := MCPServerSpec{Command: "npx", Args: []string{"mcp-remote@0.1.0", "https://example.com/mcp", "9696", "--allow-http"}}
+	if _, ok := fromMCPRemoteBridge(spec); ok {
+		t.Error("bridge with flags agentx can't model was listed as a remote server")
+	}
+	spec.Args = []string{"mcp-remote@0.1.0", "https://example.com/mcp"}
+	if remote, ok := fromMCPRemoteBridge(spec); !ok || remote.URL != "https://example.com/mcp" {
+		t.Errorf("fromMCPRemoteBridge() = %#v, %v", remote, ok)
+	}
+}
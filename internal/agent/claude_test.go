@@ -0,0 +1,125 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/agentsdance/agentx/internal/config"
+)
+
+func TestClaudeLocalScope(t *testing.T) {
+	chdirProject(t)
+	// Claude Code keys projects by the path it was started in
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), ".claude.json")
+	fixture := `{
+  "mcpServers": {"global": {"type": "stdio", "command": "global-server"}},
+  "projects": {
+    "` + filepath.ToSlash(cwd) + `": {
+      "allowedTools": [],
+      "mcpServers": {"added": {"type": "stdio", "command": "npx", "args": ["-y", "added"]}}
+    },
+    "/elsewhere": {"mcpServers": {"other": {"command": "other"}}}
+  }
+}`
+	if err := os.WriteFile(path, []byte(fixture), 0644); err != nil {
+		t.Fatal(err)
+	}
+	host := builtinAgentAt(t, "Claude Code", path).(MCPHost)
+
+	if !SupportsMCPScope(host, MCPScopeLocal) {
+		t.Fatal("Claude Code does not report local scope")
+	}
+	local, err := host.ListMCPs(MCPScopeLocal)
+	if err != nil {
+		t.Fatalf("ListMCPs(local) error = %v", err)
+	}
+	if len(local) != 1 || local["added"].Command != "npx" || local["added"].Scope != MCPScopeLocal {
+		t.Errorf("ListMCPs(local) = %+v", local)
+	}
+	user, err := host.ListMCPs(MCPScopeUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(user) != 1 || user["global"].Scope != MCPScopeUser {
+		t.Errorf("ListMCPs(user) = %+v", user)
+	}
+
+	if err := host.InstallMCP("ctx", MCPServerSpec{Command: "npx", Args: []string{"ctx"}}, MCPScopeLocal); err != nil {
+		t.Fatalf("InstallMCP(local) error = %v", err)
+	}
+	if err := host.RemoveMCP("added", MCPScopeLocal); err != nil {
+		t.Fatalf("RemoveMCP(local) error = %v", err)
+	}
+
+	cfg, err := config.ReadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	project := config.GetMap(cfg, []string{"projects", cwd})
+	servers, _ := project["mcpServers"].(map[string]interface{})
+	if _, ok := servers["ctx"]; !ok || len(servers) != 1 {
+		t.Errorf("project servers = %v, want only ctx", servers)
+	}
+	if _, ok := project["allowedTools"]; !ok {
+		t.Errorf("other project settings were dropped")
+	}
+	if other := config.GetMap(cfg, []string{"projects", "/elsewhere", "mcpServers"}); other["other"] == nil {
+		t.Errorf("other projects were modified")
+	}
+	if has, _ := host.HasMCP("ctx", MCPScopeUser); has {
+		t.Errorf("local server leaked into user scope")
+	}
+
+	configs := CollectMCPConfigs([]MCPHost{host})
+	if got := configs["ctx"].Source; got != "Claude Code (local)" {
+		t.Errorf("CollectMCPConfigs source = %q", got)
+	}
+}
+
+func TestClaudeLocalScopeInSubdirectory(t *testing.T) {
+	root := chdirProject(t)
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), ".claude.json")
+	fixture := `{
+  "projects": {
+    "` + filepath.ToSlash(root) + `": {"mcpServers": {"repo": {"command": "repo"}}},
+    "` + filepath.ToSlash(cwd) + `": {"mcpServers": {"here": {"command": "here"}}}
+  }
+}`
+	if err := os.WriteFile(path, []byte(fixture), 0644); err != nil {
+		t.Fatal(err)
+	}
+	host := builtinAgentAt(t, "Claude Code", path).(MCPHost)
+
+	local, err := host.ListMCPs(MCPScopeLocal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(local) != 1 || local["here"].Command != "here" {
+		t.Errorf("ListMCPs(local) = %+v, want the servers of the working directory", local)
+	}
+	if err := host.InstallMCP("ctx", MCPServerSpec{Command: "npx"}, MCPScopeLocal); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.ReadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if servers := config.GetMap(cfg, []string{"projects", cwd, "mcpServers"}); servers["ctx"] == nil {
+		t.Errorf("ctx was not installed for the working directory: %v", servers)
+	}
+	if servers := config.GetMap(cfg, []string{"projects", root, "mcpServers"}); len(servers) != 1 {
+		t.Errorf("repository root servers = %v, want only repo", servers)
+	}
+}
@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/agentsdance/agentx/internal/config"
+)
+
+// vscodeUserDir is the prefix of config templates inside VS Code's user
+// directory, which the Insiders and VSCodium builds keep under other names
+const vscodeUserDir = "${USER_CONFIG_DIR}/Code/User/"
+
+// vscodeEditorDirs are the user config directories of VS Code builds, in
+// the order they are tried
+var vscodeEditorDirs = []string{"Code", "Code - Insiders", "VSCodium"}
+
+// ClineAgent extends the declarative agents for Cline and its fork Roo
+// Code, VS Code extensions that keep MCP settings in the editor's
+// globalStorage. The settings are read from whichever VS Code build has
+// them: VS Code, VS Code Insiders or VSCodium. Reinstalling a server keeps
+// the settings made in the extension (autoApprove/alwaysAllow, disabled,
+// timeout, the legacy transportType, ...) unless the spec sets them.
+type ClineAgent struct {
+	*DeclarativeAgent
+}
+
+func newClineAgent(base *DeclarativeAgent) Agent {
+	if template := base.def.Config; strings.HasPrefix(template, vscodeUserDir) && !fileExists(base.configPath) {
+		rest := strings.TrimPrefix(template, vscodeUserDir)
+		for _, editor := range vscodeEditorDirs[1:] {
+			path := expandPathWith("${USER_CONFIG_DIR}/"+editor+"/User/"+rest, base.def.Env)
+			if fileExists(path) {
+				base.configPath = path
+				base.detectPath = filepath.Dir(filepath.Dir(path)) // globalStorage/<extension>
+				break
+			}
+		}
+	}
+	return &ClineAgent{DeclarativeAgent: base}
+}
+
+func (a *ClineAgent) InstallMCP(name string, spec MCPServerSpec, scope MCPScope) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	path, err := a.MCPConfigPath(scope)
+	if err != nil {
+		return err
+	}
+	f := a.def.Fields
+	return a.updateConfig(path, func(cfg map[string]interface{}) error {
+		servers := config.EnsureMap(cfg, a.mcpKey)
+		existing, _ := servers[name].(map[string]interface{})
+		servers[name] = a.translator.merge(existing, a.translator.fromSpec(spec), f.Disabled, f.Timeout, f.AutoApprove)
+		return nil
+	})
+}
@@ -0,0 +1,108 @@
+package agent
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/agentsdance/agentx/internal/config"
+)
+
+func TestClineEditorVariants(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("user config dir is platform specific")
+	}
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	def := builtinDefinition(t, "Roo Code")
+
+	a := NewAgent(def)
+	if _, ok := a.(*ClineAgent); !ok {
+		t.Fatal("Roo Code definition did not use the cline adapter")
+	}
+	want := filepath.Join(dir, "Code/User/globalStorage/rooveterinaryinc.roo-cline/settings/mcp_settings.json")
+	if got := a.ConfigPath(); got != want {
+		t.Errorf("ConfigPath() without settings = %q, want %q", got, want)
+	}
+
+	// Only VSCodium has the extension installed
+	want = filepath.Join(dir, "VSCodium/User/globalStorage/rooveterinaryinc.roo-cline/settings/mcp_settings.json")
+	if err := os.MkdirAll(filepath.Dir(want), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(want, []byte(`{"mcpServers": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	a = NewAgent(def)
+	if got := a.ConfigPath(); got != want {
+		t.Errorf("ConfigPath() = %q, want %q", got, want)
+	}
+	if got := a.(Detector).Detect().Status; got != DetectionInstalled {
+		t.Errorf("Detect().Status = %v, want %v", got, DetectionInstalled)
+	}
+}
+
+func TestClineMCPs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cline_mcp_settings.json")
+	fixture := `{
+  "mcpServers": {
+    "github": {
+      "command": "npx",
+      "args": ["-y", "@modelcontextprotocol/server-github"],
+      "autoApprove": ["list_issues", "get_issue"],
+      "disabled": false,
+      "timeout": 120,
+      "transportType": "stdio"
+    },
+    "docs": {"type": "streamableHttp", "url": "https://docs.example.com/mcp"}
+  }
+}`
+	if err := os.WriteFile(path, []byte(fixture), 0644); err != nil {
+		t.Fatal(err)
+	}
+	a := builtinAgentAt(t, "Cline", path).(MCPHost)
+
+	specs, err := a.ListMCPs(MCPScopeUser)
+	if err != nil {
+		t.Fatalf("ListMCPs() error = %v", err)
+	}
+	want := map[string]MCPServerSpec{
+		"github": {
+			Command:     "npx",
+			Args:        []string{"-y", "@modelcontextprotocol/server-github"},
+			Timeout:     120 * time.Second,
+			AutoApprove: []string{"list_issues", "get_issue"},
+			Scope:       MCPScopeUser,
+		},
+		"docs": {URL: "https://docs.example.com/mcp", Transport: TransportHTTP, Scope: MCPScopeUser},
+	}
+	if !reflect.DeepEqual(specs, want) {
+		t.Errorf("ListMCPs() = %#v\nwant %#v", specs, want)
+	}
+
+	// Reinstalling keeps the settings made in Cline
+	github := MCPServerSpec{Command: "npx", Args: []string{"-y", "@modelcontextprotocol/server-github@latest"}}
+	if err := a.InstallMCP("github", github, MCPScopeUser); err != nil {
+		t.Fatalf("InstallMCP() error = %v", err)
+	}
+	cfg, err := config.ReadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := config.GetMap(cfg, []string{"mcpServers", "github"})
+	wantEntry := map[string]interface{}{
+		"command":       "npx",
+		"args":          []interface{}{"-y", "@modelcontextprotocol/server-github@latest"},
+		"autoApprove":   []interface{}{"list_issues", "get_issue"},
+		"disabled":      false,
+		"timeout":       json.Number("120"),
+		"transportType": "stdio",
+	}
+	if !reflect.DeepEqual(got, wantEntry) {
+		t.Errorf("github entry = %#v\nwant %#v", got, wantEntry)
+	}
+}
@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCodexKeepsCommentsAndProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	fixture := `# personal defaults
+model = "gpt-5-codex"
+
+# slower, for reviews
+[profiles.deep]
+model = "gpt-5"
+model_reasoning_effort = "high"
+
+[mcp_servers.github]
+command = "npx"
+args = ["-y", "@modelcontextprotocol/server-github"]
+
+[mcp_servers.github.env]
+GITHUB_PERSONAL_ACCESS_TOKEN = "ghp_xxx" # rotated monthly
+
+[profiles.ci]
+approval_policy = "never"
+`
+	if err := os.WriteFile(path, []byte(fixture), 0644); err != nil {
+		t.Fatal(err)
+	}
+	a := builtinAgentAt(t, "Codex", path).(MCPHost)
+
+	docs := MCPServerSpec{URL: "https://docs.example.com/mcp"}
+	if err := a.InstallMCP("docs", docs, MCPScopeUser); err != nil {
+		t.Fatalf("InstallMCP() error = %v", err)
+	}
+	if err := a.RemoveMCP("github", MCPScopeUser); err != nil {
+		t.Fatalf("RemoveMCP() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# personal defaults
+model = "gpt-5-codex"
+
+# slower, for reviews
+[profiles.deep]
+model = "gpt-5"
+model_reasoning_effort = "high"
+
+[mcp_servers.docs]
+url = "https://docs.example.com/mcp"
+
+[profiles.ci]
+approval_policy = "never"
+`
+	if string(data) != want {
+		t.Errorf("config.toml =\n%s\nwant\n%s", data, want)
+	}
+	if specs, err := a.ListMCPs(MCPScopeUser); err != nil || len(specs) != 1 || specs["docs"].URL == "" {
+		t.Errorf("ListMCPs() = %v, %v", specs, err)
+	}
+}
@@ -0,0 +1,262 @@
+package agent
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/agentsdance/agentx/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// continueBlock is the layout agentx writes for a new block file or config
+type continueBlock struct {
+	Name       string                   `yaml:"name"`
+	Version    string                   `yaml:"version"`
+	Schema     string                   `yaml:"schema"`
+	MCPServers []map[string]interface{} `yaml:"mcpServers"`
+}
+
+// continueEntry is a server found in a Continue config or block file
+type continueEntry struct {
+	native map[string]interface{}
+	path   string
+}
+
+// ContinueAgent extends the declarative Continue agent, whose mcpServers
+// is a list of named entries kept in config.yaml and in block files under
+// mcpServers/ next to it (user scope) or in .continue/mcpServers of the
+// workspace (project scope). Servers are listed from both places. New
+// servers are written as block files, so the user's config.yaml is only
+// changed for servers already defined in it.
+type ContinueAgent struct {
+	*DeclarativeAgent
+}
+
+func newContinueAgent(base *DeclarativeAgent) Agent {
+	return &ContinueAgent{DeclarativeAgent: base}
+}
+
+// blocksDir returns the block file directory for scope
+func (a *ContinueAgent) blocksDir(scope MCPScope) (string, error) {
+	if scope == MCPScopeUser {
+		return filepath.Join(filepath.Dir(a.configPath), "mcpServers"), nil
+	}
+	return a.MCPConfigPath(scope)
+}
+
+// entries returns the servers configured in scope: config.yaml's first
+// (user scope only), then block files in name order
+func (a *ContinueAgent) entries(scope MCPScope) (map[string]continueEntry, error) {
+	dir, err := a.blocksDir(scope)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	if scope == MCPScopeUser {
+		paths = append(paths, a.configPath)
+	}
+	blocks, err := continueBlockFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	paths = append(paths, blocks...)
+
+	result := make(map[string]continueEntry)
+	for _, path := range paths {
+		cfg, err := a.readConfig(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, native := range continueServers(cfg) {
+			name := stringValue(native, "name")
+			if _, exists := result[name]; !exists {
+				result[name] = continueEntry{native: native, path: path}
+			}
+		}
+	}
+	return result, nil
+}
+
+func (a *ContinueAgent) HasMCP(name string, scope MCPScope) (bool, error) {
+	entries, err := a.entries(scope)
+	if err != nil {
+		return false, err
+	}
+	_, ok := entries[name]
+	return ok, nil
+}
+
+func (a *ContinueAgent) InstallMCP(name string, spec MCPServerSpec, scope MCPScope) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	entries, err := a.entries(scope)
+	if err != nil {
+		return err
+	}
+	native := a.translator.fromSpec(spec)
+	native["name"] = name
+
+	path := ""
+	if existing, ok := entries[name]; ok {
+		path = existing.path
+	} else {
+		dir, err := a.blocksDir(scope)
+		if err != nil {
+			return err
+		}
+		path = filepath.Join(dir, continueBlockFileName(name))
+		if !fileExists(path) {
+			return writeContinueBlock(path, continueBlock{
+				Name:       name,
+				Version:    "0.0.1",
+				Schema:     "v1",
+				MCPServers: []map[string]interface{}{native},
+			})
+		}
+	}
+	return a.updateServers(path, func(servers []interface{}) []interface{} {
+		for i, raw := range servers {
+			if entry, ok := raw.(map[string]interface{}); ok && stringValue(entry, "name") == name {
+				servers[i] = a.translator.merge(entry, native)
+				return servers
+			}
+		}
+		return append(servers, native)
+	})
+}
+
+func (a *ContinueAgent) RemoveMCP(name string, scope MCPScope) error {
+	entries, err := a.entries(scope)
+	if err != nil {
+		return err
+	}
+	existing, ok := entries[name]
+	if !ok {
+		return nil
+	}
+	return a.updateServers(existing.path, func(servers []interface{}) []interface{} {
+		kept := servers[:0]
+		for _, raw := range servers {
+			if entry, ok := raw.(map[string]interface{}); ok && stringValue(entry, "name") == name {
+				continue
+			}
+			kept = append(kept, raw)
+		}
+		return kept
+	})
+}
+
+func (a *ContinueAgent) ListMCPs(scope MCPScope) (map[string]MCPServerSpec, error) {
+	entries, err := a.entries(scope)
+	if err != nil {
+		return nil, err
+	}
+	servers := make(map[string]map[string]interface{}, len(entries))
+	for name, entry := range entries {
+		servers[name] = entry.native
+	}
+	return a.specsFromNative(servers, scope), nil
+}
+
+// InitConfig creates a minimal config.yaml; servers go in block files
+func (a *ContinueAgent) InitConfig() (bool, error) {
+	if fileExists(a.configPath) {
+		return false, nil
+	}
+	if err := writeContinueBlock(a.configPath, continueBlock{
+		Name:       "Local Assistant",
+		Version:    "1.0.0",
+		Schema:     "v1",
+		MCPServers: []map[string]interface{}{},
+	}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// updateServers rewrites the mcpServers list of the file at path. A block
+// file left without servers is deleted.
+func (a *ContinueAgent) updateServers(path string, update func([]interface{}) []interface{}) error {
+	err := a.updateConfig(path, func(cfg map[string]interface{}) error {
+		servers, _ := cfg["mcpServers"].([]interface{})
+		servers = update(servers)
+		if len(servers) == 0 && path != a.configPath {
+			return errEmptyBlock
+		}
+		cfg["mcpServers"] = servers
+		return nil
+	})
+	if !errors.Is(err, errEmptyBlock) {
+		return err
+	}
+	return config.RemoveFile(path)
+}
+
+// errEmptyBlock stops updateServers from writing a block file it deletes
+var errEmptyBlock = errors.New("block file has no servers left")
+
+// continueServers returns the named entries of a mcpServers list; entries
+// such as "uses:" references to hub blocks are skipped
+func continueServers(cfg map[string]interface{}) []map[string]interface{} {
+	list, _ := cfg["mcpServers"].([]interface{})
+	servers := make([]map[string]interface{}, 0, len(list))
+	for _, raw := range list {
+		if entry, ok := raw.(map[string]interface{}); ok && stringValue(entry, "name") != "" {
+			servers = append(servers, entry)
+		}
+	}
+	return servers
+}
+
+// continueBlockFiles returns the YAML block files in dir, sorted
+func continueBlockFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var paths []string
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, entry.Name()))
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// continueBlockFileName returns the block file name for a server
+func continueBlockFileName(name string) string {
+	slug := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return '-'
+	}, name)
+	slug = strings.Trim(slug, "-.")
+	if slug == "" {
+		// A name made only of symbols; servers sharing the fallback file
+		// are appended to it like any other block
+		slug = "mcp-server"
+	}
+	return slug + ".yaml"
+}
+
+func writeContinueBlock(path string, block continueBlock) error {
+	data, err := yaml.Marshal(block)
+	if err != nil {
+		return err
+	}
+	return config.WriteFile(path, data)
+}
@@ -0,0 +1,164 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/agentsdance/agentx/internal/config"
+)
+
+// continueAgentAt returns the built-in Continue agent using a copy of the
+// testdata/continue global dir
+func continueAgentAt(t *testing.T) (*ContinueAgent, string) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), ".continue")
+	for _, name := range []string{"config.yaml", filepath.Join("mcpServers", "browser.yaml")} {
+		data, err := os.ReadFile(filepath.Join("testdata", "continue", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, ok := builtinAgentAt(t, "Continue", filepath.Join(dir, "config.yaml")).(*ContinueAgent)
+	if !ok {
+		t.Fatal("Continue definition did not use the continue adapter")
+	}
+	return a, dir
+}
+
+func TestContinueListMCPs(t *testing.T) {
+	a, _ := continueAgentAt(t)
+	specs, err := a.ListMCPs(MCPScopeUser)
+	if err != nil {
+		t.Fatalf("ListMCPs() error = %v", err)
+	}
+	want := map[string]MCPServerSpec{
+		"SQLite":     {Command: "npx", Args: []string{"-y", "mcp-sqlite", "/data/app.db"}, Scope: MCPScopeUser},
+		"Playwright": {Command: "npx", Args: []string{"@playwright/mcp@latest"}, Scope: MCPScopeUser},
+		"Docs":       {URL: "https://docs.example.com/mcp", Transport: TransportHTTP, Scope: MCPScopeUser},
+	}
+	if !reflect.DeepEqual(specs, want) {
+		t.Errorf("ListMCPs() = %#v\nwant %#v", specs, want)
+	}
+}
+
+func TestContinueInstallWritesBlocks(t *testing.T) {
+	a, dir := continueAgentAt(t)
+	configPath := filepath.Join(dir, "config.yaml")
+	original, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := MCPServerSpec{Command: "npx", Args: []string{"-y", "@upstash/context7-mcp"}, Env: map[string]string{"CONTEXT7_API_KEY": "key"}}
+	if err := a.InstallMCP("Context7", spec, MCPScopeUser); err != nil {
+		t.Fatalf("InstallMCP() error = %v", err)
+	}
+	block, err := config.ReadYAMLConfig(filepath.Join(dir, "mcpServers", "context7.yaml"))
+	if err != nil {
+		t.Fatalf("block file not written: %v", err)
+	}
+	wantBlock := map[string]interface{}{
+		"name":    "Context7",
+		"version": "0.0.1",
+		"schema":  "v1",
+		"mcpServers": []interface{}{map[string]interface{}{
+			"name":    "Context7",
+			"command": "npx",
+			"args":    []interface{}{"-y", "@upstash/context7-mcp"},
+			"env":     map[string]interface{}{"CONTEXT7_API_KEY": "key"},
+		}},
+	}
+	if !reflect.DeepEqual(block, wantBlock) {
+		t.Errorf("block = %#v\nwant %#v", block, wantBlock)
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != string(original) {
+		t.Errorf("InstallMCP() rewrote config.yaml:\n%s", data)
+	}
+
+	// Removing the last server of a block deletes the file; removing one
+	// of several keeps the others
+	if err := a.RemoveMCP("Context7", MCPScopeUser); err != nil {
+		t.Fatalf("RemoveMCP() error = %v", err)
+	}
+	if fileExists(filepath.Join(dir, "mcpServers", "context7.yaml")) {
+		t.Error("empty block file was not removed")
+	}
+	if err := a.RemoveMCP("Docs", MCPScopeUser); err != nil {
+		t.Fatalf("RemoveMCP() error = %v", err)
+	}
+	specs, err := a.ListMCPs(MCPScopeUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := specs["Playwright"]; !ok || len(specs) != 2 {
+		t.Errorf("ListMCPs() after removals = %v", specs)
+	}
+
+	// Servers defined inline are updated where they are
+	sqlite := specs["SQLite"]
+	sqlite.Args = []string{"-y", "mcp-sqlite", "/data/other.db"}
+	if err := a.InstallMCP("SQLite", sqlite, MCPScopeUser); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.ReadYAMLConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	servers := cfg["mcpServers"].([]interface{})
+	if len(servers) != 2 || servers[1].(map[string]interface{})["uses"] != "anthropic/memory-mcp" {
+		t.Errorf("config.yaml mcpServers = %v", servers)
+	}
+	if args := servers[0].(map[string]interface{})["args"]; !reflect.DeepEqual(args, []interface{}{"-y", "mcp-sqlite", "/data/other.db"}) {
+		t.Errorf("SQLite args = %v", args)
+	}
+	// Keys agentx doesn't manage survive the update
+	if timeout := servers[0].(map[string]interface{})["connectionTimeout"]; timeout != 5000 {
+		t.Errorf("SQLite connectionTimeout = %v, want it kept", timeout)
+	}
+}
+
+func TestContinueBlockFileName(t *testing.T) {
+	tests := map[string]string{
+		"Context7":  "context7.yaml",
+		"My Server": "my-server.yaml",
+		".hidden-":  "hidden.yaml",
+		"@@":        "mcp-server.yaml",
+	}
+	for name, want := range tests {
+		if got := continueBlockFileName(name); got != want {
+			t.Errorf("continueBlockFileName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestContinueProjectScope(t *testing.T) {
+	a, _ := continueAgentAt(t)
+	root := chdirProject(t)
+	spec := MCPServerSpec{Command: "uvx", Args: []string{"mcp-server-git"}}
+	if err := a.InstallMCP("git", spec, MCPScopeProject); err != nil {
+		t.Fatalf("InstallMCP() error = %v", err)
+	}
+	if !fileExists(filepath.Join(root, ".continue", "mcpServers", "git.yaml")) {
+		t.Error("project block file not written to the workspace")
+	}
+	specs, err := a.ListMCPs(MCPScopeProject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec.Scope = MCPScopeProject
+	if !reflect.DeepEqual(specs, map[string]MCPServerSpec{"git": spec}) {
+		t.Errorf("ListMCPs(project) = %#v", specs)
+	}
+	if ok, _ := a.HasMCP("git", MCPScopeUser); ok {
+		t.Error("project server listed in user scope")
+	}
+}
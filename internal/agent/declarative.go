@@ -0,0 +1,390 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/agentsdance/agentx/internal/config"
+	"github.com/agentsdance/agentx/internal/plugins"
+	"github.com/agentsdance/agentx/internal/skills"
+)
+
+// adapters are Go extensions for definitions whose agents need behaviour
+// beyond what a definition can describe, keyed by the definition's adapter
+var adapters = map[string]func(*DeclarativeAgent) Agent{
+	"claude":         newClaudeAgent,
+	"claude-desktop": newClaudeDesktopAgent,
+	"cline":          newClineAgent,
+	"continue":       newContinueAgent,
+	"gemini":         newGeminiAgent,
+	"goose":          newGooseAgent,
+	"opencode":       newOpenCodeAgent,
+	"vscode":         newVSCodeAgent,
+	"windsurf":       newWindsurfAgent,
+	"zed":            newZedAgent,
+}
+
+// NewAgent creates the agent for a definition, wrapping it in its adapter
+// when one is named
+func NewAgent(def Definition) Agent {
+	base := NewDeclarativeAgent(def)
+	if adapter, ok := adapters[def.Adapter]; ok {
+		return adapter(base)
+	}
+	return base
+}
+
+// DeclarativeAgent is an agent driven entirely by a Definition
+type DeclarativeAgent struct {
+	def        Definition
+	configPath string
+	detectPath string
+	mcpKey     []string
+	translator mcpTranslator
+	instructionsFile
+}
+
+// NewDeclarativeAgent creates an agent from a definition
+func NewDeclarativeAgent(def Definition) *DeclarativeAgent {
+	a := &DeclarativeAgent{
+		def:              def,
+		configPath:       expandPathWith(def.Config, def.Env),
+		detectPath:       expandPathWith(def.Detect, def.Env),
+		mcpKey:           config.SplitKeyPath(def.MCPKey),
+		translator:       newMCPTranslator(def.Fields),
+		instructionsFile: instructionsFile{path: expandPathWith(def.Instructions, def.Env)},
+	}
+	if a.detectPath == "" {
+		a.detectPath = a.configPath
+	}
+	return a
+}
+
+// Definition returns the definition the agent was built from
+func (a *DeclarativeAgent) Definition() Definition {
+	return a.def
+}
+
+func (a *DeclarativeAgent) Name() string {
+	return a.def.Name
+}
+
+// Aliases returns the extra names the agent answers to
+func (a *DeclarativeAgent) Aliases() []string {
+	return a.def.Aliases
+}
+
+func (a *DeclarativeAgent) ConfigPath() string {
+	return a.configPath
+}
+
+// Exists reports whether one of the agent's binaries or its config is
+// present. A config without a binary still counts: GUI apps often run with
+// a minimal PATH, and some agents are installed outside it.
+func (a *DeclarativeAgent) Exists() bool {
+	return findBinary(a.def.Binaries, a.def.Env) != "" || fileExists(a.detectPath)
+}
+
+// Detect looks for the agent's binaries and config and probes the version
+// of the first binary found
+func (a *DeclarativeAgent) Detect() DetectionResult {
+	r := DetectionResult{
+		Agent:       a.def.Name,
+		Binary:      findBinary(a.def.Binaries, a.def.Env),
+		ConfigPath:  a.configPath,
+		ConfigFound: fileExists(a.detectPath),
+	}
+	r.setStatus()
+	if len(a.def.Binaries) == 0 && r.ConfigFound {
+		// Nothing to look for on PATH (apps, editor extensions), so the
+		// config is all there is to find
+		r.Status = DetectionInstalled
+	}
+	if r.Binary == "" {
+		return r
+	}
+	args := a.def.VersionArgs
+	if len(args) == 0 {
+		args = []string{"--version"}
+	}
+	v, output, err := probeVersion(r.Binary, args)
+	if err != nil {
+		r.VersionError = err.Error()
+	}
+	r.Version = v
+	r.VersionOutput = output
+	return r
+}
+
+// MinVersion returns the oldest agent version supporting feature, or ""
+func (a *DeclarativeAgent) MinVersion(feature Feature) string {
+	return a.def.MinVersions[feature]
+}
+
+func (a *DeclarativeAgent) Capabilities() Capabilities {
+	return Capabilities{
+		MCP:          true,
+		Skills:       a.def.Skills != nil,
+		Plugins:      a.def.Plugins,
+		Instructions: a.def.Instructions != "",
+	}
+}
+
+func (a *DeclarativeAgent) MCPScopes() []MCPScope {
+	if a.def.ProjectConfig != "" {
+		return []MCPScope{MCPScopeUser, MCPScopeProject}
+	}
+	return []MCPScope{MCPScopeUser}
+}
+
+// MCPConfigPath returns the config file for scope
+func (a *DeclarativeAgent) MCPConfigPath(scope MCPScope) (string, error) {
+	switch scope {
+	case MCPScopeUser:
+		return a.configPath, nil
+	case MCPScopeProject:
+		if a.def.ProjectConfig == "" {
+			break
+		}
+		root, err := ProjectRoot()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(root, filepath.FromSlash(a.def.ProjectConfig)), nil
+	}
+	return "", &UnsupportedError{Agent: a.Name(), Capability: CapabilityMCP, Scope: scope}
+}
+
+// readConfig reads a config file, treating a missing file as empty
+func (a *DeclarativeAgent) readConfig(path string) (map[string]interface{}, error) {
+	cfg, err := config.ReadDocument(path, a.def.Format)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]interface{}{}, nil
+		}
+		return nil, err
+	}
+	if cfg == nil {
+		cfg = map[string]interface{}{}
+	}
+	return cfg, nil
+}
+
+// updateConfig changes the config at path with a locked read-modify-write;
+// mutate may run more than once if the agent rewrites the file meanwhile
+func (a *DeclarativeAgent) updateConfig(path string, mutate func(cfg map[string]interface{}) error) error {
+	return config.UpdateDocument(path, a.def.Format, mutate)
+}
+
+// InitConfig creates the user config with an empty MCP server container
+// if it doesn't exist yet, reporting whether it did so
+func (a *DeclarativeAgent) InitConfig() (bool, error) {
+	if fileExists(a.configPath) {
+		return false, nil
+	}
+	err := a.updateConfig(a.configPath, func(cfg map[string]interface{}) error {
+		config.EnsureMap(cfg, a.mcpKey)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// nativeMCPs returns the raw MCP entries from the config file for scope
+func (a *DeclarativeAgent) nativeMCPs(scope MCPScope) (map[string]map[string]interface{}, error) {
+	path, err := a.MCPConfigPath(scope)
+	if err != nil {
+		return nil, err
+	}
+	return a.nativeMCPsAt(path, a.mcpKey)
+}
+
+// nativeMCPsAt returns the raw MCP entries under key in the file at path
+func (a *DeclarativeAgent) nativeMCPsAt(path string, key []string) (map[string]map[string]interface{}, error) {
+	cfg, err := a.readConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]map[string]interface{}{}
+	for name, raw := range config.GetMap(cfg, key) {
+		if serverCfg, ok := raw.(map[string]interface{}); ok {
+			result[name] = serverCfg
+		}
+	}
+	return result, nil
+}
+
+func (a *DeclarativeAgent) HasMCP(name string, scope MCPScope) (bool, error) {
+	servers, err := a.nativeMCPs(scope)
+	if err != nil {
+		return false, err
+	}
+	_, ok := servers[name]
+	return ok, nil
+}
+
+func (a *DeclarativeAgent) InstallMCP(name string, spec MCPServerSpec, scope MCPScope) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	path, err := a.MCPConfigPath(scope)
+	if err != nil {
+		return err
+	}
+	return a.setMCPEntry(path, name, a.translator.fromSpec(spec))
+}
+
+func (a *DeclarativeAgent) RemoveMCP(name string, scope MCPScope) error {
+	path, err := a.MCPConfigPath(scope)
+	if err != nil {
+		return err
+	}
+	return a.deleteMCPEntry(path, name)
+}
+
+// setMCPEntry adds or replaces one native MCP entry in the config at path
+func (a *DeclarativeAgent) setMCPEntry(path, name string, entry map[string]interface{}) error {
+	return a.updateConfig(path, func(cfg map[string]interface{}) error {
+		config.EnsureMap(cfg, a.mcpKey)[name] = entry
+		return nil
+	})
+}
+
+// deleteMCPEntry removes one native MCP entry from the config at path
+func (a *DeclarativeAgent) deleteMCPEntry(path, name string) error {
+	return a.updateConfig(path, func(cfg map[string]interface{}) error {
+		servers := config.GetMap(cfg, a.mcpKey)
+		if _, ok := servers[name]; !ok {
+			return config.ErrUnchanged
+		}
+		delete(servers, name)
+		return nil
+	})
+}
+
+func (a *DeclarativeAgent) ListMCPs(scope MCPScope) (map[string]MCPServerSpec, error) {
+	servers, err := a.nativeMCPs(scope)
+	if err != nil {
+		return nil, err
+	}
+	return a.specsFromNative(servers, scope), nil
+}
+
+// specsFromNative translates raw entries listed from scope
+func (a *DeclarativeAgent) specsFromNative(servers map[string]map[string]interface{}, scope MCPScope) map[string]MCPServerSpec {
+	specs := a.translator.specsFromNative(servers)
+	for name, spec := range specs {
+		spec.Scope = scope
+		specs[name] = spec
+	}
+	return specs
+}
+
+// SkillManager returns a manager for the agent's skill directories
+func (a *DeclarativeAgent) SkillManager() (*skills.DefaultSkillManager, error) {
+	return a.skillManager()
+}
+
+func (a *DeclarativeAgent) skillManager() (*skills.DefaultSkillManager, error) {
+	if a.def.Skills == nil {
+		return nil, &UnsupportedError{Agent: a.Name(), Capability: CapabilitySkills}
+	}
+	project := a.def.Skills.Project
+	if project != "" && !filepath.IsAbs(project) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		project = filepath.Join(cwd, project)
+	}
+	personal := expandPathWith(a.def.Skills.Personal, a.def.Env)
+	return skills.NewSkillManagerForBase(personal, project, a.def.Skills.Commands), nil
+}
+
+func (a *DeclarativeAgent) HasSkill(skillName string) (bool, error) {
+	mgr, err := a.skillManager()
+	if err != nil {
+		return false, err
+	}
+	skill, err := mgr.Get(skillName)
+	if err != nil {
+		return false, nil // Not found is not an error
+	}
+	return skill != nil, nil
+}
+
+func (a *DeclarativeAgent) InstallSkill(skillName, source string) error {
+	mgr, err := a.skillManager()
+	if err != nil {
+		return err
+	}
+	_, err = mgr.Install(source, skills.ScopePersonal)
+	return err
+}
+
+func (a *DeclarativeAgent) RemoveSkill(skillName string) error {
+	mgr, err := a.skillManager()
+	if err != nil {
+		return err
+	}
+	return mgr.Remove(skillName, skills.ScopePersonal)
+}
+
+func (a *DeclarativeAgent) pluginManager() (*plugins.DefaultPluginManager, error) {
+	if !a.def.Plugins {
+		return nil, &UnsupportedError{Agent: a.Name(), Capability: CapabilityPlugins}
+	}
+	return plugins.NewPluginManager(), nil
+}
+
+func (a *DeclarativeAgent) HasPlugin(pluginName string) (bool, error) {
+	mgr, err := a.pluginManager()
+	if err != nil {
+		return false, err
+	}
+	plugin, err := mgr.Get(pluginName)
+	if err != nil {
+		return false, nil // Not found is not an error
+	}
+	return plugin != nil, nil
+}
+
+func (a *DeclarativeAgent) InstallPlugin(pluginName, source string) error {
+	mgr, err := a.pluginManager()
+	if err != nil {
+		return err
+	}
+	_, err = mgr.Install(source)
+	return err
+}
+
+func (a *DeclarativeAgent) RemovePlugin(pluginName string) error {
+	mgr, err := a.pluginManager()
+	if err != nil {
+		return err
+	}
+	return mgr.Remove(pluginName)
+}
+
+func (a *DeclarativeAgent) InstructionsPath() string {
+	return a.instructionsFile.InstructionsPath()
+}
+
+func (a *DeclarativeAgent) ReadInstructions() (string, error) {
+	if a.def.Instructions == "" {
+		return "", &UnsupportedError{Agent: a.Name(), Capability: CapabilityInstructions}
+	}
+	return a.instructionsFile.ReadInstructions()
+}
+
+func (a *DeclarativeAgent) WriteInstructions(content string) error {
+	if a.def.Instructions == "" {
+		return &UnsupportedError{Agent: a.Name(), Capability: CapabilityInstructions}
+	}
+	if err := CheckFeature(a, FeatureInstructions); err != nil {
+		return err
+	}
+	return a.instructionsFile.WriteInstructions(content)
+}
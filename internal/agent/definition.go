@@ -0,0 +1,307 @@
+package agent
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/agentsdance/agentx/internal/config"
+	"github.com/agentsdance/agentx/internal/home"
+	"github.com/agentsdance/agentx/internal/version"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed definitions/*.yaml
+var builtinDefinitionFS embed.FS
+
+// Definition describes an agent adapter declaratively. Built-in agents are
+// embedded definitions; users can add or override agents with files in
+// ~/.agentx/agents.d/.
+type Definition struct {
+	// Name is the display name, and the key user definitions override by
+	Name string `yaml:"name" toml:"name"`
+	// Aliases are extra names accepted by --agent and friends
+	Aliases []string `yaml:"aliases,omitempty" toml:"aliases,omitempty"`
+	// Adapter selects a built-in Go extension for behaviour that can't be
+	// described here (e.g. "gemini" for extension-provided servers)
+	Adapter string `yaml:"adapter,omitempty" toml:"adapter,omitempty"`
+
+	// Extends makes this definition a named instance of another agent, such
+	// as claude@work extending claude. An instance sets only its name,
+	// aliases, dir and env; everything else comes from the agent it extends.
+	Extends string `yaml:"extends,omitempty" toml:"extends,omitempty"`
+	// Dir relocates an instance by setting the agent's HomeEnv
+	Dir string `yaml:"dir,omitempty" toml:"dir,omitempty"`
+	// Env overrides environment variables when expanding the agent's paths
+	Env map[string]string `yaml:"env,omitempty" toml:"env,omitempty"`
+	// HomeEnv is the environment variable that relocates the agent's config
+	// directory, e.g. CLAUDE_CONFIG_DIR
+	HomeEnv string `yaml:"home_env,omitempty" toml:"home_env,omitempty"`
+
+	// Config is the config path template; see expandPath
+	Config string `yaml:"config" toml:"config"`
+	// ProjectConfig is the project-scoped config path, relative to the
+	// project root; empty if the agent has no project config
+	ProjectConfig string `yaml:"project_config,omitempty" toml:"project_config,omitempty"`
+	// Detect is the path whose existence marks the agent as present,
+	// defaulting to Config
+	Detect string `yaml:"detect,omitempty" toml:"detect,omitempty"`
+	// Binaries are the agent's executables, looked up on PATH unless they
+	// are paths; finding one marks the agent as installed
+	Binaries []string `yaml:"binaries,omitempty" toml:"binaries,omitempty"`
+	// VersionArgs make a binary print its version, defaulting to --version
+	VersionArgs []string `yaml:"version_args,omitempty" toml:"version_args,omitempty"`
+	// MinVersions maps features to the oldest agent version supporting them
+	MinVersions map[Feature]string `yaml:"min_versions,omitempty" toml:"min_versions,omitempty"`
+	// Format is the config file format: json (comments and trailing
+	// commas allowed), jsonc (the same), toml or yaml
+	Format config.Format `yaml:"format" toml:"format"`
+	// MCPKey is the dot-separated path of the MCP server container
+	MCPKey string `yaml:"mcp_key" toml:"mcp_key"`
+	// Fields maps MCPServerSpec fields onto native entry keys
+	Fields FieldMapping `yaml:"fields" toml:"fields"`
+
+	Skills       *SkillsDefinition `yaml:"skills,omitempty" toml:"skills,omitempty"`
+	Plugins      bool              `yaml:"plugins,omitempty" toml:"plugins,omitempty"`
+	Instructions string            `yaml:"instructions,omitempty" toml:"instructions,omitempty"`
+
+	// source is the file the definition was loaded from
+	source string
+}
+
+// FieldMapping names the native key for each MCPServerSpec field. Fields
+// left empty are not supported by the agent.
+type FieldMapping struct {
+	Command string `yaml:"command,omitempty" toml:"command,omitempty"`
+	// CommandArray stores the command and its args as one array under the
+	// command key, as opencode does
+	CommandArray bool   `yaml:"command_array,omitempty" toml:"command_array,omitempty"`
+	Args         string `yaml:"args,omitempty" toml:"args,omitempty"`
+	Env          string `yaml:"env,omitempty" toml:"env,omitempty"`
+	Cwd          string `yaml:"cwd,omitempty" toml:"cwd,omitempty"`
+	URL          string `yaml:"url,omitempty" toml:"url,omitempty"`
+	Headers      string `yaml:"headers,omitempty" toml:"headers,omitempty"`
+
+	// URLByTransport overrides the url key per transport
+	URLByTransport map[string]string `yaml:"url_by_transport,omitempty" toml:"url_by_transport,omitempty"`
+	// Transport is the key holding the transport name
+	Transport string `yaml:"transport,omitempty" toml:"transport,omitempty"`
+	// TransportValues maps spec transports to native values when they differ
+	TransportValues map[string]string `yaml:"transport_values,omitempty" toml:"transport_values,omitempty"`
+	// AlwaysTransport writes the transport even if the spec left it implicit
+	AlwaysTransport bool `yaml:"always_transport,omitempty" toml:"always_transport,omitempty"`
+
+	Timeout string `yaml:"timeout,omitempty" toml:"timeout,omitempty"`
+	// TimeoutUnit is "ms" (default) or "s"
+	TimeoutUnit string `yaml:"timeout_unit,omitempty" toml:"timeout_unit,omitempty"`
+	Disabled    string `yaml:"disabled,omitempty" toml:"disabled,omitempty"`
+	// Enabled is the inverse of Disabled, for agents that write enabled
+	// flags; it is always written
+	Enabled string `yaml:"enabled,omitempty" toml:"enabled,omitempty"`
+	// AutoApprove holds the list of tools allowed without asking
+	AutoApprove string `yaml:"auto_approve,omitempty" toml:"auto_approve,omitempty"`
+}
+
+// SkillsDefinition describes where an agent keeps skills. Skills live in
+// <base>/skills and commands in <base>/commands.
+type SkillsDefinition struct {
+	// Personal is the personal base directory template
+	Personal string `yaml:"personal" toml:"personal"`
+	// Project is the project base directory, relative to the working directory
+	Project string `yaml:"project" toml:"project"`
+	// Commands reports whether single-file slash commands are supported
+	Commands bool `yaml:"commands,omitempty" toml:"commands,omitempty"`
+}
+
+// Source returns the file the definition was loaded from
+func (d Definition) Source() string {
+	return d.source
+}
+
+// Validate checks that the definition is complete
+func (d Definition) Validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if d.Extends != "" {
+		if d.Config != "" || d.Adapter != "" || d.MCPKey != "" {
+			return fmt.Errorf("%s: an instance of %s sets only name, aliases, dir and env", d.Name, d.Extends)
+		}
+		return nil
+	}
+	if d.Dir != "" {
+		return fmt.Errorf("%s: dir is only valid with extends", d.Name)
+	}
+	if d.Config == "" {
+		return fmt.Errorf("%s: config is required", d.Name)
+	}
+	if d.ProjectConfig != "" && filepath.IsAbs(d.ProjectConfig) {
+		return fmt.Errorf("%s: project_config must be relative to the project root", d.Name)
+	}
+	switch d.Format {
+	case config.FormatJSON, config.FormatJSONC, config.FormatTOML, config.FormatYAML:
+	default:
+		return fmt.Errorf("%s: unsupported format %q", d.Name, d.Format)
+	}
+	if d.MCPKey == "" {
+		return fmt.Errorf("%s: mcp_key is required", d.Name)
+	}
+	if d.Adapter != "" {
+		if _, ok := adapters[d.Adapter]; !ok {
+			return fmt.Errorf("%s: unknown adapter %q", d.Name, d.Adapter)
+		}
+	}
+	if _, err := timeoutUnit(d.Fields.TimeoutUnit); err != nil {
+		return fmt.Errorf("%s: %w", d.Name, err)
+	}
+	for feature, min := range d.MinVersions {
+		if !feature.known() {
+			return fmt.Errorf("%s: min_versions: unknown feature %q", d.Name, feature)
+		}
+		if _, ok := version.ExtractSemver(min); !ok {
+			return fmt.Errorf("%s: min_versions.%s: invalid version %q", d.Name, feature, min)
+		}
+	}
+	return nil
+}
+
+func timeoutUnit(unit string) (time.Duration, error) {
+	switch unit {
+	case "", "ms":
+		return time.Millisecond, nil
+	case "s":
+		return time.Second, nil
+	}
+	return 0, fmt.Errorf("unsupported timeout_unit %q", unit)
+}
+
+// ParseDefinition parses a definition in YAML or TOML, chosen by the file
+// extension of name
+func ParseDefinition(name string, data []byte) (Definition, error) {
+	var def Definition
+	var err error
+	switch strings.ToLower(path.Ext(name)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &def)
+	case ".toml":
+		err = toml.Unmarshal(data, &def)
+	default:
+		return def, fmt.Errorf("%s: unsupported definition file type", name)
+	}
+	if err != nil {
+		return def, fmt.Errorf("%s: %w", name, err)
+	}
+	def.source = name
+	if err := def.Validate(); err != nil {
+		return def, fmt.Errorf("%s: %w", name, err)
+	}
+	return def, nil
+}
+
+// BuiltinDefinitions returns the embedded definitions for built-in agents
+func BuiltinDefinitions() []Definition {
+	entries, err := builtinDefinitionFS.ReadDir("definitions")
+	if err != nil {
+		panic(err)
+	}
+	defs := make([]Definition, 0, len(entries))
+	for _, entry := range entries {
+		name := path.Join("definitions", entry.Name())
+		data, err := builtinDefinitionFS.ReadFile(name)
+		if err != nil {
+			panic(err)
+		}
+		def, err := ParseDefinition(name, data)
+		if err != nil {
+			panic(err)
+		}
+		defs = append(defs, def)
+	}
+	return defs
+}
+
+// UserDefinitionsDir returns the directory user definitions are loaded from
+// (~/.agentx/agents.d)
+func UserDefinitionsDir() (string, error) {
+	dir, err := home.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, ".agentx", "agents.d"), nil
+}
+
+// LoadUserDefinitions loads every .yaml, .yml and .toml definition in dir.
+// Invalid files are skipped and reported in the returned error.
+func LoadUserDefinitions(dir string) ([]Definition, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	var defs []Definition
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml", ".toml":
+		default:
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		def, err := ParseDefinition(path, data)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		defs = append(defs, def)
+	}
+	return defs, errors.Join(errs...)
+}
+
+// LoadDefinitions returns the built-in definitions merged with user
+// definitions. A user definition replaces the built-in one with the same
+// name (case-insensitive); others, including named instances, are
+// appended.
+func LoadDefinitions() ([]Definition, error) {
+	defs := BuiltinDefinitions()
+	dir, err := UserDefinitionsDir()
+	if err != nil {
+		return defs, err
+	}
+	userDefs, err := LoadUserDefinitions(dir)
+	for _, userDef := range userDefs {
+		replaced := false
+		for i := range defs {
+			if strings.EqualFold(defs[i].Name, userDef.Name) {
+				defs[i] = userDef
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			defs = append(defs, userDef)
+		}
+	}
+	defs, instErr := resolveInstances(defs)
+	return defs, errors.Join(err, instErr)
+}
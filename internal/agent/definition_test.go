@@ -0,0 +1,217 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// builtinDefinition returns the embedded definition with the given name
+func builtinDefinition(t *testing.T, name string) Definition {
+	t.Helper()
+	for _, def := range BuiltinDefinitions() {
+		if def.Name == name {
+			return def
+		}
+	}
+	t.Fatalf("no built-in definition named %q", name)
+	return Definition{}
+}
+
+// builtinAgentAt builds a built-in agent whose config lives at path
+func builtinAgentAt(t *testing.T, name, path string) Agent {
+	t.Helper()
+	def := builtinDefinition(t, name)
+	def.Config = path
+	def.Detect = ""
+	return NewAgent(def)
+}
+
+func builtinTranslator(t *testing.T, name string) mcpTranslator {
+	t.Helper()
+	return newMCPTranslator(builtinDefinition(t, name).Fields)
+}
+
+func TestBuiltinDefinitions(t *testing.T) {
+	want := []string{"Claude Code", "Claude Desktop", "Cline", "Codex", "Continue", "Cursor", "Droid", "Gemini cli", "Goose", "opencode", "Roo Code", "VS Code", "Windsurf", "Zed"}
+	defs := BuiltinDefinitions()
+	if len(defs) != len(want) {
+		t.Fatalf("got %d built-in definitions, want %d", len(defs), len(want))
+	}
+	for i, def := range defs {
+		if def.Name != want[i] {
+			t.Errorf("definition %d = %q, want %q", i, def.Name, want[i])
+		}
+	}
+	if _, ok := NewAgent(builtinDefinition(t, "Gemini cli")).(*GeminiAgent); !ok {
+		t.Errorf("Gemini definition did not use the gemini adapter")
+	}
+}
+
+func TestLoadUserDefinitions(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"kiro.yaml": `
+name: Kiro
+aliases: [kiro-cli]
+config: ~/.kiro/settings/mcp.json
+format: json
+mcp_key: mcpServers
+fields:
+  command: command
+  args: args
+  env: env
+`,
+		"codex.toml": `
+name = "codex"
+config = "/tmp/codex.toml"
+format = "toml"
+mcp_key = "mcp_servers"
+`,
+		"broken.yaml": "name: Broken\nformat: xml\n",
+		"notes.txt":   "ignored",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	defs, err := LoadUserDefinitions(dir)
+	if err == nil {
+		t.Errorf("LoadUserDefinitions() error = nil, want error for broken.yaml")
+	}
+	if len(defs) != 2 {
+		t.Fatalf("got %d definitions, want 2", len(defs))
+	}
+	if defs[0].Name != "codex" || defs[1].Name != "Kiro" {
+		t.Errorf("definitions = %q, %q", defs[0].Name, defs[1].Name)
+	}
+
+	kiro := NewAgent(defs[1])
+	if !matchAgentName(kiro, "KIRO-CLI") {
+		t.Errorf("alias kiro-cli did not match")
+	}
+	caps := kiro.Capabilities()
+	if !caps.MCP || caps.Skills || caps.Plugins || caps.Instructions {
+		t.Errorf("Capabilities() = %+v, want MCP only", caps)
+	}
+}
+
+func TestLoadDefinitionsUserOverride(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, ".agentx", "agents.d")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	override := `
+name: cursor
+config: ~/cursor-mcp.json
+format: json
+mcp_key: mcpServers
+`
+	if err := os.WriteFile(filepath.Join(dir, "cursor.yaml"), []byte(override), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := GetAgentByName("cursor")
+	if a == nil {
+		t.Fatal("GetAgentByName(cursor) = nil")
+	}
+	if want := filepath.Join(home, "cursor-mcp.json"); a.ConfigPath() != want {
+		t.Errorf("ConfigPath() = %q, want %q", a.ConfigPath(), want)
+	}
+	if n := len(GetAllAgents()); n != len(BuiltinDefinitions()) {
+		t.Errorf("got %d agents, want override to replace the built-in", n)
+	}
+}
+
+func TestExpandPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("CODEX_HOME", "")
+
+	if got, want := expandPath("${CODEX_HOME:-~/.codex}/config.toml"), filepath.Join(home, ".codex", "config.toml"); got != want {
+		t.Errorf("expandPath default = %q, want %q", got, want)
+	}
+	t.Setenv("CODEX_HOME", "/opt/codex")
+	if got, want := expandPath("${CODEX_HOME:-~/.codex}/config.toml"), filepath.FromSlash("/opt/codex/config.toml"); got != want {
+		t.Errorf("expandPath override = %q, want %q", got, want)
+	}
+}
+
+func TestExpandPathNested(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("OPENCODE_CONFIG", "")
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+
+	template := "${OPENCODE_CONFIG:-${XDG_CONFIG_HOME:-~/.config}/opencode/opencode.json}"
+	if got, want := expandPath(template), filepath.FromSlash("/xdg/opencode/opencode.json"); got != want {
+		t.Errorf("expandPath nested default = %q, want %q", got, want)
+	}
+	t.Setenv("OPENCODE_CONFIG", "/etc/opencode.json")
+	if got, want := expandPath(template), filepath.FromSlash("/etc/opencode.json"); got != want {
+		t.Errorf("expandPath nested override = %q, want %q", got, want)
+	}
+
+	env := map[string]string{"HOME": "/profiles/work", "CODEX_HOME": ""}
+	t.Setenv("CODEX_HOME", "/opt/codex")
+	if got, want := expandPathWith("${CODEX_HOME:-~/.codex}", env), filepath.FromSlash("/profiles/work/.codex"); got != want {
+		t.Errorf("expandPathWith = %q, want %q", got, want)
+	}
+}
+
+func TestBuiltinEnvOverrides(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("CLAUDE_CONFIG_DIR", "/profiles/claude")
+	t.Setenv("GEMINI_CLI_HOME", "/profiles/gemini")
+	t.Setenv("XDG_CONFIG_HOME", "/profiles/xdg")
+	t.Setenv("OPENCODE_CONFIG", "")
+
+	tests := []struct {
+		agent, config, instructions string
+	}{
+		{"Claude Code", "/profiles/claude/.claude.json", "/profiles/claude/CLAUDE.md"},
+		{"Gemini cli", "/profiles/gemini/.gemini/settings.json", "/profiles/gemini/.gemini/GEMINI.md"},
+		{"opencode", "/profiles/xdg/opencode/opencode.json", "/profiles/xdg/opencode/AGENTS.md"},
+	}
+	for _, tt := range tests {
+		a := NewAgent(builtinDefinition(t, tt.agent))
+		if got := a.ConfigPath(); got != filepath.FromSlash(tt.config) {
+			t.Errorf("%s ConfigPath() = %q, want %q", tt.agent, got, tt.config)
+		}
+		if got := a.(InstructionsHost).InstructionsPath(); got != filepath.FromSlash(tt.instructions) {
+			t.Errorf("%s InstructionsPath() = %q, want %q", tt.agent, got, tt.instructions)
+		}
+	}
+}
+
+func TestRerootedPaths(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("AGENTX_HOME", root)
+	t.Setenv("CLAUDE_CONFIG_DIR", "/real/claude")
+	t.Setenv("CODEX_HOME", "/real/codex")
+
+	if got, want := NewAgent(builtinDefinition(t, "Claude Code")).ConfigPath(), filepath.Join(root, ".claude.json"); got != want {
+		t.Errorf("Claude ConfigPath() = %q, want %q", got, want)
+	}
+	if got, want := NewAgent(builtinDefinition(t, "Codex")).ConfigPath(), filepath.Join(root, ".codex", "config.toml"); got != want {
+		t.Errorf("Codex ConfigPath() = %q, want %q", got, want)
+	}
+	if dir, _ := UserDefinitionsDir(); dir != filepath.Join(root, ".agentx", "agents.d") {
+		t.Errorf("UserDefinitionsDir() = %q", dir)
+	}
+
+	// Named instances still relocate inside the root
+	def, err := builtinDefinition(t, "Claude Code").Instance(Definition{Name: "claude@work", Dir: "~/.claude-work"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := NewAgent(def).ConfigPath(), filepath.Join(root, ".claude-work", ".claude.json"); got != want {
+		t.Errorf("instance ConfigPath() = %q, want %q", got, want)
+	}
+}
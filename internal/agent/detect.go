@@ -0,0 +1,224 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/agentsdance/agentx/internal/version"
+)
+
+// versionTimeout bounds a single --version probe
+const versionTimeout = 5 * time.Second
+
+// DetectionStatus summarises a DetectionResult
+type DetectionStatus string
+
+const (
+	// DetectionInstalled means an agent binary was found, or the config of
+	// an agent that has no binary (a desktop app or editor extension)
+	DetectionInstalled DetectionStatus = "installed"
+	// DetectionConfigOnly means the agent's config exists but no binary
+	// was found: a leftover config, or an agent installed outside PATH
+	DetectionConfigOnly DetectionStatus = "config-only"
+	// DetectionMissing means neither a binary nor a config was found
+	DetectionMissing DetectionStatus = "missing"
+)
+
+// DetectionResult describes how an agent was found on this machine
+type DetectionResult struct {
+	Agent  string          `json:"agent"`
+	Status DetectionStatus `json:"status"`
+	// Binary is the resolved path of the agent executable
+	Binary string `json:"binary,omitempty"`
+	// Version is the semantic version reported by the binary
+	Version string `json:"version,omitempty"`
+	// VersionOutput is the first line the binary printed, kept when it
+	// holds no recognisable version
+	VersionOutput string `json:"version_output,omitempty"`
+	// VersionError is set when the version probe failed
+	VersionError string `json:"version_error,omitempty"`
+	ConfigPath   string `json:"config_path"`
+	ConfigFound  bool   `json:"config_found"`
+}
+
+// Found reports whether the agent was found at all
+func (r DetectionResult) Found() bool {
+	return r.Binary != "" || r.ConfigFound
+}
+
+// AtLeast reports whether the detected version is min or newer. The second
+// result is false when the version is unknown.
+func (r DetectionResult) AtLeast(min string) (bool, bool) {
+	cmp, ok := version.CompareSemver(r.Version, min)
+	if !ok {
+		return false, false
+	}
+	return cmp >= 0, true
+}
+
+func (r *DetectionResult) setStatus() {
+	switch {
+	case r.Binary != "":
+		r.Status = DetectionInstalled
+	case r.ConfigFound:
+		r.Status = DetectionConfigOnly
+	default:
+		r.Status = DetectionMissing
+	}
+}
+
+// Detector is implemented by agents that can look for their binaries
+type Detector interface {
+	Detect() DetectionResult
+}
+
+// Detect looks for an agent's binaries and config and probes its version.
+// Agents that can't detect binaries report their config only.
+func Detect(a Agent) DetectionResult {
+	if d, ok := a.(Detector); ok {
+		return d.Detect()
+	}
+	r := DetectionResult{
+		Agent:       a.Name(),
+		ConfigPath:  a.ConfigPath(),
+		ConfigFound: a.Exists(),
+	}
+	r.setStatus()
+	return r
+}
+
+// DetectAll detects agents concurrently, since version probes may each
+// take a moment, and returns the results in the order of agents
+func DetectAll(agents []Agent) []DetectionResult {
+	results := make([]DetectionResult, len(agents))
+	var wg sync.WaitGroup
+	for i, a := range agents {
+		wg.Add(1)
+		go func(i int, a Agent) {
+			defer wg.Done()
+			results[i] = Detect(a)
+		}(i, a)
+	}
+	wg.Wait()
+	return results
+}
+
+// findBinary returns the first of binaries that exists, looking bare names
+// up on PATH and expanding the others as path templates with env
+func findBinary(binaries []string, env map[string]string) string {
+	for _, binary := range binaries {
+		if !strings.ContainsAny(binary, `/\`) {
+			if path, err := exec.LookPath(binary); err == nil {
+				return path
+			}
+			continue
+		}
+		if path := expandPathWith(binary, env); isExecutable(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+// probeVersion runs binary with args and extracts a version from the first
+// line of its output
+func probeVersion(binary string, args []string) (semver, output string, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), versionTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, binary, args...)
+	out, err := cmd.Output()
+	if ctx.Err() != nil {
+		return "", "", fmt.Errorf("%s timed out", binary)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	output = strings.TrimSpace(strings.SplitN(strings.TrimSpace(string(out)), "\n", 2)[0])
+	semver, ok := version.ExtractSemver(output)
+	if !ok {
+		return "", output, nil
+	}
+	return semver, "", nil
+}
+
+// Feature names something an agent only supports from some version on
+type Feature string
+
+const (
+	FeatureMCPProject   Feature = "mcp-project"
+	FeatureMCPLocal     Feature = "mcp-local"
+	FeatureMCPRemote    Feature = "mcp-remote"
+	FeatureSkills       Feature = "skills"
+	FeaturePlugins      Feature = "plugins"
+	FeatureInstructions Feature = "instructions"
+)
+
+// known reports whether f is one of the features above
+func (f Feature) known() bool {
+	switch f {
+	case FeatureMCPProject, FeatureMCPLocal, FeatureMCPRemote, FeatureSkills, FeaturePlugins, FeatureInstructions:
+		return true
+	}
+	return false
+}
+
+// versionGated is implemented by agents whose features need a minimum
+// agent version
+type versionGated interface {
+	MinVersion(feature Feature) string
+}
+
+// VersionError reports an agent too old for a feature
+type VersionError struct {
+	Agent      string
+	Feature    Feature
+	Version    string
+	MinVersion string
+}
+
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("%s %s does not support %s (requires %s or newer)", e.Agent, e.Version, e.Feature, e.MinVersion)
+}
+
+// CheckFeature returns a *VersionError when the agent's detected version is
+// older than the minimum its definition sets for feature. Features without
+// a minimum, and agents whose version is unknown, pass.
+func CheckFeature(a Agent, feature Feature) error {
+	gated, ok := a.(versionGated)
+	if !ok {
+		return nil
+	}
+	min := gated.MinVersion(feature)
+	if min == "" {
+		return nil
+	}
+	r := Detect(a)
+	if atLeast, known := r.AtLeast(min); known && !atLeast {
+		return &VersionError{Agent: a.Name(), Feature: feature, Version: r.Version, MinVersion: min}
+	}
+	return nil
+}
+
+// CheckMCPFeatures checks the features installing spec in scope relies on
+func CheckMCPFeatures(a Agent, spec MCPServerSpec, scope MCPScope) error {
+	var features []Feature
+	switch scope {
+	case MCPScopeProject:
+		features = append(features, FeatureMCPProject)
+	case MCPScopeLocal:
+		features = append(features, FeatureMCPLocal)
+	}
+	if spec.IsRemote() {
+		features = append(features, FeatureMCPRemote)
+	}
+	for _, feature := range features {
+		if err := CheckFeature(a, feature); err != nil {
+			return err
+		}
+	}
+	return nil
+}
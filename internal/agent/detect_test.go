@@ -0,0 +1,151 @@
+package agent
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeBinary writes an executable script printing output into a fresh
+// PATH directory and returns its path
+func fakeBinary(t *testing.T, name, output string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake binaries are shell scripts")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, name)
+	script := "#!/bin/sh\necho '" + output + "'\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+	return path
+}
+
+func detectionAgent(t *testing.T, configExists bool) *DeclarativeAgent {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "mcp.json")
+	if configExists {
+		if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	def := builtinDefinition(t, "Claude Code")
+	def.Config = path
+	def.Binaries = []string{"claude"}
+	return NewDeclarativeAgent(def)
+}
+
+func TestDetect(t *testing.T) {
+	binary := fakeBinary(t, "claude", "1.0.30 (Claude Code)")
+
+	r := detectionAgent(t, false).Detect()
+	if r.Status != DetectionInstalled || r.Binary != binary || r.Version != "1.0.30" || r.ConfigFound {
+		t.Errorf("Detect() = %+v", r)
+	}
+	if !detectionAgent(t, false).Exists() {
+		t.Errorf("Exists() = false with a binary on PATH")
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if r := detectionAgent(t, true).Detect(); r.Status != DetectionConfigOnly || r.Binary != "" {
+		t.Errorf("Detect() without binary = %+v", r)
+	}
+	if r := detectionAgent(t, false).Detect(); r.Status != DetectionMissing || r.Found() {
+		t.Errorf("Detect() without binary or config = %+v", r)
+	}
+}
+
+func TestDetectBinaryPath(t *testing.T) {
+	binary := fakeBinary(t, "opencode", "opencode 0.15.2")
+	t.Setenv("PATH", t.TempDir())
+
+	a := detectionAgent(t, false)
+	a.def.Binaries = []string{"opencode", binary}
+	if r := a.Detect(); r.Binary != binary || r.Version != "0.15.2" {
+		t.Errorf("Detect() = %+v", r)
+	}
+}
+
+func TestDetectVersionOutput(t *testing.T) {
+	tests := []struct {
+		output, version string
+	}{
+		{"codex-cli 0.46.0", "0.46.0"},
+		{"v0.9.0", "0.9.0"},
+		{"2025.09.18-7ae6800", "2025.09.18-7ae6800"},
+		{"nightly", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			fakeBinary(t, "claude", tt.output)
+			r := detectionAgent(t, false).Detect()
+			if r.Version != tt.version {
+				t.Errorf("Version = %q, want %q", r.Version, tt.version)
+			}
+			if tt.version == "" && r.VersionOutput != tt.output {
+				t.Errorf("VersionOutput = %q, want %q", r.VersionOutput, tt.output)
+			}
+		})
+	}
+}
+
+func TestCheckFeature(t *testing.T) {
+	fakeBinary(t, "claude", "1.0.30 (Claude Code)")
+	a := detectionAgent(t, false)
+	a.def.MinVersions = map[Feature]string{
+		FeatureMCPProject: "1.0.0",
+		FeatureMCPLocal:   "1.2.0",
+	}
+
+	if err := CheckFeature(a, FeatureMCPProject); err != nil {
+		t.Errorf("CheckFeature(project) error = %v", err)
+	}
+	if err := CheckFeature(a, FeatureSkills); err != nil {
+		t.Errorf("CheckFeature(ungated) error = %v", err)
+	}
+	err := CheckMCPFeatures(a, MCPServerSpec{Command: "npx"}, MCPScopeLocal)
+	var versionErr *VersionError
+	if !errors.As(err, &versionErr) || versionErr.Version != "1.0.30" || versionErr.MinVersion != "1.2.0" {
+		t.Fatalf("CheckMCPFeatures(local) error = %v, want VersionError", err)
+	}
+
+	// An unknown version does not block
+	t.Setenv("PATH", t.TempDir())
+	if err := CheckFeature(a, FeatureMCPLocal); err != nil {
+		t.Errorf("CheckFeature() without binary error = %v", err)
+	}
+}
+
+func TestWriteInstructionsChecksVersion(t *testing.T) {
+	fakeBinary(t, "claude", "1.0.30 (Claude Code)")
+	dir := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", dir)
+	a := detectionAgent(t, false)
+	a.def.MinVersions = map[Feature]string{FeatureInstructions: "2.0.0"}
+
+	var versionErr *VersionError
+	if err := a.WriteInstructions("# Notes\n"); !errors.As(err, &versionErr) || versionErr.Feature != FeatureInstructions {
+		t.Fatalf("WriteInstructions() error = %v, want VersionError", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "CLAUDE.md")); !os.IsNotExist(err) {
+		t.Errorf("WriteInstructions() wrote the file despite the version check: %v", err)
+	}
+}
+
+func TestDefinitionMinVersionsValidate(t *testing.T) {
+	def := builtinDefinition(t, "Codex")
+	def.MinVersions = map[Feature]string{FeatureMCPRemote: "latest"}
+	if err := def.Validate(); err == nil {
+		t.Error("Validate() accepted an invalid min version")
+	}
+
+	def.MinVersions = map[Feature]string{"mcp-remot": "1.0.0"}
+	if err := def.Validate(); err == nil || !strings.Contains(err.Error(), `unknown feature "mcp-remot"`) {
+		t.Errorf("Validate() error = %v, want an unknown feature error", err)
+	}
+}
@@ -0,0 +1,402 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/agentsdance/agentx/adapter/protocol"
+	"github.com/agentsdance/agentx/internal/backup"
+	"github.com/agentsdance/agentx/internal/plan"
+)
+
+// adapterTimeout bounds a single adapter call
+const adapterTimeout = 30 * time.Second
+
+// ExternalAgent is an agent implemented by an out-of-process adapter
+type ExternalAgent struct {
+	path string
+	desc protocol.Description
+}
+
+// NewExternalAgent describes the adapter executable at path
+func NewExternalAgent(path string) (*ExternalAgent, error) {
+	a := &ExternalAgent{path: path}
+	if err := a.call(protocol.MethodDescribe, nil, &a.desc); err != nil {
+		return nil, err
+	}
+	if a.desc.Name == "" {
+		a.desc.Name = adapterName(filepath.Base(path))
+	}
+	return a, nil
+}
+
+// discovery caches the adapters found on PATH. Starting every adapter is
+// slow and GetAllAgents runs several times per command, so they are
+// described once per process; a different PATH or root (AGENTX_HOME, which
+// adapters resolve their config against) discovers them again.
+var discovery struct {
+	sync.Mutex
+	done   bool
+	key    string
+	agents []*ExternalAgent
+	err    error
+}
+
+// DiscoverExternalAgents finds agentx-adapter-* executables on PATH and
+// describes them. As with command lookup, the first match for a name wins.
+// Adapters that fail to describe themselves are skipped and reported in
+// the returned error.
+func DiscoverExternalAgents() ([]*ExternalAgent, error) {
+	key := os.Getenv("PATH") + string(os.PathListSeparator) + os.Getenv(protocol.HomeEnvVar)
+	discovery.Lock()
+	defer discovery.Unlock()
+	if !discovery.done || discovery.key != key {
+		discovery.agents, discovery.err = discoverExternalAgents()
+		discovery.done, discovery.key = true, key
+	}
+	return append([]*ExternalAgent(nil), discovery.agents...), discovery.err
+}
+
+func discoverExternalAgents() ([]*ExternalAgent, error) {
+	var agents []*ExternalAgent
+	var errs []error
+	seen := make(map[string]bool)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := adapterName(entry.Name())
+			if name == "" || seen[name] {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			seen[name] = true
+			a, err := NewExternalAgent(path)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			agents = append(agents, a)
+		}
+	}
+	return agents, errors.Join(errs...)
+}
+
+// adapterName returns <name> for an agentx-adapter-<name> file name, or ""
+func adapterName(fileName string) string {
+	if !strings.HasPrefix(fileName, protocol.AdapterPrefix) {
+		return ""
+	}
+	name := strings.TrimPrefix(fileName, protocol.AdapterPrefix)
+	if runtime.GOOS == "windows" {
+		name = strings.TrimSuffix(strings.ToLower(name), ".exe")
+	}
+	return name
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode()&0111 != 0
+}
+
+// AdapterPath returns the adapter executable
+func (a *ExternalAgent) AdapterPath() string {
+	return a.path
+}
+
+func (a *ExternalAgent) Name() string {
+	return a.desc.Name
+}
+
+// Aliases returns the extra names the adapter answers to
+func (a *ExternalAgent) Aliases() []string {
+	return a.desc.Aliases
+}
+
+func (a *ExternalAgent) ConfigPath() string {
+	return a.desc.ConfigPath
+}
+
+// Exists reports what the adapter returned when it was described
+func (a *ExternalAgent) Exists() bool {
+	return a.desc.Exists
+}
+
+// Detect reports the binary and version the adapter described
+func (a *ExternalAgent) Detect() DetectionResult {
+	r := DetectionResult{
+		Agent:       a.desc.Name,
+		Binary:      a.desc.Binary,
+		Version:     a.desc.Version,
+		ConfigPath:  a.desc.ConfigPath,
+		ConfigFound: a.desc.Exists,
+	}
+	r.setStatus()
+	return r
+}
+
+func (a *ExternalAgent) Capabilities() Capabilities {
+	return Capabilities(a.desc.Capabilities)
+}
+
+// MCPScopes returns the scopes the adapter described, defaulting to user
+func (a *ExternalAgent) MCPScopes() []MCPScope {
+	if len(a.desc.MCPScopes) == 0 {
+		return []MCPScope{MCPScopeUser}
+	}
+	return a.desc.MCPScopes
+}
+
+func (a *ExternalAgent) HasMCP(name string, scope MCPScope) (bool, error) {
+	var has bool
+	err := a.call(protocol.MethodHasMCP, &protocol.Params{Name: name, Scope: scope}, &has)
+	return has, err
+}
+
+func (a *ExternalAgent) InstallMCP(name string, spec MCPServerSpec, scope MCPScope) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	if a.planned(a.knownConfigPath(scope), fmt.Sprintf("%s would install MCP server %s (%s scope)", a.desc.Name, name, scope)) {
+		return nil
+	}
+	if err := a.saveConfig(scope); err != nil {
+		return err
+	}
+	wire := toWireSpec(spec)
+	return a.call(protocol.MethodInstallMCP, &protocol.Params{Name: name, Spec: &wire, Scope: scope}, nil)
+}
+
+func (a *ExternalAgent) RemoveMCP(name string, scope MCPScope) error {
+	if a.planned(a.knownConfigPath(scope), fmt.Sprintf("%s would remove MCP server %s (%s scope)", a.desc.Name, name, scope)) {
+		return nil
+	}
+	if err := a.saveConfig(scope); err != nil {
+		return err
+	}
+	return a.call(protocol.MethodRemoveMCP, &protocol.Params{Name: name, Scope: scope}, nil)
+}
+
+func (a *ExternalAgent) ListMCPs(scope MCPScope) (map[string]MCPServerSpec, error) {
+	var wire map[string]protocol.MCPServerSpec
+	if err := a.call(protocol.MethodListMCPs, &protocol.Params{Scope: scope}, &wire); err != nil {
+		return nil, err
+	}
+	servers := make(map[string]MCPServerSpec, len(wire))
+	for name, w := range wire {
+		spec := fromWireSpec(w)
+		if spec.Scope == "" {
+			spec.Scope = scope
+		}
+		servers[name] = spec
+	}
+	return servers, nil
+}
+
+// toWireSpec converts spec to the form adapters receive
+func toWireSpec(spec MCPServerSpec) protocol.MCPServerSpec {
+	return protocol.MCPServerSpec{
+		Command:     spec.Command,
+		Args:        spec.Args,
+		Env:         spec.Env,
+		Cwd:         spec.Cwd,
+		URL:         spec.URL,
+		Headers:     spec.Headers,
+		Transport:   spec.Transport,
+		TimeoutMS:   spec.Timeout.Milliseconds(),
+		Disabled:    spec.Disabled,
+		AutoApprove: spec.AutoApprove,
+		Scope:       spec.Scope,
+	}
+}
+
+// fromWireSpec converts a spec listed by an adapter
+func fromWireSpec(spec protocol.MCPServerSpec) MCPServerSpec {
+	return MCPServerSpec{
+		Command:     spec.Command,
+		Args:        spec.Args,
+		Env:         spec.Env,
+		Cwd:         spec.Cwd,
+		URL:         spec.URL,
+		Headers:     spec.Headers,
+		Transport:   spec.Transport,
+		Timeout:     time.Duration(spec.TimeoutMS) * time.Millisecond,
+		Disabled:    spec.Disabled,
+		AutoApprove: spec.AutoApprove,
+		Scope:       spec.Scope,
+	}
+}
+
+func (a *ExternalAgent) HasSkill(skillName string) (bool, error) {
+	var has bool
+	err := a.call(protocol.MethodHasSkill, &protocol.Params{Name: skillName}, &has)
+	return has, err
+}
+
+func (a *ExternalAgent) InstallSkill(skillName, source string) error {
+	if a.planned("", fmt.Sprintf("%s would install skill %s from %s", a.desc.Name, skillName, source)) {
+		return nil
+	}
+	return a.call(protocol.MethodInstallSkill, &protocol.Params{Name: skillName, Source: source}, nil)
+}
+
+func (a *ExternalAgent) RemoveSkill(skillName string) error {
+	if a.planned("", fmt.Sprintf("%s would remove skill %s", a.desc.Name, skillName)) {
+		return nil
+	}
+	return a.call(protocol.MethodRemoveSkill, &protocol.Params{Name: skillName}, nil)
+}
+
+func (a *ExternalAgent) HasPlugin(pluginName string) (bool, error) {
+	var has bool
+	err := a.call(protocol.MethodHasPlugin, &protocol.Params{Name: pluginName}, &has)
+	return has, err
+}
+
+func (a *ExternalAgent) InstallPlugin(pluginName, source string) error {
+	if a.planned("", fmt.Sprintf("%s would install plugin %s from %s", a.desc.Name, pluginName, source)) {
+		return nil
+	}
+	return a.call(protocol.MethodInstallPlugin, &protocol.Params{Name: pluginName, Source: source}, nil)
+}
+
+func (a *ExternalAgent) RemovePlugin(pluginName string) error {
+	if a.planned("", fmt.Sprintf("%s would remove plugin %s", a.desc.Name, pluginName)) {
+		return nil
+	}
+	return a.call(protocol.MethodRemovePlugin, &protocol.Params{Name: pluginName}, nil)
+}
+
+func (a *ExternalAgent) InstructionsPath() string {
+	return a.desc.InstructionsPath
+}
+
+func (a *ExternalAgent) ReadInstructions() (string, error) {
+	var content string
+	err := a.call(protocol.MethodReadInstructions, nil, &content)
+	return content, err
+}
+
+func (a *ExternalAgent) WriteInstructions(content string) error {
+	if a.planned(a.desc.InstructionsPath, fmt.Sprintf("%s would rewrite its instructions", a.desc.Name)) {
+		return nil
+	}
+	if a.desc.InstructionsPath != "" {
+		if err := backup.Save(a.desc.InstructionsPath); err != nil {
+			return err
+		}
+	}
+	return a.call(protocol.MethodWriteInstructions, &protocol.Params{Content: content}, nil)
+}
+
+// planned notes a change the adapter would make in the active dry run and
+// reports whether there is one. The adapter makes its changes itself, so
+// they can't be planned in memory; path is the file it changes, if known.
+func (a *ExternalAgent) planned(path, description string) bool {
+	p := plan.Active()
+	if p == nil {
+		return false
+	}
+	if path == "" {
+		path = a.path
+	}
+	p.Note(path, description)
+	return true
+}
+
+// saveConfig backs up the config the adapter is about to change. Only the
+// user config's path is known; adapters that write elsewhere aren't backed
+// up.
+func (a *ExternalAgent) saveConfig(scope MCPScope) error {
+	if path := a.knownConfigPath(scope); path != "" {
+		return backup.Save(path)
+	}
+	return nil
+}
+
+// knownConfigPath returns the config the adapter changes for scope, or ""
+// when the adapter didn't say
+func (a *ExternalAgent) knownConfigPath(scope MCPScope) string {
+	if scope != MCPScopeUser {
+		return ""
+	}
+	return a.desc.ConfigPath
+}
+
+// call runs the adapter for a single request and decodes the result
+func (a *ExternalAgent) call(method string, params *protocol.Params, result interface{}) error {
+	req := protocol.Request{JSONRPC: "2.0", ID: 1, Method: method}
+	if params != nil {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		req.Params = raw
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), adapterTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, a.path)
+	cmd.Stdin = bytes.NewReader(append(data, '\n'))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s %s: %w: %s", filepath.Base(a.path), method, err, msg)
+		}
+		return fmt.Errorf("%s %s: %w", filepath.Base(a.path), method, err)
+	}
+
+	line, err := bufio.NewReader(bytes.NewReader(out)).ReadBytes('\n')
+	if err != nil && len(line) == 0 {
+		return fmt.Errorf("%s %s: no response", filepath.Base(a.path), method)
+	}
+	var resp protocol.Response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return fmt.Errorf("%s %s: invalid response: %w", filepath.Base(a.path), method, err)
+	}
+	if resp.Error != nil {
+		if resp.Error.Code == protocol.ErrCodeUnsupported {
+			capability, _, _ := strings.Cut(method, "/")
+			unsupported := &UnsupportedError{Agent: a.Name(), Capability: Capability(capability)}
+			if params != nil && params.Scope != "" && params.Scope != MCPScopeUser {
+				unsupported.Scope = params.Scope
+			}
+			return unsupported
+		}
+		return fmt.Errorf("%s %s: %s", filepath.Base(a.path), method, resp.Error.Message)
+	}
+	if result != nil && len(resp.Result) > 0 {
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("%s %s: invalid result: %w", filepath.Base(a.path), method, err)
+		}
+	}
+	return nil
+}
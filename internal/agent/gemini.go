@@ -1,7 +1,6 @@
 package agent
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,178 +8,36 @@ import (
 	"github.com/agentsdance/agentx/internal/config"
 )
 
-// GeminiAgent represents Gemini CLI agent
+// GeminiAgent extends the declarative Gemini CLI agent with MCP servers
+// provided by installed extensions, which are listed but never modified.
+// Extensions are user-scoped.
 type GeminiAgent struct {
-	configPath string
+	*DeclarativeAgent
 }
 
-// NewGeminiAgent creates a new Gemini CLI agent
-func NewGeminiAgent() *GeminiAgent {
-	home, _ := os.UserHomeDir()
-	return &GeminiAgent{
-		configPath: filepath.Join(home, ".gemini", "settings.json"),
-	}
-}
-
-func (a *GeminiAgent) Name() string {
-	return "Gemini cli"
-}
-
-func (a *GeminiAgent) ConfigPath() string {
-	return a.configPath
-}
-
-func (a *GeminiAgent) Exists() bool {
-	_, err := os.Stat(a.configPath)
-	return err == nil
-}
-
-func (a *GeminiAgent) HasPlaywright() (bool, error) {
-	return a.HasMCP("playwright")
-}
-
-func (a *GeminiAgent) InstallPlaywright() error {
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			cfg = make(map[string]interface{})
-			// Ensure directory exists
-			if err := os.MkdirAll(filepath.Dir(a.configPath), 0755); err != nil {
-				return err
-			}
-		} else {
-			return err
-		}
-	}
-	config.AddPlaywrightMCP(cfg)
-	return config.WriteConfig(a.configPath, cfg)
-}
-
-func (a *GeminiAgent) RemovePlaywright() error {
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return err
-	}
-	config.RemovePlaywrightMCP(cfg)
-	return config.WriteConfig(a.configPath, cfg)
-}
-
-func (a *GeminiAgent) HasContext7() (bool, error) {
-	return a.HasMCP("context7")
-}
-
-func (a *GeminiAgent) InstallContext7() error {
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			cfg = make(map[string]interface{})
-			if err := os.MkdirAll(filepath.Dir(a.configPath), 0755); err != nil {
-				return err
-			}
-		} else {
-			return err
-		}
-	}
-	config.AddContext7MCP(cfg)
-	return config.WriteConfig(a.configPath, cfg)
-}
-
-func (a *GeminiAgent) RemoveContext7() error {
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return err
-	}
-	config.RemoveContext7MCP(cfg)
-	return config.WriteConfig(a.configPath, cfg)
+func newGeminiAgent(base *DeclarativeAgent) Agent {
+	return &GeminiAgent{DeclarativeAgent: base}
 }
 
-func (a *GeminiAgent) HasRemixIcon() (bool, error) {
-	return a.HasMCP("remix-icon")
-}
-
-func (a *GeminiAgent) InstallRemixIcon() error {
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			cfg = make(map[string]interface{})
-			if err := os.MkdirAll(filepath.Dir(a.configPath), 0755); err != nil {
-				return err
-			}
-		} else {
-			return err
-		}
-	}
-	config.AddRemixIconMCP(cfg)
-	return config.WriteConfig(a.configPath, cfg)
-}
-
-func (a *GeminiAgent) RemoveRemixIcon() error {
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return err
-	}
-	config.RemoveRemixIconMCP(cfg)
-	return config.WriteConfig(a.configPath, cfg)
-}
-
-func (a *GeminiAgent) HasMCP(name string) (bool, error) {
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			cfg = map[string]interface{}{}
-		} else {
-			return false, err
-		}
-	}
-	if config.HasMCP(cfg, name) {
-		return true, nil
+func (a *GeminiAgent) HasMCP(name string, scope MCPScope) (bool, error) {
+	ok, err := a.DeclarativeAgent.HasMCP(name, scope)
+	if err != nil || ok || scope != MCPScopeUser {
+		return ok, err
 	}
 	extensions, err := a.listExtensionMCPs()
 	if err != nil {
 		return false, err
 	}
-	_, ok := extensions[name]
+	_, ok = extensions[name]
 	return ok, nil
 }
 
-func (a *GeminiAgent) InstallMCP(name string, mcpConfig map[string]interface{}) error {
-	if mcpConfig == nil {
-		return fmt.Errorf("missing mcp config")
-	}
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			cfg = make(map[string]interface{})
-			if err := os.MkdirAll(filepath.Dir(a.configPath), 0755); err != nil {
-				return err
-			}
-		} else {
-			return err
-		}
-	}
-	config.AddMCP(cfg, name, cloneMCPConfig(mcpConfig))
-	return config.WriteConfig(a.configPath, cfg)
-}
-
-func (a *GeminiAgent) RemoveMCP(name string) error {
-	cfg, err := config.ReadConfig(a.configPath)
+func (a *GeminiAgent) RemoveMCP(name string, scope MCPScope) error {
+	ok, err := a.DeclarativeAgent.HasMCP(name, scope)
 	if err != nil {
-		if os.IsNotExist(err) {
-			cfg = map[string]interface{}{}
-		} else {
-			return err
-		}
+		return err
 	}
-	if !config.HasMCP(cfg, name) {
+	if !ok && scope == MCPScopeUser {
 		extensions, extErr := a.listExtensionMCPs()
 		if extErr == nil {
 			if _, ok := extensions[name]; ok {
@@ -189,20 +46,14 @@ func (a *GeminiAgent) RemoveMCP(name string) error {
 		}
 		return nil
 	}
-	config.RemoveMCP(cfg, name)
-	return config.WriteConfig(a.configPath, cfg)
+	return a.DeclarativeAgent.RemoveMCP(name, scope)
 }
 
-func (a *GeminiAgent) ListMCPs() (map[string]map[string]interface{}, error) {
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			cfg = map[string]interface{}{}
-		} else {
-			return nil, err
-		}
+func (a *GeminiAgent) ListMCPs(scope MCPScope) (map[string]MCPServerSpec, error) {
+	result, err := a.DeclarativeAgent.ListMCPs(scope)
+	if err != nil || scope != MCPScopeUser {
+		return result, err
 	}
-	result := config.GetMCPServers(cfg)
 	extensions, err := a.listExtensionMCPs()
 	if err != nil {
 		return result, nil
@@ -211,7 +62,9 @@ func (a *GeminiAgent) ListMCPs() (map[string]map[string]interface{}, error) {
 		if _, exists := result[name]; exists {
 			continue
 		}
-		result[name] = cfg
+		spec := a.translator.toSpec(cfg)
+		spec.Scope = MCPScopeUser
+		result[name] = spec
 	}
 	return result, nil
 }
@@ -254,14 +107,10 @@ func (a *GeminiAgent) listExtensionMCPs() (map[string]map[string]interface{}, er
 }
 
 func readGeminiExtensionEnablement(path string) (map[string]struct{}, error) {
-	data, err := os.ReadFile(path)
+	raw, err := config.ReadConfig(path)
 	if err != nil {
 		return nil, err
 	}
-	var raw map[string]interface{}
-	if err := json.Unmarshal(data, &raw); err != nil {
-		return nil, err
-	}
 	result := make(map[string]struct{}, len(raw))
 	for key := range raw {
 		result[key] = struct{}{}
@@ -270,50 +119,9 @@ func readGeminiExtensionEnablement(path string) (map[string]struct{}, error) {
 }
 
 func readGeminiExtensionConfig(path string) (map[string]map[string]interface{}, error) {
-	data, err := os.ReadFile(path)
+	cfg, err := config.ReadConfig(path)
 	if err != nil {
 		return nil, err
 	}
-	var payload struct {
-		MCPServers map[string]map[string]interface{} `json:"mcpServers"`
-	}
-	if err := json.Unmarshal(data, &payload); err != nil {
-		return nil, err
-	}
-	if payload.MCPServers == nil {
-		return map[string]map[string]interface{}{}, nil
-	}
-	return payload.MCPServers, nil
-}
-
-func (a *GeminiAgent) SupportsSkills() bool {
-	return false
-}
-
-func (a *GeminiAgent) HasSkill(skillName string) (bool, error) {
-	return false, nil
-}
-
-func (a *GeminiAgent) InstallSkill(skillName, source string) error {
-	return nil
-}
-
-func (a *GeminiAgent) RemoveSkill(skillName string) error {
-	return nil
-}
-
-func (a *GeminiAgent) SupportsPlugins() bool {
-	return false
-}
-
-func (a *GeminiAgent) HasPlugin(pluginName string) (bool, error) {
-	return false, nil
-}
-
-func (a *GeminiAgent) InstallPlugin(pluginName, source string) error {
-	return nil
-}
-
-func (a *GeminiAgent) RemovePlugin(pluginName string) error {
-	return nil
+	return config.GetMCPServers(cfg), nil
 }
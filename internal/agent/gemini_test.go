@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/agentsdance/agentx/internal/config"
+)
+
+func TestGeminiSettingsWithComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	fixture := `{
+  // set by /theme
+  "theme": "GitHub",
+  "mcpServers": {
+    "fetch": {"command": "uvx", "args": ["mcp-server-fetch"]}, // for docs
+  },
+}
+`
+	if err := os.WriteFile(path, []byte(fixture), 0644); err != nil {
+		t.Fatal(err)
+	}
+	a := builtinAgentAt(t, "Gemini cli", path).(MCPHost)
+
+	if ok, err := a.HasMCP("fetch", MCPScopeUser); err != nil || !ok {
+		t.Fatalf("HasMCP() = %v, %v", ok, err)
+	}
+	if err := a.InstallMCP("github", MCPServerSpec{Command: "npx", Args: []string{"-y", "@modelcontextprotocol/server-github"}}, MCPScopeUser); err != nil {
+		t.Fatalf("InstallMCP() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  // set by /theme
+  "theme": "GitHub",
+  "mcpServers": {
+    "fetch": {"command": "uvx", "args": ["mcp-server-fetch"]}, // for docs
+    "github": {
+      "args": [
+        "-y",
+        "@modelcontextprotocol/server-github"
+      ],
+      "command": "npx"
+    },
+  },
+}
+`
+	if string(data) != want {
+		t.Errorf("settings.json =\n%s\nwant\n%s", data, want)
+	}
+
+	// A file that is genuinely broken says where
+	if err := os.WriteFile(path, []byte("{\n  \"theme\": \"GitHub\"\n  \"mcpServers\": {}\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = a.HasMCP("fetch", MCPScopeUser)
+	var syntax *config.SyntaxError
+	if !errors.As(err, &syntax) || syntax.Path != path || syntax.Line != 3 || syntax.Column != 3 {
+		t.Errorf("HasMCP() error = %v, want a syntax error at %s:3:3", err, path)
+	}
+}
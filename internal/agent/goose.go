@@ -0,0 +1,94 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/agentsdance/agentx/internal/config"
+)
+
+// gooseDefaultTimeout is the timeout, in seconds, Goose gives extensions
+// it adds itself
+const gooseDefaultTimeout = 300
+
+// gooseMCPTypes are the extension types that are MCP servers; the others
+// (builtin, platform, frontend, inline_python) are part of Goose
+var gooseMCPTypes = map[string]bool{
+	"stdio":           true,
+	"sse":             true,
+	"streamable_http": true,
+}
+
+// GooseAgent extends the declarative Goose agent for config.yaml's
+// extensions, which hold MCP servers alongside Goose's own extensions. Only
+// the MCP ones are listed or changed, and reinstalling a server updates
+// the fields agentx knows about while keeping the rest (description,
+// env_keys, bundled, ...).
+type GooseAgent struct {
+	*DeclarativeAgent
+}
+
+func newGooseAgent(base *DeclarativeAgent) Agent {
+	return &GooseAgent{DeclarativeAgent: base}
+}
+
+func (a *GooseAgent) HasMCP(name string, scope MCPScope) (bool, error) {
+	servers, err := a.nativeMCPs(scope)
+	if err != nil {
+		return false, err
+	}
+	entry, ok := servers[name]
+	return ok && isGooseMCP(entry), nil
+}
+
+func (a *GooseAgent) InstallMCP(name string, spec MCPServerSpec, scope MCPScope) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	path, err := a.MCPConfigPath(scope)
+	if err != nil {
+		return err
+	}
+	return a.updateConfig(path, func(cfg map[string]interface{}) error {
+		servers := config.EnsureMap(cfg, a.mcpKey)
+		existing, _ := servers[name].(map[string]interface{})
+		if existing != nil && !isGooseMCP(existing) {
+			return fmt.Errorf("%s is a built-in Goose extension", name)
+		}
+
+		entry := a.translator.merge(existing, a.translator.fromSpec(spec), a.def.Fields.Timeout)
+		entry["name"] = name
+		if _, ok := entry[a.def.Fields.Timeout]; !ok {
+			entry[a.def.Fields.Timeout] = gooseDefaultTimeout
+		}
+		servers[name] = entry
+		return nil
+	})
+}
+
+func (a *GooseAgent) RemoveMCP(name string, scope MCPScope) error {
+	servers, err := a.nativeMCPs(scope)
+	if err != nil {
+		return err
+	}
+	if entry, ok := servers[name]; ok && !isGooseMCP(entry) {
+		return fmt.Errorf("%s is a built-in Goose extension", name)
+	}
+	return a.DeclarativeAgent.RemoveMCP(name, scope)
+}
+
+func (a *GooseAgent) ListMCPs(scope MCPScope) (map[string]MCPServerSpec, error) {
+	servers, err := a.nativeMCPs(scope)
+	if err != nil {
+		return nil, err
+	}
+	for name, entry := range servers {
+		if !isGooseMCP(entry) {
+			delete(servers, name)
+		}
+	}
+	return a.specsFromNative(servers, scope), nil
+}
+
+func isGooseMCP(entry map[string]interface{}) bool {
+	return gooseMCPTypes[stringValue(entry, "type")]
+}
@@ -0,0 +1,144 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentsdance/agentx/internal/config"
+)
+
+func gooseAgentAt(t *testing.T) (*GooseAgent, string) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "goose", "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	a, ok := builtinAgentAt(t, "Goose", path).(*GooseAgent)
+	if !ok {
+		t.Fatal("Goose definition did not use the goose adapter")
+	}
+	return a, path
+}
+
+func TestGooseListMCPs(t *testing.T) {
+	a, _ := gooseAgentAt(t)
+	specs, err := a.ListMCPs(MCPScopeUser)
+	if err != nil {
+		t.Fatalf("ListMCPs() error = %v", err)
+	}
+	want := map[string]MCPServerSpec{
+		"github": {
+			Command:   "npx",
+			Args:      []string{"-y", "@modelcontextprotocol/server-github"},
+			Env:       map[string]string{},
+			Transport: TransportStdio,
+			Timeout:   300 * time.Second,
+			Scope:     MCPScopeUser,
+		},
+		"docs": {
+			URL:       "https://docs.example.com/mcp",
+			Headers:   map[string]string{"Authorization": "Bearer token"},
+			Transport: TransportHTTP,
+			Timeout:   time.Minute,
+			Disabled:  true,
+			Scope:     MCPScopeUser,
+		},
+	}
+	if !reflect.DeepEqual(specs, want) {
+		t.Errorf("ListMCPs() = %#v\nwant %#v", specs, want)
+	}
+	if ok, _ := a.HasMCP("developer", MCPScopeUser); ok {
+		t.Error("HasMCP(developer) = true for a built-in extension")
+	}
+	if err := a.RemoveMCP("developer", MCPScopeUser); err == nil {
+		t.Error("RemoveMCP(developer) removed a built-in extension")
+	}
+}
+
+func TestGooseRoundTrip(t *testing.T) {
+	a, path := gooseAgentAt(t)
+	specs, err := a.ListMCPs(MCPScopeUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	github := specs["github"]
+	github.Env = map[string]string{"GITHUB_HOST": "github.example.com"}
+	if err := a.InstallMCP("github", github, MCPScopeUser); err != nil {
+		t.Fatalf("InstallMCP() error = %v", err)
+	}
+	if err := a.InstallMCP("fetch", MCPServerSpec{Command: "uvx", Args: []string{"mcp-server-fetch"}}, MCPScopeUser); err != nil {
+		t.Fatalf("InstallMCP() error = %v", err)
+	}
+
+	cfg, err := config.ReadYAMLConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotGitHub := config.GetMap(cfg, []string{"extensions", "github"})
+	wantGitHub := map[string]interface{}{
+		"args":        []interface{}{"-y", "@modelcontextprotocol/server-github"},
+		"bundled":     nil,
+		"cmd":         "npx",
+		"description": "GitHub repositories and issues",
+		"enabled":     true,
+		"env_keys":    []interface{}{"GITHUB_PERSONAL_ACCESS_TOKEN"},
+		"envs":        map[string]interface{}{"GITHUB_HOST": "github.example.com"},
+		"name":        "github",
+		"timeout":     300,
+		"type":        "stdio",
+	}
+	if !reflect.DeepEqual(gotGitHub, wantGitHub) {
+		t.Errorf("github extension = %#v\nwant %#v", gotGitHub, wantGitHub)
+	}
+	gotFetch := config.GetMap(cfg, []string{"extensions", "fetch"})
+	wantFetch := map[string]interface{}{
+		"args":    []interface{}{"mcp-server-fetch"},
+		"cmd":     "uvx",
+		"enabled": true,
+		"name":    "fetch",
+		"timeout": 300,
+		"type":    "stdio",
+	}
+	if !reflect.DeepEqual(gotFetch, wantFetch) {
+		t.Errorf("fetch extension = %#v\nwant %#v", gotFetch, wantFetch)
+	}
+	if got := config.GetMap(cfg, []string{"extensions", "developer"}); got["type"] != "builtin" {
+		t.Errorf("developer extension = %v", got)
+	}
+
+	// Untouched parts of the file keep their order and comments
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	for _, keep := range []string{"# Goose config", "GOOSE_MODE: smart_approve # approve risky tool calls", "'@modelcontextprotocol/server-github'"} {
+		if !strings.Contains(text, keep) {
+			t.Errorf("config lost %q:\n%s", keep, text)
+		}
+	}
+	order := []string{"GOOSE_PROVIDER:", "extensions:", "  developer:", "  github:", "  docs:", "  fetch:", "GOOSE_MODE:"}
+	last := -1
+	for _, key := range order {
+		i := strings.Index(text, key)
+		if i < last {
+			t.Errorf("%q moved:\n%s", key, text)
+		}
+		last = i
+	}
+
+	if err := a.RemoveMCP("fetch", MCPScopeUser); err != nil {
+		t.Fatalf("RemoveMCP() error = %v", err)
+	}
+	if ok, _ := a.HasMCP("fetch", MCPScopeUser); ok {
+		t.Error("fetch still installed after RemoveMCP")
+	}
+}
@@ -0,0 +1,164 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/agentsdance/agentx/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Instance resolves inst, a definition extending d, into a complete
+// definition. The instance's aliases default to each of d's aliases with
+// the instance's @profile suffix, so claude@work also answers to
+// claude-code@work.
+func (d Definition) Instance(inst Definition) (Definition, error) {
+	if inst.Dir != "" && d.HomeEnv == "" {
+		return Definition{}, fmt.Errorf("%s: %s has no config directory variable; use env instead of dir", inst.Name, d.Name)
+	}
+	resolved := d
+	resolved.Name = inst.Name
+	resolved.Extends = inst.Extends
+	resolved.Dir = inst.Dir
+	resolved.source = inst.source
+
+	resolved.Aliases = append([]string(nil), inst.Aliases...)
+	if i := strings.LastIndex(inst.Name, "@"); i >= 0 && len(inst.Aliases) == 0 {
+		profile := inst.Name[i:]
+		for _, alias := range d.Aliases {
+			if !strings.EqualFold(alias+profile, inst.Name) {
+				resolved.Aliases = append(resolved.Aliases, alias+profile)
+			}
+		}
+	}
+
+	resolved.Env = make(map[string]string, len(d.Env)+len(inst.Env)+1)
+	for k, v := range d.Env {
+		resolved.Env[k] = v
+	}
+	for k, v := range inst.Env {
+		resolved.Env[k] = v
+	}
+	if inst.Dir != "" {
+		resolved.Env[d.HomeEnv] = inst.Dir
+	}
+	return resolved, nil
+}
+
+// matchesName reports whether name is the definition's name or an alias
+func (d Definition) matchesName(name string) bool {
+	if strings.EqualFold(d.Name, name) {
+		return true
+	}
+	for _, alias := range d.Aliases {
+		if strings.EqualFold(alias, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveInstances replaces each instance in defs with its resolved
+// definition. Instances of unknown agents are dropped and reported.
+func resolveInstances(defs []Definition) ([]Definition, error) {
+	resolved := make([]Definition, 0, len(defs))
+	var errs []error
+	for _, def := range defs {
+		if def.Extends == "" {
+			resolved = append(resolved, def)
+			continue
+		}
+		var base *Definition
+		for i := range defs {
+			if defs[i].Extends == "" && defs[i].matchesName(def.Extends) {
+				base = &defs[i]
+				break
+			}
+		}
+		if base == nil {
+			errs = append(errs, fmt.Errorf("%s: unknown agent %q", def.Name, def.Extends))
+			continue
+		}
+		inst, err := base.Instance(def)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		resolved = append(resolved, inst)
+	}
+	return resolved, errors.Join(errs...)
+}
+
+// instanceFile is the on-disk form of a named instance
+type instanceFile struct {
+	Name    string            `yaml:"name"`
+	Extends string            `yaml:"extends"`
+	Dir     string            `yaml:"dir,omitempty"`
+	Env     map[string]string `yaml:"env,omitempty"`
+}
+
+// AddInstance registers a named instance of an existing agent by writing
+// it to the user definitions directory, and returns the file written.
+// Extends defaults to the part of the name before "@".
+func AddInstance(inst Definition) (string, error) {
+	if inst.Extends == "" {
+		if i := strings.LastIndex(inst.Name, "@"); i > 0 {
+			inst.Extends = inst.Name[:i]
+		}
+	}
+	if inst.Name == "" || inst.Extends == "" {
+		return "", fmt.Errorf("instance name must look like <agent>@<profile>")
+	}
+	if strings.ContainsAny(inst.Name, `/\`) {
+		return "", fmt.Errorf("%s: instance names can't contain path separators", inst.Name)
+	}
+
+	defs, _ := LoadDefinitions() // unrelated broken definitions don't matter here
+	var base *Definition
+	for i := range defs {
+		if defs[i].matchesName(inst.Name) {
+			return "", fmt.Errorf("agent %s already exists", inst.Name)
+		}
+		if base == nil && defs[i].Extends == "" && defs[i].matchesName(inst.Extends) {
+			base = &defs[i]
+		}
+	}
+	if base == nil {
+		return "", fmt.Errorf("%s: unknown agent %q", inst.Name, inst.Extends)
+	}
+	if _, err := base.Instance(inst); err != nil {
+		return "", err
+	}
+
+	dir, err := UserDefinitionsDir()
+	if err != nil {
+		return "", err
+	}
+	data, err := yaml.Marshal(instanceFile{Name: inst.Name, Extends: inst.Extends, Dir: inst.Dir, Env: inst.Env})
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, inst.Name+".yaml")
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("%s already exists", path)
+	}
+	return path, config.WriteFile(path, data)
+}
+
+// RemoveInstance deletes the user definition of a named instance
+func RemoveInstance(name string) error {
+	dir, err := UserDefinitionsDir()
+	if err != nil {
+		return err
+	}
+	defs, _ := LoadUserDefinitions(dir)
+	for _, def := range defs {
+		if def.Extends != "" && strings.EqualFold(def.Name, name) {
+			return config.RemoveFile(def.Source())
+		}
+	}
+	return fmt.Errorf("no named instance %s in %s", name, dir)
+}
@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefinitionInstance(t *testing.T) {
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+	base := builtinDefinition(t, "Claude Code")
+
+	def, err := base.Instance(Definition{Name: "claude@work", Extends: "claude", Dir: "/profiles/work"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewAgent(def)
+	if _, ok := a.(*ClaudeAgent); !ok {
+		t.Errorf("instance did not keep the claude adapter")
+	}
+	if got, want := a.ConfigPath(), filepath.FromSlash("/profiles/work/.claude.json"); got != want {
+		t.Errorf("ConfigPath() = %q, want %q", got, want)
+	}
+	if !matchAgentName(a, "claude-code@work") || matchAgentName(a, "claude") {
+		t.Errorf("instance aliases = %v", def.Aliases)
+	}
+	if base.Env != nil {
+		t.Errorf("resolving an instance modified the base definition")
+	}
+
+	if _, err := builtinDefinition(t, "Cursor").Instance(Definition{Name: "cursor@x", Extends: "cursor", Dir: "/x"}); err == nil {
+		t.Error("Instance() accepted dir for an agent without home_env")
+	}
+}
+
+func TestNamedInstances(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+	workDir := filepath.Join(home, ".claude-work")
+
+	path, err := AddInstance(Definition{Name: "claude@work", Dir: workDir})
+	if err != nil {
+		t.Fatalf("AddInstance() error = %v", err)
+	}
+	if _, err := AddInstance(Definition{Name: "claude@work", Dir: workDir}); err == nil {
+		t.Error("AddInstance() accepted a duplicate")
+	}
+	if _, err := AddInstance(Definition{Name: "nope@work"}); err == nil {
+		t.Error("AddInstance() accepted an unknown agent")
+	}
+
+	a := GetAgentByName("claude@work")
+	if a == nil {
+		t.Fatal("GetAgentByName(claude@work) = nil")
+	}
+	if got := a.ConfigPath(); got != filepath.Join(workDir, ".claude.json") {
+		t.Errorf("ConfigPath() = %q", got)
+	}
+	if n := len(GetAllAgents()); n != len(BuiltinDefinitions())+1 {
+		t.Errorf("got %d agents, want the instance alongside the built-ins", n)
+	}
+
+	host := a.(MCPHost)
+	if err := host.InstallMCP("context7", MCPServerSpec{Command: "npx"}, MCPScopeUser); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".claude.json")); !os.IsNotExist(err) {
+		t.Errorf("instance wrote to the default profile")
+	}
+
+	if err := RemoveInstance("claude@work"); err != nil {
+		t.Fatalf("RemoveInstance() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("instance definition still exists")
+	}
+	if GetAgentByName("claude@work") != nil {
+		t.Errorf("instance still listed after removal")
+	}
+}
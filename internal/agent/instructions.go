@@ -0,0 +1,32 @@
+package agent
+
+import (
+	"os"
+
+	"github.com/agentsdance/agentx/internal/config"
+)
+
+// instructionsFile implements the InstructionsHost methods for agents that
+// read a single global markdown file
+type instructionsFile struct {
+	path string
+}
+
+func (f instructionsFile) InstructionsPath() string {
+	return f.path
+}
+
+func (f instructionsFile) ReadInstructions() (string, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (f instructionsFile) WriteInstructions(content string) error {
+	return config.WriteFile(f.path, []byte(content))
+}
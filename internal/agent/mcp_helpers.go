@@ -4,80 +4,63 @@ import "fmt"
 
 // MCPConfigEntry represents a discovered MCP configuration.
 type MCPConfigEntry struct {
-	Config map[string]interface{}
+	Spec   MCPServerSpec
 	Source string
 }
 
-// CollectMCPConfigs collects MCP configs from all agents, keyed by server name.
-func CollectMCPConfigs(agents []Agent) map[string]MCPConfigEntry {
+// CollectMCPConfigs collects MCP configs from all agents and every scope
+// they support, keyed by server name. The specs are what another agent
+// needs to run the server: state that belongs to the agent a server was
+// found in, such as whether it is switched off there or which of its tools
+// that agent's user trusts, is left out. Servers whose values are VS Code
+// ${input:id} references are skipped, since only VS Code can resolve them.
+func CollectMCPConfigs(agents []MCPHost) map[string]MCPConfigEntry {
 	configs := make(map[string]MCPConfigEntry)
 	for _, a := range agents {
-		entries, err := a.ListMCPs()
-		if err != nil {
-			continue
-		}
-		for name, cfg := range entries {
-			if _, exists := configs[name]; exists {
+		for _, scope := range a.MCPScopes() {
+			entries, err := a.ListMCPs(scope)
+			if err != nil {
 				continue
 			}
-			if cfg == nil {
-				continue
+			source := a.Name()
+			if scope != MCPScopeUser {
+				source = fmt.Sprintf("%s (%s)", a.Name(), scope)
 			}
-			configs[name] = MCPConfigEntry{
-				Config: cloneMCPConfig(cfg),
-				Source: a.Name(),
+			for name, spec := range entries {
+				if _, exists := configs[name]; exists {
+					continue
+				}
+				if spec.Validate() != nil {
+					continue // e.g. extension servers started by their agent
+				}
+				if referencesVSCodeInputs(spec) {
+					continue // the secrets stay in VS Code; another agent may have them
+				}
+				configs[name] = MCPConfigEntry{
+					Spec:   portableSpec(spec),
+					Source: source,
+				}
 			}
 		}
 	}
 	return configs
 }
 
-func cloneMCPConfig(cfg map[string]interface{}) map[string]interface{} {
-	if cfg == nil {
-		return nil
-	}
-	cloned := make(map[string]interface{}, len(cfg))
-	for key, value := range cfg {
-		switch v := value.(type) {
-		case map[string]interface{}:
-			cloned[key] = cloneMCPConfig(v)
-		case []interface{}:
-			items := make([]interface{}, len(v))
-			copy(items, v)
-			cloned[key] = items
-		case []string:
-			items := make([]string, len(v))
-			copy(items, v)
-			cloned[key] = items
-		default:
-			cloned[key] = value
-		}
-	}
-	return cloned
+// portableSpec returns a copy of spec, listed from one agent, without the
+// fields that describe that agent's setup rather than the server
+func portableSpec(spec MCPServerSpec) MCPServerSpec {
+	portable := spec.Clone()
+	portable.Disabled = false
+	portable.AutoApprove = nil
+	portable.Scope = ""
+	return portable
 }
 
-func normalizeArgsToStrings(cfg map[string]interface{}) map[string]interface{} {
-	cloned := cloneMCPConfig(cfg)
-	if cloned == nil {
-		return nil
-	}
-	args, ok := cloned["args"]
-	if !ok {
-		return cloned
-	}
-	switch v := args.(type) {
-	case []string:
-		return cloned
-	case []interface{}:
-		values := make([]string, 0, len(v))
-		for _, item := range v {
-			if s, ok := item.(string); ok {
-				values = append(values, s)
-			} else {
-				values = append(values, fmt.Sprint(item))
-			}
-		}
-		cloned["args"] = values
+// ResolveMCPSpec returns the spec to install for name, preferring configs
+// discovered in agents over the built-in defaults.
+func ResolveMCPSpec(name string, discovered map[string]MCPConfigEntry) (MCPServerSpec, bool) {
+	if entry, ok := discovered[name]; ok {
+		return entry.Spec.Clone(), true
 	}
-	return cloned
+	return BuiltinMCPServer(name)
 }
@@ -0,0 +1,106 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/agentsdance/agentx/internal/config"
+)
+
+func TestCopiedServerIsEnabled(t *testing.T) {
+	dir := t.TempDir()
+	clinePath := filepath.Join(dir, "cline_mcp_settings.json")
+	fixture := `{"mcpServers": {"github": {"command": "npx", "args": ["-y", "@modelcontextprotocol/server-github"], "disabled": true}}}`
+	if err := os.WriteFile(clinePath, []byte(fixture), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cline := builtinAgentAt(t, "Cline", clinePath).(MCPHost)
+	windsurfPath := filepath.Join(dir, "mcp_config.json")
+	windsurf := builtinAgentAt(t, "Windsurf", windsurfPath).(MCPHost)
+
+	spec, ok := ResolveMCPSpec("github", CollectMCPConfigs([]MCPHost{cline, windsurf}))
+	if !ok {
+		t.Fatal("github wasn't discovered in Cline")
+	}
+	if spec.Disabled || spec.Scope != "" {
+		t.Errorf("discovered spec = %#v, want it without Cline's state", spec)
+	}
+	if err := windsurf.InstallMCP("github", spec, MCPScopeUser); err != nil {
+		t.Fatalf("InstallMCP() error = %v", err)
+	}
+	cfg, err := config.ReadConfig(windsurfPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	github := config.GetMap(cfg, []string{"mcpServers", "github"})
+	if _, ok := github["disabled"]; ok {
+		t.Errorf("Windsurf got %v, want github installed enabled", github)
+	}
+	if github["command"] != "npx" {
+		t.Errorf("Windsurf got %v, want the server Cline has", github)
+	}
+}
+
+func TestCopiedServerDropsAutoApprove(t *testing.T) {
+	dir := t.TempDir()
+	clinePath := filepath.Join(dir, "cline_mcp_settings.json")
+	fixture := `{"mcpServers": {"github": {"command": "npx", "autoApprove": ["create_issue", "merge_pull_request"]}}}`
+	if err := os.WriteFile(clinePath, []byte(fixture), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cline := builtinAgentAt(t, "Cline", clinePath).(MCPHost)
+	rooPath := filepath.Join(dir, "mcp_settings.json")
+	roo := builtinAgentAt(t, "Roo Code", rooPath).(MCPHost)
+
+	spec, ok := ResolveMCPSpec("github", CollectMCPConfigs([]MCPHost{cline, roo}))
+	if !ok {
+		t.Fatal("github wasn't discovered in Cline")
+	}
+	if err := roo.InstallMCP("github", spec, MCPScopeUser); err != nil {
+		t.Fatalf("InstallMCP() error = %v", err)
+	}
+	cfg, err := config.ReadConfig(rooPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if github := config.GetMap(cfg, []string{"mcpServers", "github"}); github["alwaysAllow"] != nil {
+		t.Errorf("Roo Code got %v, want Cline's auto-approved tools left behind", github)
+	}
+
+	// Reinstalling in Cline itself keeps the list the user made there
+	if err := cline.InstallMCP("github", spec, MCPScopeUser); err != nil {
+		t.Fatalf("InstallMCP() error = %v", err)
+	}
+	specs, err := cline.ListMCPs(MCPScopeUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := specs["github"].AutoApprove; len(got) != 2 {
+		t.Errorf("Cline AutoApprove after reinstall = %v, want both tools kept", got)
+	}
+}
+
+func TestCopySkipsVSCodeInputs(t *testing.T) {
+	dir := t.TempDir()
+	vscodePath := filepath.Join(dir, "mcp.json")
+	fixture := `{"servers": {"sentry": {"command": "npx", "env": {"SENTRY_AUTH_TOKEN": "${input:sentry-token}"}}}}`
+	if err := os.WriteFile(vscodePath, []byte(fixture), 0644); err != nil {
+		t.Fatal(err)
+	}
+	vscode := builtinAgentAt(t, "VS Code", vscodePath).(MCPHost)
+	clinePath := filepath.Join(dir, "cline_mcp_settings.json")
+	fixture = `{"mcpServers": {"sentry": {"command": "npx", "env": {"SENTRY_AUTH_TOKEN": "sntrys_secret"}}}}`
+	if err := os.WriteFile(clinePath, []byte(fixture), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cline := builtinAgentAt(t, "Cline", clinePath).(MCPHost)
+
+	if _, ok := CollectMCPConfigs([]MCPHost{vscode})["sentry"]; ok {
+		t.Error("a server with ${input:} values was collected for copying")
+	}
+	entry, ok := CollectMCPConfigs([]MCPHost{vscode, cline})["sentry"]
+	if !ok || entry.Spec.Env["SENTRY_AUTH_TOKEN"] != "sntrys_secret" {
+		t.Errorf("collected %#v, want Cline's sentry with its token", entry)
+	}
+}
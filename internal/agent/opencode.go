@@ -1,238 +1,202 @@
 package agent
 
 import (
-	"fmt"
 	"os"
-	"path/filepath"
+	"sort"
 
 	"github.com/agentsdance/agentx/internal/config"
 )
 
-// OpenCodeAgent represents OpenCode agent
+// legacyOpenCodeConfig is where opencode kept its config before it moved
+// to opencode.json
+const legacyOpenCodeConfig = "~/.opencode/config.json"
+
+// legacyOpenCodeFields maps the Claude-style entries of the legacy config
+var legacyOpenCodeFields = FieldMapping{
+	Command:   "command",
+	Args:      "args",
+	Env:       "env",
+	URL:       "url",
+	Headers:   "headers",
+	Transport: "type",
+}
+
+// legacyOpenCodeKey is the server container of the legacy config
+var legacyOpenCodeKey = []string{"mcpServers"}
+
+// OpenCodeLayout describes which opencode config files are present
+type OpenCodeLayout string
+
+const (
+	OpenCodeLayoutNone    OpenCodeLayout = "none"
+	OpenCodeLayoutCurrent OpenCodeLayout = "current"
+	OpenCodeLayoutLegacy  OpenCodeLayout = "legacy"
+	OpenCodeLayoutBoth    OpenCodeLayout = "both"
+)
+
+// OpenCodeAgent extends the declarative opencode agent, which manages the
+// current opencode.json schema, with the legacy ~/.opencode/config.json.
+// Legacy servers are listed and can be removed or migrated; new servers
+// are always written in the current schema.
 type OpenCodeAgent struct {
-	configPath string
+	*DeclarativeAgent
+	legacyPath string
+	legacy     mcpTranslator
 }
 
-// NewOpenCodeAgent creates a new OpenCode agent
-func NewOpenCodeAgent() *OpenCodeAgent {
-	home, _ := os.UserHomeDir()
+func newOpenCodeAgent(base *DeclarativeAgent) Agent {
 	return &OpenCodeAgent{
-		configPath: filepath.Join(home, ".opencode", "config.json"),
+		DeclarativeAgent: base,
+		legacyPath:       expandPathWith(legacyOpenCodeConfig, base.def.Env),
+		legacy:           newMCPTranslator(legacyOpenCodeFields),
 	}
 }
 
-func (a *OpenCodeAgent) Name() string {
-	return "opencode"
+// LegacyConfigPath returns the path of the legacy config
+func (a *OpenCodeAgent) LegacyConfigPath() string {
+	return a.legacyPath
 }
 
-func (a *OpenCodeAgent) ConfigPath() string {
-	return a.configPath
+// Layout reports which config layouts are present
+func (a *OpenCodeAgent) Layout() OpenCodeLayout {
+	current := fileExists(a.configPath)
+	legacy := fileExists(a.legacyPath)
+	switch {
+	case current && legacy:
+		return OpenCodeLayoutBoth
+	case current:
+		return OpenCodeLayoutCurrent
+	case legacy:
+		return OpenCodeLayoutLegacy
+	}
+	return OpenCodeLayoutNone
 }
 
 func (a *OpenCodeAgent) Exists() bool {
-	_, err := os.Stat(a.configPath)
-	return err == nil
+	return a.DeclarativeAgent.Exists() || fileExists(a.legacyPath)
 }
 
-func (a *OpenCodeAgent) HasPlaywright() (bool, error) {
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return false, nil
-		}
-		return false, err
+// Detect also counts the legacy config as a config
+func (a *OpenCodeAgent) Detect() DetectionResult {
+	r := a.DeclarativeAgent.Detect()
+	if !r.ConfigFound && fileExists(a.legacyPath) {
+		r.ConfigFound = true
+		r.setStatus()
 	}
-	return config.HasPlaywrightMCP(cfg), nil
+	return r
 }
 
-func (a *OpenCodeAgent) InstallPlaywright() error {
-	cfg, err := config.ReadConfig(a.configPath)
+func (a *OpenCodeAgent) HasMCP(name string, scope MCPScope) (bool, error) {
+	ok, err := a.DeclarativeAgent.HasMCP(name, scope)
+	if err != nil || ok || scope != MCPScopeUser {
+		return ok, err
+	}
+	legacy, err := a.nativeMCPsAt(a.legacyPath, legacyOpenCodeKey)
 	if err != nil {
-		if os.IsNotExist(err) {
-			cfg = make(map[string]interface{})
-			// Ensure directory exists
-			if err := os.MkdirAll(filepath.Dir(a.configPath), 0755); err != nil {
-				return err
-			}
-		} else {
-			return err
-		}
+		return false, err
 	}
-	config.AddPlaywrightMCP(cfg)
-	return config.WriteConfig(a.configPath, cfg)
+	_, ok = legacy[name]
+	return ok, nil
 }
 
-func (a *OpenCodeAgent) RemovePlaywright() error {
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
+func (a *OpenCodeAgent) RemoveMCP(name string, scope MCPScope) error {
+	if err := a.DeclarativeAgent.RemoveMCP(name, scope); err != nil {
 		return err
 	}
-	config.RemovePlaywrightMCP(cfg)
-	return config.WriteConfig(a.configPath, cfg)
-}
-
-func (a *OpenCodeAgent) HasContext7() (bool, error) {
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return false, nil
-		}
-		return false, err
+	if scope != MCPScopeUser {
+		return nil
 	}
-	return config.HasContext7MCP(cfg), nil
-}
-
-func (a *OpenCodeAgent) InstallContext7() error {
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			cfg = make(map[string]interface{})
-			if err := os.MkdirAll(filepath.Dir(a.configPath), 0755); err != nil {
-				return err
-			}
-		} else {
-			return err
+	return a.updateConfig(a.legacyPath, func(cfg map[string]interface{}) error {
+		servers := config.GetMap(cfg, legacyOpenCodeKey)
+		if _, ok := servers[name]; !ok {
+			return config.ErrUnchanged
 		}
-	}
-	config.AddContext7MCP(cfg)
-	return config.WriteConfig(a.configPath, cfg)
+		delete(servers, name)
+		return nil
+	})
 }
 
-func (a *OpenCodeAgent) RemoveContext7() error {
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return err
+func (a *OpenCodeAgent) ListMCPs(scope MCPScope) (map[string]MCPServerSpec, error) {
+	result, err := a.DeclarativeAgent.ListMCPs(scope)
+	if err != nil || scope != MCPScopeUser {
+		return result, err
 	}
-	config.RemoveContext7MCP(cfg)
-	return config.WriteConfig(a.configPath, cfg)
-}
-
-func (a *OpenCodeAgent) HasRemixIcon() (bool, error) {
-	cfg, err := config.ReadConfig(a.configPath)
+	legacy, err := a.nativeMCPsAt(a.legacyPath, legacyOpenCodeKey)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return false, nil
-		}
-		return false, err
+		return nil, err
 	}
-	return config.HasRemixIconMCP(cfg), nil
-}
-
-func (a *OpenCodeAgent) InstallRemixIcon() error {
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			cfg = make(map[string]interface{})
-			if err := os.MkdirAll(filepath.Dir(a.configPath), 0755); err != nil {
-				return err
-			}
-		} else {
-			return err
+	for name, native := range legacy {
+		if _, exists := result[name]; exists {
+			continue
 		}
+		spec := a.legacy.toSpec(native)
+		spec.Scope = MCPScopeUser
+		result[name] = spec
 	}
-	config.AddRemixIconMCP(cfg)
-	return config.WriteConfig(a.configPath, cfg)
+	return result, nil
 }
 
-func (a *OpenCodeAgent) RemoveRemixIcon() error {
-	cfg, err := config.ReadConfig(a.configPath)
+// LegacyMCPs returns the servers still in the legacy config
+func (a *OpenCodeAgent) LegacyMCPs() (map[string]MCPServerSpec, error) {
+	legacy, err := a.nativeMCPsAt(a.legacyPath, legacyOpenCodeKey)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return err
+		return nil, err
 	}
-	config.RemoveRemixIconMCP(cfg)
-	return config.WriteConfig(a.configPath, cfg)
+	return a.legacy.specsFromNative(legacy), nil
 }
 
-func (a *OpenCodeAgent) HasMCP(name string) (bool, error) {
-	cfg, err := config.ReadConfig(a.configPath)
+// MigrateLegacy moves servers from the legacy config into opencode.json
+// and returns their names. Servers whose name is already taken in
+// opencode.json are left in the legacy config.
+func (a *OpenCodeAgent) MigrateLegacy() ([]string, error) {
+	legacyCfg, err := a.readConfig(a.legacyPath)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return false, nil
-		}
-		return false, err
+		return nil, err
 	}
-	return config.HasMCP(cfg, name), nil
-}
-
-func (a *OpenCodeAgent) InstallMCP(name string, mcpConfig map[string]interface{}) error {
-	if mcpConfig == nil {
-		return fmt.Errorf("missing mcp config")
+	legacyServers := config.GetMap(legacyCfg, legacyOpenCodeKey)
+	if len(legacyServers) == 0 {
+		return nil, nil
 	}
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			cfg = make(map[string]interface{})
-			if err := os.MkdirAll(filepath.Dir(a.configPath), 0755); err != nil {
-				return err
+
+	var migrated []string
+	err = a.updateConfig(a.configPath, func(cfg map[string]interface{}) error {
+		servers := config.EnsureMap(cfg, a.mcpKey)
+		migrated = nil
+		for name, raw := range legacyServers {
+			native, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if _, exists := servers[name]; exists {
+				continue
 			}
-		} else {
-			return err
+			servers[name] = a.translator.fromSpec(a.legacy.toSpec(native))
+			migrated = append(migrated, name)
 		}
-	}
-	config.AddMCP(cfg, name, cloneMCPConfig(mcpConfig))
-	return config.WriteConfig(a.configPath, cfg)
-}
-
-func (a *OpenCodeAgent) RemoveMCP(name string) error {
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
+		if len(migrated) == 0 {
+			return config.ErrUnchanged
 		}
-		return err
+		return nil
+	})
+	if err != nil || len(migrated) == 0 {
+		return nil, err
 	}
-	config.RemoveMCP(cfg, name)
-	return config.WriteConfig(a.configPath, cfg)
-}
+	sort.Strings(migrated)
 
-func (a *OpenCodeAgent) ListMCPs() (map[string]map[string]interface{}, error) {
-	cfg, err := config.ReadConfig(a.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return map[string]map[string]interface{}{}, nil
+	err = a.updateConfig(a.legacyPath, func(cfg map[string]interface{}) error {
+		servers := config.GetMap(cfg, legacyOpenCodeKey)
+		for _, name := range migrated {
+			delete(servers, name)
 		}
-		return nil, err
+		return nil
+	})
+	if err != nil {
+		return migrated, err
 	}
-	return config.GetMCPServers(cfg), nil
-}
-
-func (a *OpenCodeAgent) SupportsSkills() bool {
-	return false
-}
-
-func (a *OpenCodeAgent) HasSkill(skillName string) (bool, error) {
-	return false, nil
-}
-
-func (a *OpenCodeAgent) InstallSkill(skillName, source string) error {
-	return nil
+	return migrated, nil
 }
 
-func (a *OpenCodeAgent) RemoveSkill(skillName string) error {
-	return nil
-}
-
-func (a *OpenCodeAgent) SupportsPlugins() bool {
-	return false
-}
-
-func (a *OpenCodeAgent) HasPlugin(pluginName string) (bool, error) {
-	return false, nil
-}
-
-func (a *OpenCodeAgent) InstallPlugin(pluginName, source string) error {
-	return nil
-}
-
-func (a *OpenCodeAgent) RemovePlugin(pluginName string) error {
-	return nil
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
 }
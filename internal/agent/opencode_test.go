@@ -0,0 +1,194 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/agentsdance/agentx/internal/config"
+)
+
+// openCodeFixture sets up a home directory with the given opencode
+// fixtures ("" skips a layout) and returns the agent
+func openCodeFixture(t *testing.T, legacy, current string) *OpenCodeAgent {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	copyFixture := func(fixture, dst string) {
+		data, err := os.ReadFile(filepath.Join("testdata", "opencode", fixture))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(dst, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if legacy != "" {
+		copyFixture(legacy, filepath.Join(home, ".opencode", "config.json"))
+	}
+	if current != "" {
+		copyFixture(current, filepath.Join(home, ".config", "opencode", "opencode.json"))
+	}
+
+	a, ok := NewAgent(builtinDefinition(t, "opencode")).(*OpenCodeAgent)
+	if !ok {
+		t.Fatal("opencode definition did not use the opencode adapter")
+	}
+	return a
+}
+
+func TestOpenCodeLayout(t *testing.T) {
+	tests := []struct {
+		legacy, current string
+		want            OpenCodeLayout
+	}{
+		{"", "", OpenCodeLayoutNone},
+		{"legacy.json", "", OpenCodeLayoutLegacy},
+		{"", "opencode.json", OpenCodeLayoutCurrent},
+		{"legacy.json", "opencode.json", OpenCodeLayoutBoth},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.want), func(t *testing.T) {
+			a := openCodeFixture(t, tt.legacy, tt.current)
+			if got := a.Layout(); got != tt.want {
+				t.Errorf("Layout() = %q, want %q", got, tt.want)
+			}
+			if exists := a.Exists(); exists != (tt.want != OpenCodeLayoutNone) {
+				t.Errorf("Exists() = %v", exists)
+			}
+		})
+	}
+}
+
+func TestOpenCodeCurrentSchema(t *testing.T) {
+	a := openCodeFixture(t, "", "opencode.json")
+
+	specs, err := a.ListMCPs(MCPScopeUser)
+	if err != nil {
+		t.Fatalf("ListMCPs() error = %v", err)
+	}
+	want := map[string]MCPServerSpec{
+		"context7": {
+			URL:       "https://mcp.context7.com/mcp",
+			Headers:   map[string]string{"CONTEXT7_API_KEY": "secret"},
+			Transport: TransportHTTP,
+			Scope:     MCPScopeUser,
+		},
+		"sentry": {
+			Command:   "npx",
+			Args:      []string{"-y", "@sentry/mcp-server"},
+			Env:       map[string]string{"SENTRY_TOKEN": "token"},
+			Transport: TransportStdio,
+			Timeout:   10 * time.Second,
+			Disabled:  true,
+			Scope:     MCPScopeUser,
+		},
+	}
+	if !reflect.DeepEqual(specs, want) {
+		t.Errorf("ListMCPs() = %#v\nwant %#v", specs, want)
+	}
+
+	spec, _ := BuiltinMCPServer("playwright")
+	if err := a.InstallMCP("playwright", spec, MCPScopeUser); err != nil {
+		t.Fatalf("InstallMCP() error = %v", err)
+	}
+	cfg, err := config.ReadConfig(a.ConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := config.GetMap(cfg, []string{"mcp", "playwright"})
+	wantEntry := map[string]interface{}{
+		"type":    "local",
+		"command": []interface{}{"npx", "@playwright/mcp@latest"},
+		"enabled": true,
+	}
+	if !reflect.DeepEqual(got, wantEntry) {
+		t.Errorf("installed entry = %#v, want %#v", got, wantEntry)
+	}
+	if cfg["theme"] != "tokyonight" || cfg["$schema"] == nil {
+		t.Errorf("unrelated settings were not preserved: %v", cfg)
+	}
+	if _, err := os.Stat(a.LegacyConfigPath()); !os.IsNotExist(err) {
+		t.Errorf("legacy config was created")
+	}
+}
+
+func TestOpenCodeLegacyLayout(t *testing.T) {
+	a := openCodeFixture(t, "legacy.json", "opencode.json")
+
+	specs, err := a.ListMCPs(MCPScopeUser)
+	if err != nil {
+		t.Fatalf("ListMCPs() error = %v", err)
+	}
+	if len(specs) != 4 {
+		t.Fatalf("ListMCPs() = %v, want current and legacy servers", specs)
+	}
+	if specs["context7"].URL == "" {
+		t.Errorf("current entry should win over legacy context7: %+v", specs["context7"])
+	}
+	if has, err := a.HasMCP("playwright", MCPScopeUser); err != nil || !has {
+		t.Errorf("HasMCP(legacy playwright) = %v, %v", has, err)
+	}
+
+	if err := a.RemoveMCP("docs", MCPScopeUser); err != nil {
+		t.Fatalf("RemoveMCP(legacy) error = %v", err)
+	}
+	legacy, err := a.LegacyMCPs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := legacy["docs"]; ok || len(legacy) != 2 {
+		t.Errorf("LegacyMCPs() after remove = %v", legacy)
+	}
+}
+
+func TestOpenCodeMigrateLegacy(t *testing.T) {
+	a := openCodeFixture(t, "legacy.json", "opencode.json")
+
+	migrated, err := a.MigrateLegacy()
+	if err != nil {
+		t.Fatalf("MigrateLegacy() error = %v", err)
+	}
+	if want := []string{"docs", "playwright"}; !reflect.DeepEqual(migrated, want) {
+		t.Errorf("MigrateLegacy() = %v, want %v", migrated, want)
+	}
+
+	cfg, err := config.ReadConfig(a.ConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := config.GetMap(cfg, []string{"mcp", "docs"})
+	wantDocs := map[string]interface{}{
+		"type":    "remote",
+		"url":     "https://docs.example.com/mcp",
+		"headers": map[string]interface{}{"Authorization": "Bearer token"},
+		"enabled": true,
+	}
+	if !reflect.DeepEqual(docs, wantDocs) {
+		t.Errorf("migrated docs = %#v, want %#v", docs, wantDocs)
+	}
+
+	// context7 already exists in opencode.json, so it stays behind
+	legacyCfg, err := config.ReadConfig(a.LegacyConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	left := config.GetMap(legacyCfg, legacyOpenCodeKey)
+	if len(left) != 1 || left["context7"] == nil {
+		t.Errorf("legacy servers after migration = %v, want only context7", left)
+	}
+	if legacyCfg["theme"] != "opencode" {
+		t.Errorf("legacy settings were not preserved")
+	}
+
+	if migrated, err := a.MigrateLegacy(); err != nil || len(migrated) != 0 {
+		t.Errorf("second MigrateLegacy() = %v, %v; want nothing", migrated, err)
+	}
+}
@@ -0,0 +1,84 @@
+package agent
+
+import (
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/agentsdance/agentx/internal/home"
+)
+
+// envPattern matches an innermost ${VAR} or ${VAR:-default}; expanding
+// repeatedly resolves nested defaults from the inside out
+var envPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^${}]*))?\}`)
+
+// userConfigDirVar names the platform's per-user config directory in path
+// templates: $XDG_CONFIG_HOME or ~/.config on Linux, ~/Library/Application
+// Support on macOS and %APPDATA% on Windows
+const userConfigDirVar = "USER_CONFIG_DIR"
+
+// expandPath expands a definition path template. It understands ${VAR},
+// ${VAR:-default} (defaults may nest), ${USER_CONFIG_DIR} and a leading ~
+// for the home directory, so "${CODEX_HOME:-~/.codex}/config.toml" honours
+// CODEX_HOME when set.
+func expandPath(template string) string {
+	return expandPathWith(template, nil)
+}
+
+// expandPathWith is expandPath with env taking precedence over the process
+// environment, as named instances use to relocate an agent. When agentx is
+// re-rooted the process environment is ignored and ~ is the root.
+func expandPathWith(template string, env map[string]string) string {
+	if template == "" {
+		return ""
+	}
+	var lookup func(name string) string
+	lookup = func(name string) string {
+		if value, ok := env[name]; ok {
+			return value
+		}
+		if name == userConfigDirVar {
+			return userConfigDir(lookup)
+		}
+		return home.Getenv(name)
+	}
+	expanded := template
+	// Bounded, since a variable's value may itself look like a template
+	for i := 0; i < 8 && envPattern.MatchString(expanded); i++ {
+		expanded = envPattern.ReplaceAllStringFunc(expanded, func(match string) string {
+			groups := envPattern.FindStringSubmatch(match)
+			if value := lookup(groups[1]); value != "" {
+				return value
+			}
+			return groups[2]
+		})
+	}
+
+	if expanded == "~" || strings.HasPrefix(expanded, "~/") {
+		dir, ok := env["HOME"]
+		if !ok {
+			dir, _ = home.Dir()
+		}
+		expanded = filepath.Join(dir, strings.TrimPrefix(expanded, "~"))
+	}
+	return filepath.FromSlash(expanded)
+}
+
+// userConfigDir returns the ${USER_CONFIG_DIR} template, before ~ is
+// expanded, using lookup for environment variables
+func userConfigDir(lookup func(string) string) string {
+	switch runtime.GOOS {
+	case "windows":
+		if appData := lookup("APPDATA"); appData != "" {
+			return appData
+		}
+		return "~/AppData/Roaming"
+	case "darwin":
+		return "~/Library/Application Support"
+	}
+	if xdg := lookup("XDG_CONFIG_HOME"); xdg != "" {
+		return xdg
+	}
+	return "~/.config"
+}
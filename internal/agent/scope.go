@@ -0,0 +1,91 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/agentsdance/agentx/adapter/protocol"
+)
+
+// MCPScope indicates which config file an MCP operation targets
+type MCPScope = protocol.MCPScope
+
+const (
+	// MCPScopeUser is the agent's global config (~/.claude.json, ...)
+	MCPScopeUser = protocol.MCPScopeUser
+	// MCPScopeLocal is private per-project config kept in the agent's
+	// global config (Claude Code's projects["/abs/path"].mcpServers)
+	MCPScopeLocal = protocol.MCPScopeLocal
+	// MCPScopeProject is the config committed in the project (.mcp.json, ...)
+	MCPScopeProject = protocol.MCPScopeProject
+)
+
+// ParseMCPScope parses a scope name, defaulting to MCPScopeUser
+func ParseMCPScope(s string) (MCPScope, error) {
+	switch MCPScope(s) {
+	case "", MCPScopeUser:
+		return MCPScopeUser, nil
+	case MCPScopeLocal, MCPScopeProject:
+		return MCPScope(s), nil
+	}
+	return "", fmt.Errorf("unknown scope %q (want user, local or project)", s)
+}
+
+// SupportsMCPScope reports whether host can manage servers in scope
+func SupportsMCPScope(host MCPHost, scope MCPScope) bool {
+	for _, s := range host.MCPScopes() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ProjectRoot returns the root of the project containing the working
+// directory: the nearest ancestor with a .git entry, or the working
+// directory itself outside a repository
+func ProjectRoot() (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return FindProjectRoot(cwd), nil
+}
+
+// FindProjectRoot walks up from dir to the nearest directory containing
+// .git, returning dir if there is none
+func FindProjectRoot(dir string) string {
+	for current := dir; ; {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir
+		}
+		current = parent
+	}
+}
+
+// scopedConfig is implemented by agents that can name their per-scope
+// config files
+type scopedConfig interface {
+	MCPConfigPath(scope MCPScope) (string, error)
+}
+
+// MCPConfigPath returns the config file host uses for scope, or "" if
+// unknown
+func MCPConfigPath(host MCPHost, scope MCPScope) string {
+	if sc, ok := host.(scopedConfig); ok {
+		path, err := sc.MCPConfigPath(scope)
+		if err != nil {
+			return ""
+		}
+		return path
+	}
+	if scope == MCPScopeUser {
+		return host.ConfigPath()
+	}
+	return ""
+}
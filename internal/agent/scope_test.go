@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/agentsdance/agentx/internal/config"
+)
+
+// chdirProject creates a repository with a nested working directory and
+// changes into it, returning the repository root
+func chdirProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	nested := filepath.Join(root, "src", "pkg")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(nested)
+	return root
+}
+
+func TestFindProjectRoot(t *testing.T) {
+	root := chdirProject(t)
+	got, err := ProjectRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := filepath.EvalSymlinks(root); got != root && got != want {
+		t.Errorf("ProjectRoot() = %q, want %q", got, root)
+	}
+
+	outside := t.TempDir()
+	if got := FindProjectRoot(outside); got != outside {
+		t.Errorf("FindProjectRoot(outside) = %q, want %q", got, outside)
+	}
+}
+
+func TestProjectScopeMCP(t *testing.T) {
+	root := chdirProject(t)
+	userPath := filepath.Join(t.TempDir(), "user.json")
+	spec := MCPServerSpec{Command: "npx", Args: []string{"-y", "pkg"}}
+
+	tests := []struct {
+		agent string
+		file  string
+		read  func(path string) (map[string]interface{}, error)
+		key   string
+	}{
+		{"Claude Code", ".mcp.json", config.ReadConfig, "mcpServers"},
+		{"Cursor", ".cursor/mcp.json", config.ReadConfig, "mcpServers"},
+		{"Gemini cli", ".gemini/settings.json", config.ReadConfig, "mcpServers"},
+		{"Codex", ".codex/config.toml", config.ReadTOMLConfig, "mcp_servers"},
+		{"Droid", ".factory/mcp.json", config.ReadConfig, "mcpServers"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.agent, func(t *testing.T) {
+			host := builtinAgentAt(t, tt.agent, userPath).(MCPHost)
+			if !SupportsMCPScope(host, MCPScopeProject) {
+				t.Fatalf("%s does not report project scope", tt.agent)
+			}
+			if err := host.InstallMCP("shared", spec, MCPScopeProject); err != nil {
+				t.Fatalf("InstallMCP() error = %v", err)
+			}
+
+			cfg, err := tt.read(filepath.Join(root, filepath.FromSlash(tt.file)))
+			if err != nil {
+				t.Fatalf("project config not written: %v", err)
+			}
+			if servers := config.GetMap(cfg, []string{tt.key}); servers["shared"] == nil {
+				t.Errorf("project config = %v, want shared server", cfg)
+			}
+			if has, _ := host.HasMCP("shared", MCPScopeUser); has {
+				t.Errorf("server leaked into user scope")
+			}
+
+			if err := host.RemoveMCP("shared", MCPScopeProject); err != nil {
+				t.Fatalf("RemoveMCP() error = %v", err)
+			}
+			if has, err := host.HasMCP("shared", MCPScopeProject); err != nil || has {
+				t.Errorf("HasMCP() after remove = %v, %v", has, err)
+			}
+		})
+	}
+}
+
+func TestProjectScopeUnsupported(t *testing.T) {
+	chdirProject(t)
+	host := NewDeclarativeAgent(Definition{
+		Name:   "Kiro",
+		Config: filepath.Join(t.TempDir(), "mcp.json"),
+		Format: config.FormatJSON,
+		MCPKey: "mcpServers",
+	})
+
+	_, err := host.ListMCPs(MCPScopeProject)
+	var unsupported *UnsupportedError
+	if !errors.As(err, &unsupported) || unsupported.Scope != MCPScopeProject {
+		t.Fatalf("ListMCPs(project) error = %v, want project-scoped UnsupportedError", err)
+	}
+	if !errors.Is(err, ErrUnsupported) {
+		t.Errorf("error does not match ErrUnsupported")
+	}
+}
@@ -0,0 +1,116 @@
+package agent
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Transport values used by MCPServerSpec.Transport
+const (
+	TransportStdio = "stdio"
+	TransportSSE   = "sse"
+	TransportHTTP  = "http"
+)
+
+// MCPServerSpec is the agent-independent description of an MCP server.
+// Agents translate it to and from their native config format.
+type MCPServerSpec struct {
+	// Command, Args, Env and Cwd describe a local (stdio) server
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	Cwd     string            `json:"cwd,omitempty"`
+
+	// URL and Headers describe a remote server
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Transport is one of the Transport* constants, or empty when the
+	// source config left it implicit
+	Transport string `json:"transport,omitempty"`
+	// Timeout is the startup/request timeout, zero when unset
+	Timeout time.Duration `json:"timeout,omitempty"`
+	// Disabled marks servers that are configured but switched off
+	Disabled bool `json:"disabled,omitempty"`
+	// AutoApprove lists the tools the agent may call without asking
+	AutoApprove []string `json:"auto_approve,omitempty"`
+
+	// Scope is the config the server was listed from; it is set by
+	// ListMCPs and ignored when installing
+	Scope MCPScope `json:"scope,omitempty"`
+}
+
+// IsRemote reports whether the spec describes a remote server.
+func (s MCPServerSpec) IsRemote() bool {
+	return s.URL != "" && s.Command == ""
+}
+
+// Validate reports whether the spec describes a usable server.
+func (s MCPServerSpec) Validate() error {
+	if s.Command == "" && s.URL == "" {
+		return fmt.Errorf("mcp server needs a command or url")
+	}
+	return nil
+}
+
+// Clone returns a deep copy of the spec.
+func (s MCPServerSpec) Clone() MCPServerSpec {
+	cloned := s
+	if s.Args != nil {
+		cloned.Args = append([]string(nil), s.Args...)
+	}
+	if s.AutoApprove != nil {
+		cloned.AutoApprove = append([]string(nil), s.AutoApprove...)
+	}
+	cloned.Env = cloneStringMap(s.Env)
+	cloned.Headers = cloneStringMap(s.Headers)
+	return cloned
+}
+
+func cloneStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	cloned := make(map[string]string, len(m))
+	for key, value := range m {
+		cloned[key] = value
+	}
+	return cloned
+}
+
+// builtinMCPServers are the MCP servers agentx can install without
+// discovering them in another agent first.
+var builtinMCPServers = map[string]MCPServerSpec{
+	"playwright": {
+		Command: "npx",
+		Args:    []string{"@playwright/mcp@latest"},
+	},
+	"context7": {
+		Command: "npx",
+		Args:    []string{"-y", "@upstash/context7-mcp"},
+	},
+	"remix-icon": {
+		Command: "npx",
+		Args:    []string{"-y", "remixicon-mcp"},
+	},
+}
+
+// BuiltinMCPServer returns the spec for a built-in MCP server.
+func BuiltinMCPServer(name string) (MCPServerSpec, bool) {
+	spec, ok := builtinMCPServers[name]
+	if !ok {
+		return MCPServerSpec{}, false
+	}
+	return spec.Clone(), true
+}
+
+// BuiltinMCPServerNames returns the names of the built-in MCP servers.
+func BuiltinMCPServerNames() []string {
+	names := make([]string, 0, len(builtinMCPServers))
+	for name := range builtinMCPServers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
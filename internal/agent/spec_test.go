@@ -0,0 +1,211 @@
+package agent
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/agentsdance/agentx/internal/config"
+)
+
+type mcpRoundTripCase struct {
+	name     string
+	file     string
+	fixture  string
+	newAgent func(t *testing.T, path string) MCPHost
+	read     func(path string) (map[string]interface{}, error)
+	servers  func(cfg map[string]interface{}) map[string]interface{}
+}
+
+func jsonMCPServers(cfg map[string]interface{}) map[string]interface{} {
+	servers, _ := cfg["mcpServers"].(map[string]interface{})
+	return servers
+}
+
+func TestMCPRoundTrip(t *testing.T) {
+	tests := []mcpRoundTripCase{
+		{
+			name: "Claude Code",
+			file: ".claude.json",
+			fixture: `{
+  "mcpServers": {
+    "local": {"type": "stdio", "command": "npx", "args": ["-y", "pkg"], "env": {"TOKEN": "x"}},
+    "remote": {"type": "http", "url": "https://example.com/mcp", "headers": {"Authorization": "Bearer x"}},
+    "events": {"type": "sse", "url": "https://example.com/sse"}
+  }
+}`,
+			newAgent: func(t *testing.T, path string) MCPHost { return builtinAgentAt(t, "Claude Code", path).(MCPHost) },
+			read:     config.ReadConfig,
+			servers:  jsonMCPServers,
+		},
+		{
+			name: "Codex",
+			file: "config.toml",
+			fixture: `
+[mcp_servers.local]
+command = "npx"
+args = ["-y", "pkg"]
+cwd = "/tmp"
+startup_timeout_sec = 20
+
+[mcp_servers.local.env]
+TOKEN = "x"
+
+[mcp_servers.remote]
+url = "https://example.com/mcp"
+
+[mcp_servers.remote.http_headers]
+Authorization = "Bearer x"
+`,
+			newAgent: func(t *testing.T, path string) MCPHost { return builtinAgentAt(t, "Codex", path).(MCPHost) },
+			read:     config.ReadTOMLConfig,
+			servers: func(cfg map[string]interface{}) map[string]interface{} {
+				servers, _ := cfg["mcp_servers"].(map[string]interface{})
+				return servers
+			},
+		},
+		{
+			name: "Cursor",
+			file: "mcp.json",
+			fixture: `{
+  "mcpServers": {
+    "local": {"command": "node", "args": ["server.js"], "env": {"TOKEN": "x"}},
+    "remote": {"url": "https://example.com/mcp", "headers": {"X-Key": "y"}}
+  }
+}`,
+			newAgent: func(t *testing.T, path string) MCPHost { return builtinAgentAt(t, "Cursor", path).(MCPHost) },
+			read:     config.ReadConfig,
+			servers:  jsonMCPServers,
+		},
+		{
+			name: "Droid",
+			file: "mcp.json",
+			fixture: `{
+  "mcpServers": {
+    "local": {"type": "stdio", "command": "npx", "args": ["-y", "pkg"], "env": {"TOKEN": "x"}, "disabled": true},
+    "remote": {"type": "http", "url": "https://example.com/mcp", "headers": {"X-Key": "y"}}
+  }
+}`,
+			newAgent: func(t *testing.T, path string) MCPHost { return builtinAgentAt(t, "Droid", path).(MCPHost) },
+			read:     config.ReadConfig,
+			servers:  jsonMCPServers,
+		},
+		{
+			name: "Gemini cli",
+			file: "settings.json",
+			fixture: `{
+  "mcpServers": {
+    "local": {"command": "python", "args": ["-m", "srv"], "env": {"TOKEN": "x"}, "cwd": "./srv", "timeout": 30000},
+    "events": {"url": "https://example.com/sse", "headers": {"X-Key": "y"}},
+    "stream": {"httpUrl": "https://example.com/mcp"}
+  }
+}`,
+			newAgent: func(t *testing.T, path string) MCPHost { return builtinAgentAt(t, "Gemini cli", path).(MCPHost) },
+			read:     config.ReadConfig,
+			servers:  jsonMCPServers,
+		},
+		{
+			name: "opencode",
+			file: "opencode.json",
+			fixture: `{
+  "mcp": {
+    "local": {"type": "local", "command": ["npx", "-y", "pkg"], "environment": {"TOKEN": "x"}, "enabled": true, "timeout": 5000},
+    "remote": {"type": "remote", "url": "https://example.com/mcp", "headers": {"X-Key": "y"}, "enabled": false}
+  }
+}`,
+			newAgent: func(t *testing.T, path string) MCPHost {
+				t.Setenv("HOME", t.TempDir()) // no legacy config
+				return builtinAgentAt(t, "opencode", path).(MCPHost)
+			},
+			read: config.ReadConfig,
+			servers: func(cfg map[string]interface{}) map[string]interface{} {
+				servers, _ := cfg["mcp"].(map[string]interface{})
+				return servers
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			srcPath := filepath.Join(dir, "src", tt.file)
+			dstPath := filepath.Join(dir, "dst", tt.file)
+			if err := os.MkdirAll(filepath.Dir(srcPath), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(srcPath, []byte(tt.fixture), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			specs, err := tt.newAgent(t, srcPath).ListMCPs(MCPScopeUser)
+			if err != nil {
+				t.Fatalf("ListMCPs() error = %v", err)
+			}
+			dst := tt.newAgent(t, dstPath)
+			for name, spec := range specs {
+				if err := dst.InstallMCP(name, spec, MCPScopeUser); err != nil {
+					t.Fatalf("InstallMCP(%s) error = %v", name, err)
+				}
+			}
+
+			want, err := tt.read(srcPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := tt.read(dstPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tt.servers(got), tt.servers(want)) {
+				t.Errorf("round trip mismatch\n got: %#v\nwant: %#v", tt.servers(got), tt.servers(want))
+			}
+		})
+	}
+}
+
+func TestMCPTranslatorDropsUnsupportedFields(t *testing.T) {
+	spec := builtinTranslator(t, "Gemini cli").toSpec(map[string]interface{}{
+		"command": "python",
+		"args":    []interface{}{"-m", "srv"},
+		"cwd":     "./srv",
+		"timeout": json.Number("30000"),
+	})
+	if spec.Timeout != 30*time.Second {
+		t.Fatalf("Timeout = %v, want 30s", spec.Timeout)
+	}
+
+	got := builtinTranslator(t, "Claude Code").fromSpec(spec)
+	want := map[string]interface{}{
+		"command": "python",
+		"args":    []string{"-m", "srv"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fromSpec() = %#v, want %#v", got, want)
+	}
+
+	codex := builtinTranslator(t, "Codex").fromSpec(spec)
+	if codex["cwd"] != "./srv" || codex["startup_timeout_sec"] != int64(30) {
+		t.Errorf("codex entry = %#v, want cwd and startup_timeout_sec", codex)
+	}
+}
+
+func TestMCPTranslatorRemoteTransport(t *testing.T) {
+	spec := builtinTranslator(t, "Gemini cli").toSpec(map[string]interface{}{
+		"httpUrl": "https://example.com/mcp",
+	})
+	if spec.Transport != TransportHTTP || spec.URL != "https://example.com/mcp" {
+		t.Fatalf("spec = %#v, want http transport", spec)
+	}
+
+	got := builtinTranslator(t, "Droid").fromSpec(spec)
+	want := map[string]interface{}{
+		"type": "http",
+		"url":  "https://example.com/mcp",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fromSpec() = %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,288 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// mcpTranslator maps MCPServerSpec fields onto the keys an agent uses for a
+// single MCP server entry. An empty key means the agent has no equivalent
+// field, so the value is dropped when writing and ignored when reading.
+type mcpTranslator struct {
+	fields      FieldMapping
+	timeoutUnit time.Duration
+}
+
+func newMCPTranslator(fields FieldMapping) mcpTranslator {
+	unit, err := timeoutUnit(fields.TimeoutUnit)
+	if err != nil {
+		unit = time.Millisecond
+	}
+	return mcpTranslator{fields: fields, timeoutUnit: unit}
+}
+
+// toSpec converts a native MCP server entry into an MCPServerSpec.
+func (t mcpTranslator) toSpec(native map[string]interface{}) MCPServerSpec {
+	var spec MCPServerSpec
+	if native == nil {
+		return spec
+	}
+	f := t.fields
+	if f.CommandArray {
+		if parts := stringSlice(native, f.Command); len(parts) > 0 {
+			spec.Command = parts[0]
+			if len(parts) > 1 {
+				spec.Args = parts[1:]
+			}
+		}
+	} else {
+		spec.Command = stringValue(native, f.Command)
+		spec.Args = stringSlice(native, f.Args)
+	}
+	spec.Env = stringMap(native, f.Env)
+	spec.Cwd = stringValue(native, f.Cwd)
+	spec.Headers = stringMap(native, f.Headers)
+
+	for _, transport := range []string{TransportHTTP, TransportSSE} {
+		key, ok := f.URLByTransport[transport]
+		if !ok {
+			continue
+		}
+		if url := stringValue(native, key); url != "" {
+			spec.URL = url
+			spec.Transport = transport
+			break
+		}
+	}
+	if spec.URL == "" {
+		spec.URL = stringValue(native, f.URL)
+	}
+	if transport := stringValue(native, f.Transport); transport != "" {
+		spec.Transport = transport
+		// Several spec transports may share a native value (opencode's
+		// "remote"); sorting makes http win over sse
+		specValues := make([]string, 0, len(f.TransportValues))
+		for specValue := range f.TransportValues {
+			specValues = append(specValues, specValue)
+		}
+		sort.Strings(specValues)
+		for _, specValue := range specValues {
+			if f.TransportValues[specValue] == transport {
+				spec.Transport = specValue
+				break
+			}
+		}
+	}
+
+	if f.Timeout != "" {
+		if n, ok := numberValue(native[f.Timeout]); ok {
+			spec.Timeout = time.Duration(n * float64(t.timeoutUnit))
+		}
+	}
+	if f.Disabled != "" {
+		if disabled, ok := native[f.Disabled].(bool); ok {
+			spec.Disabled = disabled
+		}
+	}
+	if f.Enabled != "" {
+		if enabled, ok := native[f.Enabled].(bool); ok {
+			spec.Disabled = !enabled
+		}
+	}
+	spec.AutoApprove = stringSlice(native, f.AutoApprove)
+	return spec
+}
+
+// fromSpec converts an MCPServerSpec into a native MCP server entry,
+// dropping fields the agent does not understand.
+func (t mcpTranslator) fromSpec(spec MCPServerSpec) map[string]interface{} {
+	f := t.fields
+	native := make(map[string]interface{})
+	if spec.Command != "" && f.Command != "" {
+		if f.CommandArray {
+			native[f.Command] = append([]string{spec.Command}, spec.Args...)
+		} else {
+			native[f.Command] = spec.Command
+			if len(spec.Args) > 0 && f.Args != "" {
+				native[f.Args] = append([]string(nil), spec.Args...)
+			}
+		}
+	}
+	if len(spec.Env) > 0 && f.Env != "" {
+		native[f.Env] = cloneStringMap(spec.Env)
+	}
+	if spec.Cwd != "" && f.Cwd != "" {
+		native[f.Cwd] = spec.Cwd
+	}
+	if spec.URL != "" {
+		key := f.URL
+		if override, ok := f.URLByTransport[spec.Transport]; ok {
+			key = override
+		}
+		if key != "" {
+			native[key] = spec.URL
+		}
+	}
+	if len(spec.Headers) > 0 && f.Headers != "" {
+		native[f.Headers] = cloneStringMap(spec.Headers)
+	}
+	if f.Transport != "" {
+		transport := spec.Transport
+		if transport == "" && f.AlwaysTransport {
+			transport = TransportStdio
+			if spec.IsRemote() {
+				transport = TransportHTTP
+			}
+		}
+		if nativeValue, ok := f.TransportValues[transport]; ok {
+			transport = nativeValue
+		}
+		if transport != "" {
+			native[f.Transport] = transport
+		}
+	}
+	if spec.Timeout > 0 && f.Timeout != "" {
+		if spec.Timeout%t.timeoutUnit == 0 {
+			native[f.Timeout] = int64(spec.Timeout / t.timeoutUnit)
+		} else {
+			native[f.Timeout] = float64(spec.Timeout) / float64(t.timeoutUnit)
+		}
+	}
+	if spec.Disabled && f.Disabled != "" {
+		native[f.Disabled] = true
+	}
+	if f.Enabled != "" {
+		native[f.Enabled] = !spec.Disabled
+	}
+	if len(spec.AutoApprove) > 0 && f.AutoApprove != "" {
+		native[f.AutoApprove] = append([]string(nil), spec.AutoApprove...)
+	}
+	return native
+}
+
+// merge lays native over an existing entry for the same server: keys the
+// translator manages come from native, or are dropped unless listed in
+// keep, and keys it doesn't know (agent-specific settings) are kept.
+func (t mcpTranslator) merge(existing, native map[string]interface{}, keep ...string) map[string]interface{} {
+	merged := make(map[string]interface{}, len(existing)+len(native))
+	for key, value := range existing {
+		merged[key] = value
+	}
+	kept := make(map[string]bool, len(keep))
+	for _, key := range keep {
+		kept[key] = true
+	}
+	for _, key := range t.keys() {
+		if value, ok := native[key]; ok {
+			merged[key] = value
+		} else if !kept[key] {
+			delete(merged, key)
+		}
+	}
+	return merged
+}
+
+// keys returns the native keys the translator reads and writes
+func (t mcpTranslator) keys() []string {
+	f := t.fields
+	candidates := []string{f.Command, f.Args, f.Env, f.Cwd, f.URL, f.Headers, f.Transport, f.Timeout, f.Disabled, f.Enabled, f.AutoApprove}
+	for _, key := range f.URLByTransport {
+		candidates = append(candidates, key)
+	}
+	keys := candidates[:0]
+	for _, key := range candidates {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// specsFromNative converts every native entry in servers into a spec.
+func (t mcpTranslator) specsFromNative(servers map[string]map[string]interface{}) map[string]MCPServerSpec {
+	result := make(map[string]MCPServerSpec, len(servers))
+	for name, native := range servers {
+		if native == nil {
+			continue
+		}
+		result[name] = t.toSpec(native)
+	}
+	return result
+}
+
+func stringValue(m map[string]interface{}, key string) string {
+	if key == "" {
+		return ""
+	}
+	switch v := m[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func stringSlice(m map[string]interface{}, key string) []string {
+	if key == "" {
+		return nil
+	}
+	switch v := m[key].(type) {
+	case []string:
+		return append([]string(nil), v...)
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			} else {
+				values = append(values, fmt.Sprint(item))
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+func stringMap(m map[string]interface{}, key string) map[string]string {
+	if key == "" {
+		return nil
+	}
+	switch v := m[key].(type) {
+	case map[string]string:
+		return cloneStringMap(v)
+	case map[string]interface{}:
+		values := make(map[string]string, len(v))
+		for k, item := range v {
+			if s, ok := item.(string); ok {
+				values[k] = s
+			} else {
+				values[k] = fmt.Sprint(item)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+func numberValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case json.Number:
+		n, err := v.Float64()
+		return n, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,189 @@
+package agent
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/agentsdance/agentx/internal/config"
+)
+
+// vscodeSecretPattern matches env and header names whose values are
+// secrets: a whole "_" or "-" separated word such as KEY or TOKEN, so that
+// SENTRY_AUTH_TOKEN and X-API-Key match but MONKEY and AUTHOR don't
+var vscodeSecretPattern = regexp.MustCompile(`(?i)(^|[_-])(api[_-]?key|key|token|secret|password|passwd|credentials?|pat|authorization)($|[_-])`)
+
+// vscodeInputPattern matches an ${input:id} reference
+var vscodeInputPattern = regexp.MustCompile(`\$\{input:([^}]+)\}`)
+
+// VSCodeAgent extends the declarative VS Code agent with "inputs": secret
+// env values and headers are written as ${input:id} references to
+// promptString inputs, which VS Code asks for once and stores securely,
+// instead of in plaintext. Inputs no longer referenced are dropped when a
+// server is removed.
+type VSCodeAgent struct {
+	*DeclarativeAgent
+}
+
+func newVSCodeAgent(base *DeclarativeAgent) Agent {
+	return &VSCodeAgent{DeclarativeAgent: base}
+}
+
+func (a *VSCodeAgent) InstallMCP(name string, spec MCPServerSpec, scope MCPScope) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	path, err := a.MCPConfigPath(scope)
+	if err != nil {
+		return err
+	}
+	spec, inputs := vscodeSecretInputs(name, spec)
+	entry := a.translator.fromSpec(spec)
+	return a.updateConfig(path, func(cfg map[string]interface{}) error {
+		config.EnsureMap(cfg, a.mcpKey)[name] = entry
+		addVSCodeInputs(cfg, inputs)
+		return nil
+	})
+}
+
+func (a *VSCodeAgent) RemoveMCP(name string, scope MCPScope) error {
+	path, err := a.MCPConfigPath(scope)
+	if err != nil {
+		return err
+	}
+	return a.updateConfig(path, func(cfg map[string]interface{}) error {
+		servers := config.GetMap(cfg, a.mcpKey)
+		entry, ok := servers[name]
+		if !ok {
+			return config.ErrUnchanged
+		}
+		delete(servers, name)
+		pruneVSCodeInputs(cfg, vscodeInputRefs(entry), vscodeInputRefs(servers))
+		return nil
+	})
+}
+
+// vscodeSecretInputs replaces secret env values and headers in spec with
+// ${input:id} references and returns the inputs they refer to. Values that
+// already reference an input or variable are left alone.
+func vscodeSecretInputs(server string, spec MCPServerSpec) (MCPServerSpec, []map[string]interface{}) {
+	spec = spec.Clone()
+	var inputs []map[string]interface{}
+	secretize := func(values map[string]string) {
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := values[key]
+			if value == "" || strings.Contains(value, "${") || !vscodeSecretPattern.MatchString(key) {
+				continue
+			}
+			id := server + "-" + strings.ToLower(strings.ReplaceAll(key, "_", "-"))
+			scheme := ""
+			if i := strings.IndexByte(value, ' '); i > 0 && strings.EqualFold(key, "Authorization") {
+				scheme = value[:i+1] // keep "Bearer "
+			}
+			values[key] = scheme + "${input:" + id + "}"
+			inputs = append(inputs, map[string]interface{}{
+				"type":        "promptString",
+				"id":          id,
+				"description": fmt.Sprintf("%s for %s", key, server),
+				"password":    true,
+			})
+		}
+	}
+	secretize(spec.Env)
+	secretize(spec.Headers)
+	return spec, inputs
+}
+
+// referencesVSCodeInputs reports whether spec uses ${input:id} values,
+// which VS Code keeps in its secret storage and no other agent can resolve
+func referencesVSCodeInputs(spec MCPServerSpec) bool {
+	values := append([]string{spec.Command, spec.Cwd, spec.URL}, spec.Args...)
+	for _, value := range spec.Env {
+		values = append(values, value)
+	}
+	for _, value := range spec.Headers {
+		values = append(values, value)
+	}
+	for _, value := range values {
+		if vscodeInputPattern.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// addVSCodeInputs appends inputs to the config's inputs array, skipping
+// ids that are already declared
+func addVSCodeInputs(cfg map[string]interface{}, inputs []map[string]interface{}) {
+	if len(inputs) == 0 {
+		return
+	}
+	existing, _ := cfg["inputs"].([]interface{})
+	declared := make(map[string]bool, len(existing))
+	for _, raw := range existing {
+		if input, ok := raw.(map[string]interface{}); ok {
+			declared[stringValue(input, "id")] = true
+		}
+	}
+	for _, input := range inputs {
+		if id := input["id"].(string); !declared[id] {
+			existing = append(existing, input)
+			declared[id] = true
+		}
+	}
+	cfg["inputs"] = existing
+}
+
+// pruneVSCodeInputs drops the inputs in removed that nothing in keep still
+// references
+func pruneVSCodeInputs(cfg map[string]interface{}, removed, keep map[string]bool) {
+	existing, ok := cfg["inputs"].([]interface{})
+	if !ok || len(removed) == 0 {
+		return
+	}
+	kept := existing[:0]
+	for _, raw := range existing {
+		if input, ok := raw.(map[string]interface{}); ok {
+			id := stringValue(input, "id")
+			if removed[id] && !keep[id] {
+				continue
+			}
+		}
+		kept = append(kept, raw)
+	}
+	cfg["inputs"] = kept
+}
+
+// vscodeInputRefs returns the input ids referenced anywhere in v
+func vscodeInputRefs(v interface{}) map[string]bool {
+	refs := make(map[string]bool)
+	var walk func(interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case string:
+			for _, match := range vscodeInputPattern.FindAllStringSubmatch(v, -1) {
+				refs[match[1]] = true
+			}
+		case map[string]interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		case map[string]string:
+			for _, item := range v {
+				walk(item)
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		}
+	}
+	walk(v)
+	return refs
+}
@@ -0,0 +1,145 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/agentsdance/agentx/internal/config"
+)
+
+func TestVSCodeConfigPaths(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("user config dir is platform specific")
+	}
+	t.Setenv("HOME", "/home/dev")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	a := NewAgent(builtinDefinition(t, "VS Code"))
+	if _, ok := a.(*VSCodeAgent); !ok {
+		t.Fatal("VS Code definition did not use the vscode adapter")
+	}
+	if got, want := a.ConfigPath(), "/home/dev/.config/Code/User/mcp.json"; got != want {
+		t.Errorf("ConfigPath() = %q, want %q", got, want)
+	}
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+	if got, want := NewAgent(builtinDefinition(t, "VS Code")).ConfigPath(), "/xdg/Code/User/mcp.json"; got != want {
+		t.Errorf("ConfigPath() = %q, want %q", got, want)
+	}
+}
+
+func TestVSCodeInputs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp.json")
+	fixture := `{
+  "inputs": [{"type": "promptString", "id": "shared-token", "description": "Shared", "password": true}],
+  "servers": {
+    "github": {"type": "http", "url": "https://api.githubcopilot.com/mcp/", "headers": {"Authorization": "Bearer ${input:shared-token}"}}
+  }
+}`
+	if err := os.WriteFile(path, []byte(fixture), 0644); err != nil {
+		t.Fatal(err)
+	}
+	host := builtinAgentAt(t, "VS Code", path).(MCPHost)
+
+	spec := MCPServerSpec{
+		Command: "npx",
+		Args:    []string{"-y", "@sentry/mcp-server"},
+		Env:     map[string]string{"SENTRY_AUTH_TOKEN": "sntrys_secret", "SENTRY_HOST": "sentry.io"},
+	}
+	if err := host.InstallMCP("sentry", spec, MCPScopeUser); err != nil {
+		t.Fatalf("InstallMCP() error = %v", err)
+	}
+	remote := MCPServerSpec{URL: "https://mcp.example.com", Headers: map[string]string{"Authorization": "Bearer abc"}}
+	if err := host.InstallMCP("example", remote, MCPScopeUser); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.ReadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sentry := config.GetMap(cfg, []string{"servers", "sentry"})
+	wantSentry := map[string]interface{}{
+		"type":    "stdio",
+		"command": "npx",
+		"args":    []interface{}{"-y", "@sentry/mcp-server"},
+		"env": map[string]interface{}{
+			"SENTRY_AUTH_TOKEN": "${input:sentry-sentry-auth-token}",
+			"SENTRY_HOST":       "sentry.io",
+		},
+	}
+	if !reflect.DeepEqual(sentry, wantSentry) {
+		t.Errorf("sentry entry = %#v\nwant %#v", sentry, wantSentry)
+	}
+	headers := config.GetMap(cfg, []string{"servers", "example", "headers"})
+	if headers["Authorization"] != "Bearer ${input:example-authorization}" {
+		t.Errorf("example headers = %v", headers)
+	}
+	if got := inputIDs(cfg); !reflect.DeepEqual(got, []string{"shared-token", "sentry-sentry-auth-token", "example-authorization"}) {
+		t.Errorf("inputs = %v", got)
+	}
+
+	// Removing a server drops its inputs but keeps shared ones
+	if err := host.RemoveMCP("sentry", MCPScopeUser); err != nil {
+		t.Fatal(err)
+	}
+	if err := host.RemoveMCP("github", MCPScopeUser); err != nil {
+		t.Fatal(err)
+	}
+	cfg, _ = config.ReadConfig(path)
+	if got := inputIDs(cfg); !reflect.DeepEqual(got, []string{"example-authorization"}) {
+		t.Errorf("inputs after remove = %v", got)
+	}
+}
+
+func TestVSCodeWorkspaceScope(t *testing.T) {
+	root := chdirProject(t)
+	host := builtinAgentAt(t, "VS Code", filepath.Join(t.TempDir(), "mcp.json")).(MCPHost)
+	spec := MCPServerSpec{URL: "https://mcp.context7.com/mcp", Transport: TransportHTTP}
+	if err := host.InstallMCP("context7", spec, MCPScopeProject); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.ReadConfig(filepath.Join(root, ".vscode", "mcp.json"))
+	if err != nil {
+		t.Fatalf("workspace mcp.json not written: %v", err)
+	}
+	want := map[string]interface{}{"type": "http", "url": "https://mcp.context7.com/mcp"}
+	if got := config.GetMap(cfg, []string{"servers", "context7"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("workspace entry = %#v, want %#v", got, want)
+	}
+	specs, err := host.ListMCPs(MCPScopeProject)
+	if err != nil || specs["context7"].URL != spec.URL || specs["context7"].Transport != TransportHTTP {
+		t.Errorf("ListMCPs(project) = %+v, %v", specs, err)
+	}
+}
+
+func TestVSCodeSecretPattern(t *testing.T) {
+	for name, want := range map[string]bool{
+		"SENTRY_AUTH_TOKEN":  true,
+		"CONTEXT7_API_KEY":   true,
+		"OPENAI_APIKEY":      true,
+		"DB_PASSWORD":        true,
+		"GITHUB_PAT":         true,
+		"X-API-Key":          true,
+		"Authorization":      true,
+		"AUTHOR":             false,
+		"MONKEY":             false,
+		"OAUTH_CALLBACK_URL": false,
+		"KEYBOARD_LAYOUT":    false,
+		"SENTRY_HOST":        false,
+	} {
+		if got := vscodeSecretPattern.MatchString(name); got != want {
+			t.Errorf("vscodeSecretPattern matches %s = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func inputIDs(cfg map[string]interface{}) []string {
+	var ids []string
+	inputs, _ := cfg["inputs"].([]interface{})
+	for _, raw := range inputs {
+		ids = append(ids, stringValue(raw.(map[string]interface{}), "id"))
+	}
+	return ids
+}
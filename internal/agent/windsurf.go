@@ -0,0 +1,31 @@
+package agent
+
+import "github.com/agentsdance/agentx/internal/config"
+
+// WindsurfAgent extends the declarative Windsurf agent so that installing
+// over an existing server keeps what the user set in Windsurf: the
+// disabled flag (agentx never silently re-enables a server) and
+// disabledTools. Disabled servers are listed with Disabled set.
+type WindsurfAgent struct {
+	*DeclarativeAgent
+}
+
+func newWindsurfAgent(base *DeclarativeAgent) Agent {
+	return &WindsurfAgent{DeclarativeAgent: base}
+}
+
+func (a *WindsurfAgent) InstallMCP(name string, spec MCPServerSpec, scope MCPScope) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	path, err := a.MCPConfigPath(scope)
+	if err != nil {
+		return err
+	}
+	return a.updateConfig(path, func(cfg map[string]interface{}) error {
+		servers := config.EnsureMap(cfg, a.mcpKey)
+		existing, _ := servers[name].(map[string]interface{})
+		servers[name] = a.translator.merge(existing, a.translator.fromSpec(spec), a.def.Fields.Disabled)
+		return nil
+	})
+}
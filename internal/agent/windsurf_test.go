@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/agentsdance/agentx/internal/config"
+)
+
+func TestWindsurfMCPs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp_config.json")
+	fixture := `{
+  "mcpServers": {
+    "github": {
+      "command": "npx",
+      "args": ["-y", "@modelcontextprotocol/server-github"],
+      "disabled": true,
+      "disabledTools": ["create_issue"]
+    },
+    "docs": {"serverUrl": "https://docs.example.com/mcp"}
+  }
+}`
+	if err := os.WriteFile(path, []byte(fixture), 0644); err != nil {
+		t.Fatal(err)
+	}
+	a, ok := builtinAgentAt(t, "Windsurf", path).(*WindsurfAgent)
+	if !ok {
+		t.Fatal("Windsurf definition did not use the windsurf adapter")
+	}
+
+	specs, err := a.ListMCPs(MCPScopeUser)
+	if err != nil {
+		t.Fatalf("ListMCPs() error = %v", err)
+	}
+	want := map[string]MCPServerSpec{
+		"github": {Command: "npx", Args: []string{"-y", "@modelcontextprotocol/server-github"}, Disabled: true, Scope: MCPScopeUser},
+		"docs":   {URL: "https://docs.example.com/mcp", Scope: MCPScopeUser},
+	}
+	if !reflect.DeepEqual(specs, want) {
+		t.Errorf("ListMCPs() = %#v\nwant %#v", specs, want)
+	}
+
+	// Reinstalling keeps the user's disabled flag and disabledTools
+	github := MCPServerSpec{Command: "npx", Args: []string{"-y", "@modelcontextprotocol/server-github@latest"}}
+	if err := a.InstallMCP("github", github, MCPScopeUser); err != nil {
+		t.Fatalf("InstallMCP() error = %v", err)
+	}
+	remote := MCPServerSpec{URL: "https://mcp.example.com/mcp", Headers: map[string]string{"Authorization": "Bearer abc"}}
+	if err := a.InstallMCP("example", remote, MCPScopeUser); err != nil {
+		t.Fatalf("InstallMCP() error = %v", err)
+	}
+
+	cfg, err := config.ReadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotGitHub := config.GetMap(cfg, []string{"mcpServers", "github"})
+	wantGitHub := map[string]interface{}{
+		"command":       "npx",
+		"args":          []interface{}{"-y", "@modelcontextprotocol/server-github@latest"},
+		"disabled":      true,
+		"disabledTools": []interface{}{"create_issue"},
+	}
+	if !reflect.DeepEqual(gotGitHub, wantGitHub) {
+		t.Errorf("github entry = %#v\nwant %#v", gotGitHub, wantGitHub)
+	}
+	gotExample := config.GetMap(cfg, []string{"mcpServers", "example"})
+	wantExample := map[string]interface{}{
+		"serverUrl": "https://mcp.example.com/mcp",
+		"headers":   map[string]interface{}{"Authorization": "Bearer abc"},
+	}
+	if !reflect.DeepEqual(gotExample, wantExample) {
+		t.Errorf("example entry = %#v\nwant %#v", gotExample, wantExample)
+	}
+}
@@ -0,0 +1,220 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// ZedAgent extends the declarative Zed agent with the shapes Zed accepts
+// for a context server and with servers provided by installed extensions.
+// A custom server is either flat, as current releases write it ("source":
+// "custom" with command, args and env), or the older command object
+// {path, args, env}; new servers follow whichever shape the file already
+// uses. Extension servers are listed but never modified, like their
+// "source": "extension" settings entries.
+type ZedAgent struct {
+	*DeclarativeAgent
+}
+
+func newZedAgent(base *DeclarativeAgent) Agent {
+	return &ZedAgent{DeclarativeAgent: base}
+}
+
+func (a *ZedAgent) HasMCP(name string, scope MCPScope) (bool, error) {
+	ok, err := a.DeclarativeAgent.HasMCP(name, scope)
+	if err != nil || ok || scope != MCPScopeUser {
+		return ok, err
+	}
+	extensions, err := a.listExtensionMCPs()
+	if err != nil {
+		return false, err
+	}
+	_, ok = extensions[name]
+	return ok, nil
+}
+
+func (a *ZedAgent) InstallMCP(name string, spec MCPServerSpec, scope MCPScope) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	path, err := a.MCPConfigPath(scope)
+	if err != nil {
+		return err
+	}
+	servers, err := a.nativeMCPsAt(path, a.mcpKey)
+	if err != nil {
+		return err
+	}
+	if err := a.checkNotExtension(name, servers, scope); err != nil {
+		return err
+	}
+
+	entry := a.translator.fromSpec(spec)
+	if !spec.IsRemote() {
+		if zedUsesCommandObject(servers) {
+			entry["command"] = zedCommandObject(entry)
+			delete(entry, "args")
+			delete(entry, "env")
+		} else {
+			entry["source"] = "custom"
+		}
+	}
+	return a.setMCPEntry(path, name, entry)
+}
+
+func (a *ZedAgent) RemoveMCP(name string, scope MCPScope) error {
+	path, err := a.MCPConfigPath(scope)
+	if err != nil {
+		return err
+	}
+	servers, err := a.nativeMCPsAt(path, a.mcpKey)
+	if err != nil {
+		return err
+	}
+	if err := a.checkNotExtension(name, servers, scope); err != nil {
+		return err
+	}
+	if _, ok := servers[name]; !ok {
+		return nil
+	}
+	return a.deleteMCPEntry(path, name)
+}
+
+func (a *ZedAgent) ListMCPs(scope MCPScope) (map[string]MCPServerSpec, error) {
+	servers, err := a.nativeMCPs(scope)
+	if err != nil {
+		return nil, err
+	}
+	flat := make(map[string]map[string]interface{}, len(servers))
+	for name, entry := range servers {
+		flat[name] = zedFlatEntry(entry)
+	}
+	result := a.specsFromNative(flat, scope)
+	if scope != MCPScopeUser {
+		return result, nil
+	}
+	extensions, err := a.listExtensionMCPs()
+	if err != nil {
+		return result, nil
+	}
+	for name := range extensions {
+		if _, exists := result[name]; !exists {
+			result[name] = MCPServerSpec{Scope: MCPScopeUser}
+		}
+	}
+	return result, nil
+}
+
+// checkNotExtension refuses to change a server that an extension provides
+func (a *ZedAgent) checkNotExtension(name string, servers map[string]map[string]interface{}, scope MCPScope) error {
+	if entry, ok := servers[name]; ok {
+		if stringValue(entry, "source") == "extension" {
+			return fmt.Errorf("mcp %s is provided by a Zed extension", name)
+		}
+		return nil
+	}
+	if scope != MCPScopeUser {
+		return nil
+	}
+	extensions, err := a.listExtensionMCPs()
+	if err != nil {
+		return nil
+	}
+	if extension, ok := extensions[name]; ok {
+		return fmt.Errorf("mcp %s is provided by the Zed extension %s", name, extension)
+	}
+	return nil
+}
+
+// extensionsDir returns the directory Zed installs extensions into
+func (a *ZedAgent) extensionsDir() string {
+	template := "${XDG_DATA_HOME:-~/.local/share}/zed/extensions/installed"
+	switch runtime.GOOS {
+	case "darwin":
+		template = "~/Library/Application Support/Zed/extensions/installed"
+	case "windows":
+		template = "${LOCALAPPDATA:-~/AppData/Local}/Zed/extensions/installed"
+	}
+	return expandPathWith(template, a.def.Env)
+}
+
+// listExtensionMCPs returns the context servers declared by installed
+// extensions, mapped to the extension providing each
+func (a *ZedAgent) listExtensionMCPs() (map[string]string, error) {
+	extensionsDir := a.extensionsDir()
+	entries, err := os.ReadDir(extensionsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+
+	result := make(map[string]string)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(extensionsDir, entry.Name(), "extension.toml"))
+		if err != nil {
+			continue
+		}
+		var manifest struct {
+			ContextServers map[string]interface{} `toml:"context_servers"`
+		}
+		if err := toml.Unmarshal(data, &manifest); err != nil {
+			continue
+		}
+		for server := range manifest.ContextServers {
+			result[server] = entry.Name()
+		}
+	}
+	return result, nil
+}
+
+// zedFlatEntry returns entry with an older command object flattened into
+// command, args and env
+func zedFlatEntry(entry map[string]interface{}) map[string]interface{} {
+	command, ok := entry["command"].(map[string]interface{})
+	if !ok {
+		return entry
+	}
+	flat := make(map[string]interface{}, len(entry)+2)
+	for key, value := range entry {
+		flat[key] = value
+	}
+	flat["command"] = command["path"]
+	flat["args"] = command["args"]
+	flat["env"] = command["env"]
+	return flat
+}
+
+// zedCommandObject returns the {path, args, env} object for a flat entry
+func zedCommandObject(entry map[string]interface{}) map[string]interface{} {
+	command := map[string]interface{}{
+		"path": entry["command"],
+		"args": entry["args"],
+	}
+	if command["args"] == nil {
+		command["args"] = []string{}
+	}
+	if env, ok := entry["env"]; ok {
+		command["env"] = env
+	}
+	return command
+}
+
+// zedUsesCommandObject reports whether the custom servers in servers use
+// the older command object shape, which new servers should follow
+func zedUsesCommandObject(servers map[string]map[string]interface{}) bool {
+	for _, entry := range servers {
+		if _, ok := entry["command"].(map[string]interface{}); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/agentsdance/agentx/internal/config"
+)
+
+// zedAgentAt returns the built-in Zed agent reading settings from a copy of
+// the fixture, with extensions installed under a temporary data dir
+func zedAgentAt(t *testing.T, fixture string) (*ZedAgent, string) {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("Zed's data dir is platform specific")
+	}
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "data"))
+	path := filepath.Join(dir, "settings.json")
+	if fixture != "" {
+		data, err := os.ReadFile(filepath.Join("testdata", "zed", fixture))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, ok := builtinAgentAt(t, "Zed", path).(*ZedAgent)
+	if !ok {
+		t.Fatal("Zed definition did not use the zed adapter")
+	}
+	return a, path
+}
+
+func installZedExtension(t *testing.T, id, manifest string) {
+	t.Helper()
+	dir := filepath.Join(os.Getenv("XDG_DATA_HOME"), "zed", "extensions", "installed", id)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "extension.toml"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestZedListMCPs(t *testing.T) {
+	a, _ := zedAgentAt(t, "settings.json")
+	installZedExtension(t, "context7-mcp", `
+id = "context7-mcp"
+name = "Context7"
+version = "0.1.0"
+
+[context_servers.mcp-server-context7]
+`)
+
+	specs, err := a.ListMCPs(MCPScopeUser)
+	if err != nil {
+		t.Fatalf("ListMCPs() error = %v", err)
+	}
+	want := map[string]MCPServerSpec{
+		"postgres": {
+			Command: "npx",
+			Args:    []string{"-y", "@modelcontextprotocol/server-postgres", "postgres://localhost/dev"},
+			Env:     map[string]string{"PGPASSWORD": "secret"},
+			Scope:   MCPScopeUser,
+		},
+		"mcp-server-github":   {Scope: MCPScopeUser},
+		"mcp-server-context7": {Scope: MCPScopeUser},
+	}
+	if !reflect.DeepEqual(specs, want) {
+		t.Errorf("ListMCPs() = %#v\nwant %#v", specs, want)
+	}
+
+	for _, name := range []string{"mcp-server-github", "mcp-server-context7"} {
+		if ok, _ := a.HasMCP(name, MCPScopeUser); !ok {
+			t.Errorf("HasMCP(%q) = false", name)
+		}
+		if err := a.RemoveMCP(name, MCPScopeUser); err == nil || !strings.Contains(err.Error(), "Zed extension") {
+			t.Errorf("RemoveMCP(%q) error = %v, want extension error", name, err)
+		}
+	}
+	collected := CollectMCPConfigs([]MCPHost{a})
+	if _, ok := collected["mcp-server-context7"]; ok {
+		t.Error("CollectMCPConfigs() offered an extension server for install")
+	}
+}
+
+func TestZedEditPreservesComments(t *testing.T) {
+	a, path := zedAgentAt(t, "settings.json")
+	original, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := MCPServerSpec{Command: "uvx", Args: []string{"mcp-server-git"}}
+	if err := a.InstallMCP("git", spec, MCPScopeUser); err != nil {
+		t.Fatalf("InstallMCP() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, keep := range []string{"// Zed settings", `"theme": "One Dark", // keep the theme`, "/* context servers */", `"vim_mode": true,`} {
+		if !strings.Contains(string(data), keep) {
+			t.Errorf("settings lost %q:\n%s", keep, data)
+		}
+	}
+	cfg, err := config.ParseJSONC(data)
+	if err != nil {
+		t.Fatalf("settings no longer parse: %v\n%s", err, data)
+	}
+	// The file already uses the command object, so the new server does too
+	got := config.GetMap(cfg, []string{"context_servers", "git"})
+	want := map[string]interface{}{
+		"command": map[string]interface{}{"path": "uvx", "args": []interface{}{"mcp-server-git"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("git entry = %#v\nwant %#v", got, want)
+	}
+
+	if err := a.RemoveMCP("git", MCPScopeUser); err != nil {
+		t.Fatalf("RemoveMCP() error = %v", err)
+	}
+	data, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(original) {
+		t.Errorf("install then remove changed the file:\n%s\nwant\n%s", data, original)
+	}
+}
+
+func TestZedFlatShape(t *testing.T) {
+	a, path := zedAgentAt(t, "")
+	spec := MCPServerSpec{Command: "npx", Args: []string{"-y", "@upstash/context7-mcp"}, Env: map[string]string{"CONTEXT7_API_KEY": "key"}}
+	if err := a.InstallMCP("context7", spec, MCPScopeUser); err != nil {
+		t.Fatalf("InstallMCP() error = %v", err)
+	}
+	remote := MCPServerSpec{URL: "https://mcp.example.com/mcp", Headers: map[string]string{"Authorization": "Bearer abc"}}
+	if err := a.InstallMCP("example", remote, MCPScopeUser); err != nil {
+		t.Fatalf("InstallMCP() error = %v", err)
+	}
+
+	cfg, err := config.ReadJSONCConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := config.GetMap(cfg, []string{"context_servers", "context7"})
+	want := map[string]interface{}{
+		"source":  "custom",
+		"command": "npx",
+		"args":    []interface{}{"-y", "@upstash/context7-mcp"},
+		"env":     map[string]interface{}{"CONTEXT7_API_KEY": "key"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("context7 entry = %#v\nwant %#v", got, want)
+	}
+
+	specs, err := a.ListMCPs(MCPScopeUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec.Scope = MCPScopeUser
+	remote.Scope = MCPScopeUser
+	if !reflect.DeepEqual(specs, map[string]MCPServerSpec{"context7": spec, "example": remote}) {
+		t.Errorf("ListMCPs() = %#v", specs)
+	}
+}
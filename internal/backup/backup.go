@@ -0,0 +1,387 @@
+// Package backup snapshots the files and directories agentx is about to
+// change, so that an operation can be undone. Each operation that changes
+// something gets a snapshot directory under ~/.agentx/backups/<id>/ and an
+// entry in ~/.agentx/backups/journal.jsonl that records which paths it
+// touched and whether they existed before.
+//
+// Commands wrap their changes in Begin and Commit; the config writers and
+// the skill and plugin managers call Save before changing a path. Save is a
+// no-op outside an operation, so library code and tests don't take
+// snapshots unless asked to.
+package backup
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/agentsdance/agentx/internal/home"
+	"github.com/agentsdance/agentx/internal/plan"
+)
+
+// journalFile is the name of the journal inside the backups directory
+const journalFile = "journal.jsonl"
+
+// idFormat names snapshots so that they sort by time
+const idFormat = "20060102-150405.000"
+
+// Entry is one path saved by an operation
+type Entry struct {
+	Path string `json:"path"`
+	// Existed is false when the operation created the path, which undoing
+	// it removes again
+	Existed bool        `json:"existed"`
+	Dir     bool        `json:"dir,omitempty"`
+	Mode    os.FileMode `json:"mode,omitempty"`
+	// Backup is the name of the copy inside the snapshot directory
+	Backup string `json:"backup,omitempty"`
+	// After is a digest of the path as the operation left it (see
+	// digestPath). Rolling the operation back refuses to overwrite a path
+	// that has changed since. Journals from older versions lack it.
+	After string `json:"after,omitempty"`
+}
+
+// Snapshot is a journal entry: an operation and the paths it saved
+type Snapshot struct {
+	ID        string    `json:"id"`
+	Time      time.Time `json:"time"`
+	Operation string    `json:"operation"`
+	Entries   []Entry   `json:"entries"`
+	// Reverts lists the snapshots an undo or restore rolled back
+	Reverts []string `json:"reverts,omitempty"`
+}
+
+// Dir returns the backups directory (~/.agentx/backups)
+func Dir() (string, error) {
+	dir, err := home.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, ".agentx", "backups"), nil
+}
+
+// Operation collects the snapshots of one user-facing change
+type Operation struct {
+	snap  Snapshot
+	dir   string
+	saved map[string]bool
+}
+
+var (
+	mu     sync.Mutex
+	active *Operation
+	depth  int
+)
+
+// Begin starts an operation described by description, such as
+// "install context7". Paths passed to Save until the matching Commit are
+// snapshotted into it. Begin inside another operation joins it, so the
+// outer operation is undone as a whole.
+func Begin(description string) *Operation {
+	mu.Lock()
+	defer mu.Unlock()
+	depth++
+	if active == nil {
+		active = &Operation{
+			snap:  Snapshot{Operation: description},
+			saved: make(map[string]bool),
+		}
+	}
+	return active
+}
+
+// Commit ends the operation and, if it saved anything, adds it to the
+// journal and prunes old snapshots. It ends a joined operation without
+// committing the outer one.
+func (op *Operation) Commit() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if depth == 0 || active != op {
+		return nil
+	}
+	depth--
+	if depth > 0 {
+		return nil
+	}
+	active = nil
+	if len(op.snap.Entries) == 0 {
+		if op.dir != "" {
+			os.RemoveAll(op.dir)
+		}
+		return nil
+	}
+	for i := range op.snap.Entries {
+		// A path that can't be read is left without a digest, which
+		// rolling back doesn't check
+		op.snap.Entries[i].After, _ = digestPath(op.snap.Entries[i].Path)
+	}
+	backups, err := Dir()
+	if err != nil {
+		return err
+	}
+	if err := appendJournal(backups, op.snap); err != nil {
+		return err
+	}
+	return prune(backups, RetentionFromEnv(), time.Now())
+}
+
+// Save snapshots path into the active operation before it is changed or
+// deleted. A path that doesn't exist yet is recorded so that undoing the
+// operation removes it. Each path is saved once per operation, holding its
+// state from before the operation. Save does nothing outside an operation,
+// or during a dry run, which changes nothing that would need undoing.
+func Save(path string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if active == nil || plan.Active() != nil {
+		return nil
+	}
+	return active.save(path)
+}
+
+func (op *Operation) save(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if target, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = target
+	}
+	backups, err := Dir()
+	if err != nil {
+		return err
+	}
+	if op.saved[abs] || within(abs, backups) {
+		return nil
+	}
+
+	if op.dir == "" {
+		if err := op.create(backups); err != nil {
+			return err
+		}
+	}
+	entry := Entry{Path: abs}
+	info, err := os.Stat(abs)
+	switch {
+	case err == nil:
+		entry.Existed = true
+		entry.Dir = info.IsDir()
+		entry.Mode = info.Mode().Perm()
+		entry.Backup = strconv.Itoa(len(op.snap.Entries))
+		if err := copyPath(abs, filepath.Join(op.dir, entry.Backup)); err != nil {
+			return fmt.Errorf("backing up %s: %w", abs, err)
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+	op.saved[abs] = true
+	op.snap.Entries = append(op.snap.Entries, entry)
+	return nil
+}
+
+// create makes the operation's snapshot directory
+func (op *Operation) create(backups string) error {
+	op.snap.ID, op.snap.Time = newID(backups)
+	op.dir = filepath.Join(backups, op.snap.ID)
+	return os.MkdirAll(op.dir, 0700)
+}
+
+// newID returns an unused snapshot ID for the current time
+func newID(backups string) (string, time.Time) {
+	now := time.Now()
+	id := now.UTC().Format(idFormat)
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(backups, id)); os.IsNotExist(err) {
+			return id, now
+		}
+		id = now.UTC().Format(idFormat) + "-" + strconv.Itoa(i)
+	}
+}
+
+// absent is the digest of a path that doesn't exist
+const absent = "absent"
+
+// digestPath returns a digest of the content of the file or directory tree
+// at path, or absent if there is nothing there. Modes are left out, so a
+// chmod doesn't count as a change.
+func digestPath(path string) (string, error) {
+	if _, err := os.Lstat(path); os.IsNotExist(err) {
+		return absent, nil
+	}
+	h := sha256.New()
+	err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(path, p)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00%v\x00", filepath.ToSlash(rel), info.Mode().Type())
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			io.WriteString(h, link)
+		case info.Mode().IsRegular():
+			f, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if _, err := io.Copy(h, f); err != nil {
+				return err
+			}
+		}
+		h.Write([]byte{0})
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// within reports whether path is dir or inside it
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// ReadJournal returns the snapshots in the journal, oldest first
+func ReadJournal() ([]Snapshot, error) {
+	backups, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return readJournal(backups)
+}
+
+func readJournal(backups string) ([]Snapshot, error) {
+	f, err := os.Open(filepath.Join(backups, journalFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var snaps []Snapshot
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var snap Snapshot
+		if err := json.Unmarshal(scanner.Bytes(), &snap); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", f.Name(), line, err)
+		}
+		snaps = append(snaps, snap)
+	}
+	return snaps, scanner.Err()
+}
+
+// appendJournal adds snap to the journal
+func appendJournal(backups string, snap Snapshot) error {
+	if err := os.MkdirAll(backups, 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(backups, journalFile), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeJournal replaces the journal with snaps
+func writeJournal(backups string, snaps []Snapshot) error {
+	var buf strings.Builder
+	for _, snap := range snaps {
+		data, err := json.Marshal(snap)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	path := filepath.Join(backups, journalFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(buf.String()), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// copyPath copies the file or directory tree at src to dst
+func copyPath(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case !info.Mode().IsRegular():
+			return nil
+		}
+		return copyFile(path, target, info.Mode().Perm())
+	})
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// ErrNothingToUndo is returned by Undo when every operation in the journal
+// has been undone
+var ErrNothingToUndo = errors.New("nothing to undo")
@@ -0,0 +1,247 @@
+package backup
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/agentsdance/agentx/internal/home"
+)
+
+func setupHome(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	t.Setenv(home.EnvVar, root)
+	t.Setenv(KeepEnvVar, "")
+	t.Setenv(MaxAgeEnvVar, "")
+	return root
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// change runs one operation that saves and then changes paths
+func change(t *testing.T, description string, edit func(save func(string))) {
+	t.Helper()
+	op := Begin(description)
+	edit(func(path string) {
+		if err := Save(path); err != nil {
+			t.Fatalf("Save(%s) error = %v", path, err)
+		}
+	})
+	if err := op.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+}
+
+func TestUndo(t *testing.T) {
+	root := setupHome(t)
+	config := filepath.Join(root, ".claude.json")
+	skill := filepath.Join(root, ".claude", "skills", "pdf")
+	writeFile(t, config, `{"mcpServers": {}}`)
+
+	change(t, "install context7", func(save func(string)) {
+		save(config)
+		save(config) // saved once, holding the state before the operation
+		writeFile(t, config, `{"mcpServers": {"context7": {}}}`)
+		save(skill)
+		writeFile(t, filepath.Join(skill, "SKILL.md"), "# pdf")
+	})
+
+	snap, err := Undo(false)
+	if err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if snap.Operation != "install context7" || len(snap.Entries) != 2 {
+		t.Errorf("Undo() = %+v", snap)
+	}
+	if got := readFile(t, config); got != `{"mcpServers": {}}` {
+		t.Errorf("config after undo = %s", got)
+	}
+	if info, err := os.Stat(config); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("config mode after undo = %v, %v", info.Mode(), err)
+	}
+	if _, err := os.Stat(skill); !os.IsNotExist(err) {
+		t.Errorf("skill created by the operation is still there: %v", err)
+	}
+
+	// The undo itself is not undone by the next undo
+	if _, err := Undo(false); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("second Undo() error = %v, want %v", err, ErrNothingToUndo)
+	}
+
+	list, err := List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || !list[1].Undone || list[0].Operation != "undo install context7" || list[0].Undone {
+		t.Fatalf("List() = %+v", list)
+	}
+
+	// Restoring to before the undo redoes the operation
+	if _, err := RestoreTo(list[0].ID, false); err != nil {
+		t.Fatalf("RestoreTo() error = %v", err)
+	}
+	if got := readFile(t, config); got != `{"mcpServers": {"context7": {}}}` {
+		t.Errorf("config after redo = %s", got)
+	}
+	if got := readFile(t, filepath.Join(skill, "SKILL.md")); got != "# pdf" {
+		t.Errorf("skill after redo = %s", got)
+	}
+}
+
+func TestRestoreTo(t *testing.T) {
+	root := setupHome(t)
+	plugin := filepath.Join(root, ".agentx", "plugins", "review")
+	writeFile(t, filepath.Join(plugin, "plugin.json"), "v1")
+
+	change(t, "plugins install review", func(save func(string)) {
+		save(plugin)
+		writeFile(t, filepath.Join(plugin, "plugin.json"), "v2")
+		writeFile(t, filepath.Join(plugin, "extra.md"), "extra")
+	})
+	change(t, "plugins remove review", func(save func(string)) {
+		save(plugin)
+		if err := os.RemoveAll(plugin); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	snaps, err := ReadJournal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rolledBack, err := RestoreTo(snaps[0].ID, false)
+	if err != nil {
+		t.Fatalf("RestoreTo() error = %v", err)
+	}
+	if len(rolledBack) != 2 || rolledBack[0].Operation != "plugins remove review" {
+		t.Errorf("RestoreTo() rolled back %+v", rolledBack)
+	}
+	if got := readFile(t, filepath.Join(plugin, "plugin.json")); got != "v1" {
+		t.Errorf("plugin.json = %s, want v1", got)
+	}
+	if _, err := os.Stat(filepath.Join(plugin, "extra.md")); !os.IsNotExist(err) {
+		t.Error("extra.md added by the install survived the restore")
+	}
+
+	if _, err := RestoreTo("nope", false); err == nil {
+		t.Error("RestoreTo() of an unknown id succeeded")
+	}
+}
+
+func TestUndoRefusesChangedFiles(t *testing.T) {
+	root := setupHome(t)
+	config := filepath.Join(root, ".claude.json")
+	writeFile(t, config, `{"mcpServers": {}}`)
+	change(t, "install context7", func(save func(string)) {
+		save(config)
+		writeFile(t, config, `{"mcpServers": {"context7": {}}}`)
+	})
+	// The agent writes its config after the install
+	writeFile(t, config, `{"mcpServers": {"context7": {}}, "numStartups": 2}`)
+
+	var modified *ModifiedError
+	if _, err := Undo(false); !errors.As(err, &modified) || len(modified.Paths) != 1 || modified.Paths[0] != config {
+		t.Fatalf("Undo() error = %v, want a ModifiedError for %s", err, config)
+	}
+	if got := readFile(t, config); got != `{"mcpServers": {"context7": {}}, "numStartups": 2}` {
+		t.Errorf("config after refused undo = %s", got)
+	}
+	if list, _ := List(); len(list) != 1 || list[0].Undone {
+		t.Errorf("refused undo was recorded: %+v", list)
+	}
+
+	if _, err := Undo(true); err != nil {
+		t.Fatalf("Undo(force) error = %v", err)
+	}
+	if got := readFile(t, config); got != `{"mcpServers": {}}` {
+		t.Errorf("config after forced undo = %s", got)
+	}
+	if info, err := os.Stat(config); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("config mode after forced undo = %v, %v", info.Mode(), err)
+	}
+}
+
+func TestSaveOutsideOperation(t *testing.T) {
+	root := setupHome(t)
+	if err := Save(filepath.Join(root, "settings.json")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".agentx", "backups")); !os.IsNotExist(err) {
+		t.Error("Save outside an operation created a backup")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	root := setupHome(t)
+	backups, _ := Dir()
+	now := time.Now()
+	var snaps []Snapshot
+	for i, age := range []time.Duration{40 * 24 * time.Hour, 3 * time.Hour, 2 * time.Hour, time.Hour} {
+		snap := Snapshot{ID: now.Add(-age).UTC().Format(idFormat), Time: now.Add(-age), Operation: "op"}
+		snap.Entries = []Entry{{Path: filepath.Join(root, "f"), Existed: i > 0}}
+		if err := os.MkdirAll(filepath.Join(backups, snap.ID), 0700); err != nil {
+			t.Fatal(err)
+		}
+		snaps = append(snaps, snap)
+	}
+	if err := writeJournal(backups, snaps); err != nil {
+		t.Fatal(err)
+	}
+
+	// The oldest is past MaxAge, and Keep drops the next one
+	if err := prune(backups, Retention{Keep: 10, MaxAge: DefaultMaxAge}, now); err != nil {
+		t.Fatalf("prune() error = %v", err)
+	}
+	if kept, _ := readJournal(backups); len(kept) != 3 {
+		t.Errorf("kept %d snapshots after the age limit, want 3", len(kept))
+	}
+	if err := prune(backups, Retention{Keep: 2}, now); err != nil {
+		t.Fatalf("prune() error = %v", err)
+	}
+	kept, err := readJournal(backups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kept) != 2 || kept[0].ID != snaps[2].ID || kept[1].ID != snaps[3].ID {
+		t.Errorf("kept %+v, want the two newest", kept)
+	}
+	for i, snap := range snaps {
+		_, err := os.Stat(filepath.Join(backups, snap.ID))
+		if exists := err == nil; exists != (i >= 2) {
+			t.Errorf("snapshot %d exists = %v", i, exists)
+		}
+	}
+}
+
+func TestRetentionFromEnv(t *testing.T) {
+	t.Setenv(KeepEnvVar, "5")
+	t.Setenv(MaxAgeEnvVar, "14d")
+	if got := RetentionFromEnv(); got != (Retention{Keep: 5, MaxAge: 14 * 24 * time.Hour}) {
+		t.Errorf("RetentionFromEnv() = %+v", got)
+	}
+	t.Setenv(KeepEnvVar, "many")
+	t.Setenv(MaxAgeEnvVar, "72h")
+	if got := RetentionFromEnv(); got != (Retention{Keep: DefaultKeep, MaxAge: 72 * time.Hour}) {
+		t.Errorf("RetentionFromEnv() = %+v", got)
+	}
+}
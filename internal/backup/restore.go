@@ -0,0 +1,271 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/agentsdance/agentx/internal/plan"
+	"github.com/agentsdance/agentx/internal/safefile"
+)
+
+// Record is a journal entry with its current state
+type Record struct {
+	Snapshot
+	// Undone is true when a later undo or restore rolled the operation back
+	Undone bool
+}
+
+// List returns the journal, newest first
+func List() ([]Record, error) {
+	snaps, err := ReadJournal()
+	if err != nil {
+		return nil, err
+	}
+	return records(snaps), nil
+}
+
+// records marks the snapshots rolled back by a later undo or restore that
+// wasn't itself rolled back, and returns them newest first
+func records(snaps []Snapshot) []Record {
+	out := make([]Record, 0, len(snaps))
+	reverted := make(map[string]bool)
+	for i := len(snaps) - 1; i >= 0; i-- {
+		r := Record{Snapshot: snaps[i], Undone: reverted[snaps[i].ID]}
+		if !r.Undone {
+			for _, id := range r.Reverts {
+				reverted[id] = true
+			}
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// ModifiedError is returned by Undo and RestoreTo when paths changed after
+// the operations being rolled back, by an agent or the user, so that
+// rolling back would overwrite those changes. Nothing is rolled back.
+type ModifiedError struct {
+	Paths []string
+}
+
+func (e *ModifiedError) Error() string {
+	return "changed since agentx last wrote it: " + strings.Join(e.Paths, ", ")
+}
+
+// errModifiedDuringRestore aborts restoring a path that changed while it
+// was being restored
+var errModifiedDuringRestore = errors.New("changed while it was being restored")
+
+// Undo rolls back the most recent operation that hasn't been undone and
+// returns it. Undo and restore operations are skipped, so repeated undos
+// walk back through the history; restore --to an undo's snapshot redoes.
+// Unless force is set, it returns a ModifiedError instead if a path the
+// operation changed has changed since.
+func Undo(force bool) (Snapshot, error) {
+	list, err := List()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	for _, r := range list {
+		if r.Undone || len(r.Reverts) > 0 {
+			continue
+		}
+		if err := revert("undo "+r.Operation, []Snapshot{r.Snapshot}, force); err != nil {
+			return Snapshot{}, err
+		}
+		return r.Snapshot, nil
+	}
+	return Snapshot{}, ErrNothingToUndo
+}
+
+// RestoreTo puts every path back the way it was before the operation id,
+// rolling back it and every later operation, newest first. id may be
+// shortened to any unique prefix. It returns the operations rolled back.
+// Unless force is set, it returns a ModifiedError instead if a path changed
+// after the last of those operations that touched it.
+func RestoreTo(id string, force bool) ([]Snapshot, error) {
+	snaps, err := ReadJournal()
+	if err != nil {
+		return nil, err
+	}
+	start := -1
+	for i, snap := range snaps {
+		if !strings.HasPrefix(snap.ID, id) {
+			continue
+		}
+		if start >= 0 {
+			return nil, fmt.Errorf("snapshot id %q is ambiguous", id)
+		}
+		start = i
+	}
+	if start < 0 {
+		return nil, fmt.Errorf("no snapshot %q (see agentx restore --list)", id)
+	}
+	rollback := make([]Snapshot, 0, len(snaps)-start)
+	for i := len(snaps) - 1; i >= start; i-- {
+		rollback = append(rollback, snaps[i])
+	}
+	if err := revert("restore to "+snaps[start].ID, rollback, force); err != nil {
+		return nil, err
+	}
+	return rollback, nil
+}
+
+// revert restores the paths saved in snaps, which are newest first, as a
+// new operation of its own, so the rollback can be rolled back in turn
+func revert(description string, snaps []Snapshot, force bool) (err error) {
+	backups, err := Dir()
+	if err != nil {
+		return err
+	}
+	if !force {
+		if err := checkUnchanged(snaps); err != nil {
+			return err
+		}
+	}
+	op := Begin(description)
+	defer func() {
+		if cerr := op.Commit(); err == nil {
+			err = cerr
+		}
+	}()
+	for _, snap := range snaps {
+		op.snap.Reverts = append(op.snap.Reverts, snap.ID)
+	}
+	for _, snap := range snaps {
+		for i := len(snap.Entries) - 1; i >= 0; i-- {
+			entry := snap.Entries[i]
+			if err := Save(entry.Path); err != nil {
+				return err
+			}
+			if err := restoreEntry(filepath.Join(backups, snap.ID), entry); err != nil {
+				return fmt.Errorf("restoring %s: %w", entry.Path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkUnchanged returns a ModifiedError if a path saved in snaps, which
+// are newest first, is no longer as the newest of them left it
+func checkUnchanged(snaps []Snapshot) error {
+	var modified []string
+	seen := make(map[string]bool)
+	for _, snap := range snaps {
+		for _, entry := range snap.Entries {
+			if seen[entry.Path] {
+				continue
+			}
+			seen[entry.Path] = true
+			if entry.After == "" {
+				continue
+			}
+			now, err := digestPath(entry.Path)
+			if err != nil {
+				return err
+			}
+			if now != entry.After {
+				modified = append(modified, entry.Path)
+			}
+		}
+	}
+	if len(modified) > 0 {
+		return &ModifiedError{Paths: modified}
+	}
+	return nil
+}
+
+// restoreEntry puts one saved path back, or removes it if the operation
+// created it. Files are written like the config writers write them: under
+// the lock other agentx processes take, and atomically.
+func restoreEntry(snapDir string, entry Entry) error {
+	if p := plan.Active(); p != nil {
+		return planEntry(p, snapDir, entry)
+	}
+	if entry.Existed && entry.Dir {
+		return restoreDir(filepath.Join(snapDir, entry.Backup), entry)
+	}
+	if _, err := os.Lstat(entry.Path); !entry.Existed && os.IsNotExist(err) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(entry.Path), 0755); err != nil {
+		return err
+	}
+	unlock, err := safefile.Lock(entry.Path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if !entry.Existed {
+		if err := os.RemoveAll(entry.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(snapDir, entry.Backup))
+	if err != nil {
+		return fmt.Errorf("backup is missing: %w", err)
+	}
+	if info, err := os.Lstat(entry.Path); err == nil && info.IsDir() {
+		if err := os.RemoveAll(entry.Path); err != nil {
+			return err
+		}
+	}
+	current, err := digestPath(entry.Path)
+	if err != nil {
+		return err
+	}
+	err = safefile.Replace(entry.Path, data, entry.Mode, func() error {
+		// An agent may rewrite its config without taking the lock
+		if now, err := digestPath(entry.Path); err != nil || now != current {
+			return errModifiedDuringRestore
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return os.Chmod(entry.Path, entry.Mode)
+}
+
+// restoreDir puts a saved directory tree back. It is copied next to the
+// path first, so a failed copy leaves the path untouched.
+func restoreDir(src string, entry Entry) error {
+	if _, err := os.Stat(src); err != nil {
+		return fmt.Errorf("backup is missing: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(entry.Path), 0755); err != nil {
+		return err
+	}
+	tmp := entry.Path + ".agentx-restore"
+	os.RemoveAll(tmp)
+	if err := copyPath(src, tmp); err != nil {
+		os.RemoveAll(tmp)
+		return err
+	}
+	if err := os.Chmod(tmp, entry.Mode); err != nil {
+		os.RemoveAll(tmp)
+		return err
+	}
+	if err := os.RemoveAll(entry.Path); err != nil {
+		os.RemoveAll(tmp)
+		return err
+	}
+	return os.Rename(tmp, entry.Path)
+}
+
+// planEntry records in a dry run what restoreEntry would do
+func planEntry(p *plan.Plan, snapDir string, entry Entry) error {
+	if err := p.RemoveAll(entry.Path); err != nil || !entry.Existed {
+		return err
+	}
+	src := filepath.Join(snapDir, entry.Backup)
+	if entry.Dir {
+		return p.CopyTree(entry.Path, src)
+	}
+	return p.CopyFile(entry.Path, src)
+}
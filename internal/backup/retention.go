@@ -0,0 +1,88 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables that configure the retention policy
+const (
+	KeepEnvVar   = "AGENTX_BACKUP_KEEP"
+	MaxAgeEnvVar = "AGENTX_BACKUP_MAX_AGE"
+)
+
+// Default retention: the last 50 operations from the last 30 days
+const (
+	DefaultKeep   = 50
+	DefaultMaxAge = 30 * 24 * time.Hour
+)
+
+// Retention decides which snapshots are pruned after each operation
+type Retention struct {
+	// Keep is the number of most recent snapshots kept; 0 keeps none
+	Keep int
+	// MaxAge prunes snapshots older than this; 0 disables the age limit
+	MaxAge time.Duration
+}
+
+// RetentionFromEnv returns the retention policy set by AGENTX_BACKUP_KEEP
+// (a count) and AGENTX_BACKUP_MAX_AGE (a duration such as 72h or 14d),
+// falling back to the defaults for unset or invalid values
+func RetentionFromEnv() Retention {
+	r := Retention{Keep: DefaultKeep, MaxAge: DefaultMaxAge}
+	if v := strings.TrimSpace(os.Getenv(KeepEnvVar)); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			r.Keep = n
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv(MaxAgeEnvVar)); v != "" {
+		if d, ok := parseAge(v); ok {
+			r.MaxAge = d
+		}
+	}
+	return r
+}
+
+// parseAge parses a Go duration, or a number of days such as "14d"
+func parseAge(v string) (time.Duration, bool) {
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		n, err := strconv.Atoi(days)
+		return time.Duration(n) * 24 * time.Hour, err == nil && n >= 0
+	}
+	d, err := time.ParseDuration(v)
+	return d, err == nil && d >= 0
+}
+
+// Prune removes the snapshots the retention policy no longer keeps
+func Prune(r Retention) error {
+	backups, err := Dir()
+	if err != nil {
+		return err
+	}
+	return prune(backups, r, time.Now())
+}
+
+func prune(backups string, r Retention, now time.Time) error {
+	snaps, err := readJournal(backups)
+	if err != nil {
+		return err
+	}
+	keep := snaps[:0:0]
+	for i, snap := range snaps {
+		tooOld := r.MaxAge > 0 && now.Sub(snap.Time) > r.MaxAge
+		if len(snaps)-i > r.Keep || tooOld {
+			if err := os.RemoveAll(filepath.Join(backups, snap.ID)); err != nil {
+				return err
+			}
+			continue
+		}
+		keep = append(keep, snap)
+	}
+	if len(keep) == len(snaps) {
+		return nil
+	}
+	return writeJournal(backups, keep)
+}
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Format identifies the file format of an agent config
+type Format string
+
+const (
+	FormatJSON  Format = "json"
+	FormatJSONC Format = "jsonc"
+	FormatTOML  Format = "toml"
+	FormatYAML  Format = "yaml"
+)
+
+// ReadDocument reads a config file in the given format
+func ReadDocument(path string, format Format) (map[string]interface{}, error) {
+	switch format {
+	case FormatJSON:
+		return ReadConfig(path)
+	case FormatJSONC:
+		return ReadJSONCConfig(path)
+	case FormatTOML:
+		return ReadTOMLConfig(path)
+	case FormatYAML:
+		return ReadYAMLConfig(path)
+	}
+	return nil, fmt.Errorf("unsupported config format: %s", format)
+}
+
+// WriteDocument writes a config file in the given format
+func WriteDocument(path string, format Format, cfg map[string]interface{}) error {
+	switch format {
+	case FormatJSON, FormatJSONC:
+		return WriteConfig(path, cfg)
+	case FormatTOML:
+		return WriteTOMLConfig(path, cfg)
+	case FormatYAML:
+		return WriteYAMLConfig(path, cfg)
+	}
+	return fmt.Errorf("unsupported config format: %s", format)
+}
+
+// ErrNotPatchable is returned when a TOML config can't be edited in place.
+// agentx leaves the file alone rather than re-encode it, which would drop
+// its comments and reorder it.
+var ErrNotPatchable = errors.New("can't edit the config in place")
+
+// ErrUnchanged is returned by an UpdateDocument mutation that leaves the
+// config as it was, so that the file isn't rewritten
+var ErrUnchanged = errors.New("config unchanged")
+
+// UpdateDocument changes the config at path in the given format with a
+// locked read-modify-write (see UpdateFile). mutate gets the parsed config,
+// empty when the file doesn't exist, and may run more than once when the
+// file changes under it. JSON, JSONC and TOML files are edited in place:
+// only the members and tables mutate changed are rewritten, and the rest
+// of the file keeps its bytes.
+func UpdateDocument(path string, format Format, mutate func(cfg map[string]interface{}) error) error {
+	return UpdateFile(path, func(data []byte) ([]byte, error) {
+		cfg := map[string]interface{}{}
+		if data != nil {
+			parsed, err := parseDocument(data, format)
+			if err != nil {
+				return nil, inFile(err, path)
+			}
+			if parsed != nil {
+				cfg = parsed
+			}
+		}
+		if err := mutate(cfg); err != nil {
+			if errors.Is(err, ErrUnchanged) {
+				return data, nil
+			}
+			return nil, err
+		}
+		switch {
+		case format == FormatYAML:
+		case format == FormatTOML || len(bytes.TrimSpace(data)) > 0:
+			// Patch the original text rather than re-encoding all of it,
+			// so that a change to one server is a change to one server
+			patched, err := patchDocument(data, format, cfg)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			return patched, nil
+		}
+		return encodeDocument(data, format, cfg)
+	})
+}
+
+// patchDocument edits data, the current content of a JSON, JSONC or TOML
+// config, so that it holds cfg
+func patchDocument(data []byte, format Format, cfg map[string]interface{}) ([]byte, error) {
+	old, err := parseDocument(data, format)
+	if err != nil || old == nil {
+		return encodeDocument(data, format, cfg)
+	}
+	if format != FormatTOML {
+		return patchJSON(data, old, cfg)
+	}
+
+	// TOML can spell the same table in several ways (headers, dotted keys,
+	// inline tables). Should an edit come out meaning anything other than
+	// cfg, refuse it rather than rewrite the file without its comments.
+	patched, err := patchTOML(data, old, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotPatchable, err)
+	}
+	encoded, err := encodeDocument(data, format, cfg)
+	if err != nil {
+		return nil, err
+	}
+	got, err := parseTOML(patched)
+	if err != nil {
+		return nil, fmt.Errorf("%w: the edit doesn't parse: %v", ErrNotPatchable, err)
+	}
+	want, err := parseTOML(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if !reflect.DeepEqual(dropEmptyTables(got), dropEmptyTables(want)) {
+		return nil, fmt.Errorf("%w: the edit changes more than it should", ErrNotPatchable)
+	}
+	return patched, nil
+}
+
+// dropEmptyTables removes the tables left with nothing in them, such as
+// mcp_servers once its last server is removed
+func dropEmptyTables(table map[string]interface{}) map[string]interface{} {
+	for key, value := range table {
+		if sub, ok := value.(map[string]interface{}); ok {
+			if len(dropEmptyTables(sub)) == 0 {
+				delete(table, key)
+			}
+		}
+	}
+	return table
+}
+
+func parseDocument(data []byte, format Format) (map[string]interface{}, error) {
+	switch format {
+	case FormatJSON, FormatJSONC:
+		return ParseJSONC(data)
+	case FormatTOML:
+		return parseTOML(data)
+	case FormatYAML:
+		return parseYAML(data)
+	}
+	return nil, fmt.Errorf("unsupported config format: %s", format)
+}
+
+// encodeDocument encodes cfg in the given format; YAML is merged into the
+// existing document
+func encodeDocument(existing []byte, format Format, cfg map[string]interface{}) ([]byte, error) {
+	switch format {
+	case FormatJSON, FormatJSONC:
+		return encodeJSON(cfg, "", "  ")
+	case FormatTOML:
+		if cfg == nil {
+			cfg = map[string]interface{}{}
+		}
+		return toml.Marshal(cfg)
+	case FormatYAML:
+		return encodeYAML(existing, cfg)
+	}
+	return nil, fmt.Errorf("unsupported config format: %s", format)
+}
+
+// SplitKeyPath splits a dot-separated key path such as "mcp.servers"
+func SplitKeyPath(keyPath string) []string {
+	if keyPath == "" {
+		return nil
+	}
+	return strings.Split(keyPath, ".")
+}
+
+// GetMap returns the nested object at path, or nil if any segment is
+// missing or not an object
+func GetMap(cfg map[string]interface{}, path []string) map[string]interface{} {
+	current := cfg
+	for _, key := range path {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = next
+	}
+	return current
+}
+
+// EnsureMap returns the nested object at path, creating missing segments
+func EnsureMap(cfg map[string]interface{}, path []string) map[string]interface{} {
+	current := cfg
+	for _, key := range path {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			current[key] = next
+		}
+		current = next
+	}
+	return current
+}
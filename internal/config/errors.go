@@ -0,0 +1,75 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// SyntaxError is a config file that doesn't parse, with the line and
+// column (both from 1) of the problem
+type SyntaxError struct {
+	Path   string // empty for data that didn't come from a file
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("line %d, column %d: %v", e.Line, e.Column, e.Err)
+	}
+	return fmt.Sprintf("%s:%d:%d: %v", e.Path, e.Line, e.Column, e.Err)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// jsonSyntaxError turns an encoding/json error from parsing data, or the
+// comment-stripped copy of it, into a SyntaxError
+func jsonSyntaxError(data []byte, err error) error {
+	var syntax *json.SyntaxError
+	var typ *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntax):
+		// Offset counts the bytes read, including the offending one
+		line, col := position(data, int(syntax.Offset)-1)
+		return &SyntaxError{Line: line, Column: col, Err: err}
+	case errors.As(err, &typ):
+		line, col := position(data, int(typ.Offset)-1)
+		return &SyntaxError{Line: line, Column: col, Err: fmt.Errorf("expected an object, found %s", typ.Value)}
+	}
+	return err
+}
+
+// tomlDecodeError turns a go-toml decode error into a SyntaxError
+func tomlDecodeError(err error) error {
+	var decode *toml.DecodeError
+	if errors.As(err, &decode) {
+		line, col := decode.Position()
+		return &SyntaxError{Line: line, Column: col, Err: err}
+	}
+	return err
+}
+
+// inFile records path in a SyntaxError
+func inFile(err error, path string) error {
+	var syntax *SyntaxError
+	if errors.As(err, &syntax) && syntax.Path == "" {
+		syntax.Path = path
+	}
+	return err
+}
+
+// position returns the line and column of offset in data, counting
+// columns in characters
+func position(data []byte, offset int) (int, int) {
+	offset = max(0, min(offset, len(data)))
+	start := lineStart(data, offset)
+	return bytes.Count(data[:start], []byte("\n")) + 1, utf8.RuneCount(data[start:offset]) + 1
+}
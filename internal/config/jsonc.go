@@ -0,0 +1,549 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// JSONC is JSON with // and /* */ comments and trailing commas, as Zed and
+// VS Code settings files use. Reading strips both; editing patches the
+// original text so that everything outside the edited member survives.
+
+// ReadJSONCConfig reads a JSONC config file
+func ReadJSONCConfig(path string) (map[string]interface{}, error) {
+	data, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := ParseJSONC(data)
+	return cfg, inFile(err, path)
+}
+
+// ParseJSONC parses a JSONC document whose top level is an object. An
+// empty document is an empty object. Numbers are json.Number, so that
+// large integers such as timestamps and IDs are written back exactly. A
+// malformed document gives a *SyntaxError with the line and column of the
+// problem.
+func ParseJSONC(data []byte) (map[string]interface{}, error) {
+	stripped := StripJSONC(data)
+	if len(bytes.TrimSpace(stripped)) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	if !json.Valid(stripped) {
+		var probe interface{}
+		return nil, jsonSyntaxError(data, json.Unmarshal(stripped, &probe))
+	}
+	dec := json.NewDecoder(bytes.NewReader(stripped))
+	dec.UseNumber()
+	var cfg map[string]interface{}
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, jsonSyntaxError(data, err)
+	}
+	return cfg, nil
+}
+
+// StripJSONC blanks out comments and trailing commas, leaving plain JSON.
+// Every removed byte becomes a space (newlines are kept), so offsets into
+// the result are offsets into data.
+func StripJSONC(data []byte) []byte {
+	out := stripJSONCComments(data)
+	inString := false
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		if inString {
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case ',':
+			next := skipSpace(out, i+1)
+			if next < len(out) && (out[next] == '}' || out[next] == ']') {
+				out[i] = ' '
+			}
+		}
+	}
+	return out
+}
+
+// stripJSONCComments returns a copy of data with comments blanked out
+func stripJSONCComments(data []byte) []byte {
+	out := append([]byte(nil), data...)
+	inString := false
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		if inString {
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		if c == '"' {
+			inString = true
+			continue
+		}
+		if c != '/' || i+1 >= len(out) {
+			continue
+		}
+		switch out[i+1] {
+		case '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case '*':
+			end := bytes.Index(out[i+2:], []byte("*/"))
+			stop := len(out)
+			if end >= 0 {
+				stop = i + 2 + end + 2
+			}
+			for ; i < stop; i++ {
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+			i--
+		}
+	}
+	return out
+}
+
+// SetJSONC returns data with the member at path set to value. Missing
+// objects along the path are created. Only the text of the member's value
+// (or the new member) changes; comments, trailing commas and the layout of
+// everything else are kept.
+func SetJSONC(data []byte, path []string, value interface{}) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("empty key path")
+	}
+	doc, err := parseJSONCDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	if doc.root == nil {
+		encoded, err := doc.marshal(nestValue(path, value), "")
+		if err != nil {
+			return nil, err
+		}
+		return append(append(data[:len(data):len(data)], encoded...), '\n'), nil
+	}
+
+	obj := doc.root
+	for i, key := range path {
+		member := obj.member(key)
+		if member == nil {
+			return doc.insert(obj, key, nestValue(path[i+1:], value))
+		}
+		if i == len(path)-1 || !member.value.object {
+			var encoded []byte
+			if doc.multiline(obj) && !doc.inlineContainer(&member.value) {
+				encoded, err = doc.marshal(nestValue(path[i+1:], value), doc.lineIndent(member.keyStart))
+			} else {
+				encoded, err = doc.marshalCompact(nestValue(path[i+1:], value))
+			}
+			if err != nil {
+				return nil, err
+			}
+			return splice(data, member.value.start, member.value.end, encoded), nil
+		}
+		obj = &member.value
+	}
+	return data, nil
+}
+
+// DeleteJSONC returns data with the member at path removed, along with its
+// line when it had one to itself. Deleting a missing member is a no-op.
+func DeleteJSONC(data []byte, path []string) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("empty key path")
+	}
+	doc, err := parseJSONCDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	if doc.root == nil {
+		return data, nil
+	}
+	obj := doc.root
+	for _, key := range path[:len(path)-1] {
+		member := obj.member(key)
+		if member == nil || !member.value.object {
+			return data, nil
+		}
+		obj = &member.value
+	}
+	index := -1
+	for i := range obj.members {
+		if obj.members[i].key == path[len(path)-1] {
+			index = i
+		}
+	}
+	if index < 0 {
+		return data, nil
+	}
+	return doc.remove(obj, index), nil
+}
+
+// nestValue wraps value in one object per key
+func nestValue(path []string, value interface{}) interface{} {
+	for i := len(path) - 1; i >= 0; i-- {
+		value = map[string]interface{}{path[i]: value}
+	}
+	return value
+}
+
+func splice(data []byte, start, end int, text []byte) []byte {
+	out := make([]byte, 0, len(data)-(end-start)+len(text))
+	out = append(out, data[:start]...)
+	out = append(out, text...)
+	return append(out, data[end:]...)
+}
+
+// jsoncDocument is the structure of a JSONC document: byte spans of its
+// values, found by parsing the comment-stripped text
+type jsoncDocument struct {
+	data     []byte
+	stripped []byte // comments and trailing commas blanked out
+	comments []byte // comments blanked out, trailing commas kept
+	root     *jsoncValue
+	indent   string
+}
+
+type jsoncValue struct {
+	start, end int
+	object     bool
+	members    []jsoncMember
+}
+
+type jsoncMember struct {
+	key      string
+	keyStart int
+	value    jsoncValue
+}
+
+func (v *jsoncValue) member(key string) *jsoncMember {
+	// Like encoding/json, the last duplicate wins
+	for i := len(v.members) - 1; i >= 0; i-- {
+		if v.members[i].key == key {
+			return &v.members[i]
+		}
+	}
+	return nil
+}
+
+func parseJSONCDocument(data []byte) (*jsoncDocument, error) {
+	doc := &jsoncDocument{
+		data:     data,
+		stripped: StripJSONC(data),
+		comments: stripJSONCComments(data),
+	}
+	start := skipSpace(doc.stripped, 0)
+	if start == len(doc.stripped) {
+		doc.indent = "  "
+		return doc, nil
+	}
+	if !json.Valid(doc.stripped) {
+		var probe interface{}
+		return nil, jsonSyntaxError(data, json.Unmarshal(doc.stripped, &probe))
+	}
+	if doc.stripped[start] != '{' {
+		return nil, fmt.Errorf("top level of JSONC document is not an object")
+	}
+	root, _ := doc.parseValue(start)
+	doc.root = &root
+	doc.indent = doc.detectIndent()
+	return doc, nil
+}
+
+// parseValue parses the value at pos in the stripped text, which is known
+// to be valid JSON, and returns it with the offset just past it
+func (d *jsoncDocument) parseValue(pos int) (jsoncValue, int) {
+	s := d.stripped
+	pos = skipSpace(s, pos)
+	v := jsoncValue{start: pos}
+	switch s[pos] {
+	case '{':
+		v.object = true
+		pos = skipSpace(s, pos+1)
+		for s[pos] != '}' {
+			keyStart := pos
+			pos = skipString(s, pos)
+			var key string
+			_ = json.Unmarshal(s[keyStart:pos], &key)
+			pos = skipSpace(s, pos)
+			var value jsoncValue
+			value, pos = d.parseValue(pos + 1) // past ':'
+			v.members = append(v.members, jsoncMember{key: key, keyStart: keyStart, value: value})
+			pos = skipSpace(s, pos)
+			if s[pos] == ',' {
+				pos = skipSpace(s, pos+1)
+			}
+		}
+		pos++
+	case '[':
+		pos = skipSpace(s, pos+1)
+		for s[pos] != ']' {
+			_, pos = d.parseValue(pos)
+			pos = skipSpace(s, pos)
+			if s[pos] == ',' {
+				pos = skipSpace(s, pos+1)
+			}
+		}
+		pos++
+	case '"':
+		pos = skipString(s, pos)
+	default:
+		for pos < len(s) && !strings.ContainsRune(",}] \t\r\n", rune(s[pos])) {
+			pos++
+		}
+	}
+	v.end = pos
+	return v, pos
+}
+
+// insert adds a member to obj after its last member
+func (d *jsoncDocument) insert(obj *jsoncValue, key string, value interface{}) ([]byte, error) {
+	closing := obj.end - 1
+	closingLineStart := lineStart(d.data, closing)
+	if !d.multiline(obj) && len(obj.members) > 0 {
+		// {"a": 1} stays on one line
+		encoded, err := d.marshalCompact(value)
+		if err != nil {
+			return nil, err
+		}
+		keyText, _ := json.Marshal(key)
+		last := obj.members[len(obj.members)-1]
+		text := fmt.Sprintf(", %s: %s", keyText, encoded)
+		return splice(d.data, last.value.end, last.value.end, []byte(text)), nil
+	}
+
+	indent := d.lineIndent(obj.start) + d.indent
+	if len(obj.members) > 0 {
+		indent = d.lineIndent(obj.members[0].keyStart)
+	}
+	encoded, err := d.marshal(value, indent)
+	if err != nil {
+		return nil, err
+	}
+	keyText, _ := json.Marshal(key)
+	member := fmt.Sprintf("%s%s: %s", indent, keyText, encoded)
+
+	if len(obj.members) == 0 {
+		// Keep whatever is inside the braces, such as comments
+		inner := strings.TrimRight(string(d.data[obj.start+1:closing]), " \t")
+		if strings.TrimSpace(inner) == "" {
+			inner = ""
+		}
+		if inner != "" && !strings.HasSuffix(inner, "\n") {
+			inner += "\n"
+		}
+		if inner == "" {
+			inner = "\n"
+		}
+		text := "{" + inner + member + "\n" + d.lineIndent(obj.start) + "}"
+		return splice(d.data, obj.start, obj.end, []byte(text)), nil
+	}
+
+	last := obj.members[len(obj.members)-1]
+	comma := d.commaAfter(last.value.end, closing)
+	if comma >= 0 {
+		member += "," // the file uses trailing commas
+	}
+	at := closing
+	if isBlank(d.stripped[closingLineStart:closing]) {
+		at = closingLineStart
+		member += "\n"
+	} else {
+		member = "\n" + member + "\n" + d.lineIndent(closing)
+	}
+	out := splice(d.data, at, at, []byte(member))
+	if comma < 0 {
+		out = splice(out, last.value.end, last.value.end, []byte(","))
+	}
+	return out, nil
+}
+
+// remove deletes obj's member at index
+func (d *jsoncDocument) remove(obj *jsoncValue, index int) []byte {
+	member := obj.members[index]
+	closing := obj.end - 1
+	if len(obj.members) == 1 && isBlank(d.data[obj.start+1:member.keyStart]) &&
+		isBlank(bytes.TrimSpace(bytes.TrimPrefix(bytes.TrimSpace(d.data[member.value.end:closing]), []byte(",")))) {
+		// Nothing else, not even a comment, is inside the braces
+		return splice(d.data, obj.start, obj.end, []byte("{}"))
+	}
+	limit := closing
+	if index+1 < len(obj.members) {
+		limit = obj.members[index+1].keyStart
+	}
+	start, end := member.keyStart, member.value.end
+	comma := d.commaAfter(member.value.end, limit)
+	if comma >= 0 {
+		end = comma + 1
+	}
+
+	// Take the whole line when the member has it to itself, including a
+	// trailing comment
+	from := lineStart(d.data, start)
+	to := bytes.IndexByte(d.data[end:], '\n')
+	if to < 0 {
+		to = len(d.data)
+	} else {
+		to += end
+	}
+	wholeLine := isBlank(d.data[from:start]) && isBlank(d.stripped[end:to]) && to < closing
+	switch {
+	case wholeLine:
+		start, end = from, to+1
+	case comma < 0 && index > 0:
+		// {"a": 1, "b": 2} loses ", "b": 2"
+		return splice(d.data, obj.members[index-1].value.end, end, nil)
+	default:
+		for end < closing && (d.data[end] == ' ' || d.data[end] == '\t') {
+			end++
+		}
+	}
+
+	out := splice(d.data, start, end, nil)
+	if comma < 0 && index > 0 {
+		// The removed member was last without a trailing comma, so the
+		// one before it mustn't have one either
+		prev := obj.members[index-1]
+		if c := d.commaAfter(prev.value.end, member.keyStart); c >= 0 {
+			out = splice(out, c, c+1, nil)
+		}
+	}
+	return out
+}
+
+// commaAfter returns the offset of the comma between pos and limit, or -1
+func (d *jsoncDocument) commaAfter(pos, limit int) int {
+	for i := pos; i < limit; i++ {
+		switch d.comments[i] {
+		case ',':
+			return i
+		case ' ', '\t', '\r', '\n':
+		default:
+			return -1
+		}
+	}
+	return -1
+}
+
+// marshal encodes value as the document would, indenting continuation
+// lines by prefix
+func (d *jsoncDocument) marshal(value interface{}, prefix string) ([]byte, error) {
+	return encodeJSON(value, prefix, d.indent)
+}
+
+// marshalCompact encodes value on one line, spaced like {"a": [1, 2]}
+func (d *jsoncDocument) marshalCompact(value interface{}) ([]byte, error) {
+	encoded, err := encodeJSON(value, "", "")
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(encoded)+len(encoded)/4)
+	inString := false
+	for i := 0; i < len(encoded); i++ {
+		c := encoded[i]
+		out = append(out, c)
+		switch {
+		case inString && c == '\\':
+			i++
+			out = append(out, encoded[i])
+		case c == '"':
+			inString = !inString
+		case !inString && (c == ',' || c == ':'):
+			out = append(out, ' ')
+		}
+	}
+	return out, nil
+}
+
+// encodeJSON encodes value without escaping HTML characters, which has no
+// place in a config file
+func encodeJSON(value interface{}, prefix, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(prefix, indent)
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// multiline reports whether v spans more than one line
+func (d *jsoncDocument) multiline(v *jsoncValue) bool {
+	return bytes.IndexByte(d.data[v.start:v.end], '\n') >= 0
+}
+
+// inlineContainer reports whether v is an object or array written on one
+// line, which a replacement keeps on one line
+func (d *jsoncDocument) inlineContainer(v *jsoncValue) bool {
+	c := d.stripped[v.start]
+	return (c == '{' || c == '[') && !d.multiline(v)
+}
+
+// lineIndent returns the whitespace that starts the line containing pos
+func (d *jsoncDocument) lineIndent(pos int) string {
+	start := lineStart(d.data, pos)
+	end := start
+	for end < len(d.data) && (d.data[end] == ' ' || d.data[end] == '\t') {
+		end++
+	}
+	return string(d.data[start:end])
+}
+
+// detectIndent returns the indentation unit of the document: that of the
+// first member of the root object, or two spaces
+func (d *jsoncDocument) detectIndent() string {
+	if len(d.root.members) > 0 {
+		first := d.root.members[0].keyStart
+		if indent := d.lineIndent(first); indent != "" && isBlank(d.data[lineStart(d.data, first):first]) {
+			return strings.TrimPrefix(indent, d.lineIndent(d.root.start))
+		}
+	}
+	return "  "
+}
+
+func lineStart(data []byte, pos int) int {
+	return bytes.LastIndexByte(data[:pos], '\n') + 1
+}
+
+func isBlank(data []byte) bool {
+	return len(bytes.TrimSpace(data)) == 0
+}
+
+func skipSpace(data []byte, pos int) int {
+	for pos < len(data) {
+		switch data[pos] {
+		case ' ', '\t', '\r', '\n':
+			pos++
+		default:
+			return pos
+		}
+	}
+	return pos
+}
+
+// skipString returns the offset just past the string starting at pos
+func skipString(data []byte, pos int) int {
+	for i := pos + 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(data)
+}
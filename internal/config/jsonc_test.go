@@ -0,0 +1,302 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseJSONC(t *testing.T) {
+	data := []byte(`// settings
+{
+  "url": "https://example.com/a//b", /* not a comment inside strings */
+  "list": [1, 2,],
+  "quote": "say \"hi\" // still a string",
+}`)
+	got, err := ParseJSONC(data)
+	if err != nil {
+		t.Fatalf("ParseJSONC() error = %v", err)
+	}
+	want := map[string]interface{}{
+		"url":   "https://example.com/a//b",
+		"list":  []interface{}{json.Number("1"), json.Number("2")},
+		"quote": `say "hi" // still a string`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseJSONC() = %#v, want %#v", got, want)
+	}
+	if got, err := ParseJSONC([]byte("// nothing yet\n")); err != nil || len(got) != 0 {
+		t.Errorf("ParseJSONC(comment only) = %v, %v", got, err)
+	}
+}
+
+func TestParseJSONCErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		in         string
+		line, col  int
+		wantInText string
+	}{
+		{
+			name: "missing comma",
+			in: `{
+  // theme
+  "theme": "dark"
+  "mcpServers": {}
+}`,
+			line: 4, col: 3,
+			wantInText: "line 4, column 3",
+		},
+		{
+			name:       "bad literal after comment",
+			in:         "/* ü */ {\"a\": tru}",
+			line:       1,
+			col:        18,
+			wantInText: "invalid character '}' in literal true",
+		},
+		{
+			name:       "top level array",
+			in:         "[1, 2]",
+			line:       1,
+			col:        1,
+			wantInText: "expected an object, found array",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseJSONC([]byte(tt.in))
+			var syntax *SyntaxError
+			if !errors.As(err, &syntax) {
+				t.Fatalf("ParseJSONC() error = %v, want a *SyntaxError", err)
+			}
+			if syntax.Line != tt.line || syntax.Column != tt.col {
+				t.Errorf("position = %d:%d, want %d:%d", syntax.Line, syntax.Column, tt.line, tt.col)
+			}
+			if !strings.Contains(err.Error(), tt.wantInText) {
+				t.Errorf("error %q doesn't mention %q", err, tt.wantInText)
+			}
+		})
+	}
+}
+
+func TestReadConfigLenient(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "settings.json")
+	settings := `{
+  // Gemini CLI settings
+  "theme": "GitHub",
+  "mcpServers": {
+    "fetch": {"command": "uvx", "args": ["mcp-server-fetch",],},
+  },
+}
+`
+	if err := os.WriteFile(path, []byte(settings), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := ReadConfig(path)
+	if err != nil {
+		t.Fatalf("ReadConfig() error = %v", err)
+	}
+	if got := GetMCPServers(cfg)["fetch"]["command"]; got != "uvx" {
+		t.Errorf("fetch command = %v, want uvx", got)
+	}
+
+	broken := filepath.Join(dir, "mcp.json")
+	if err := os.WriteFile(broken, []byte("{\n  \"mcpServers\": {\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ReadConfig(broken)
+	if err == nil || !strings.HasPrefix(err.Error(), broken+":") {
+		t.Errorf("ReadConfig() error = %v, want it to start with the path", err)
+	}
+}
+
+func TestSetJSONC(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		path  []string
+		value interface{}
+		want  string
+	}{
+		{
+			name:  "empty document",
+			in:    "",
+			path:  []string{"servers", "a"},
+			value: map[string]interface{}{"command": "x"},
+			want:  "{\n  \"servers\": {\n    \"a\": {\n      \"command\": \"x\"\n    }\n  }\n}\n",
+		},
+		{
+			name: "append keeps comments and adds comma",
+			in: `{
+  // theme
+  "theme": "dark" // trailing note
+}`,
+			path:  []string{"servers"},
+			value: map[string]interface{}{},
+			want: `{
+  // theme
+  "theme": "dark", // trailing note
+  "servers": {}
+}`,
+		},
+		{
+			name: "append follows trailing commas",
+			in: `{
+	"servers": {
+		"a": 1,
+	},
+}`,
+			path:  []string{"servers", "b"},
+			value: 2,
+			want: `{
+	"servers": {
+		"a": 1,
+		"b": 2,
+	},
+}`,
+		},
+		{
+			name: "replace value in place",
+			in: `{
+  "servers": {"a": 1, "b": 2}, // inline
+}`,
+			path:  []string{"servers", "a"},
+			value: []string{"x"},
+			want: `{
+  "servers": {"a": ["x"], "b": 2}, // inline
+}`,
+		},
+		{
+			name: "fill empty object with comment",
+			in: `{
+  "servers": {
+    // none yet
+  }
+}`,
+			path:  []string{"servers", "a"},
+			value: true,
+			want: `{
+  "servers": {
+    // none yet
+    "a": true
+  }
+}`,
+		},
+		{
+			name:  "single line object",
+			in:    `{"a": 1}`,
+			path:  []string{"b"},
+			value: "<&>",
+			want:  `{"a": 1, "b": "<&>"}`,
+		},
+		{
+			name: "inline array stays inline",
+			in: `{
+  "args": ["-y", "server"]
+}`,
+			path:  []string{"args"},
+			value: []string{"-y", "server@latest", `a,"b": c`},
+			want: `{
+  "args": ["-y", "server@latest", "a,\"b\": c"]
+}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SetJSONC([]byte(tt.in), tt.path, tt.value)
+			if err != nil {
+				t.Fatalf("SetJSONC() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("SetJSONC() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeleteJSONC(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		path []string
+		want string
+	}{
+		{
+			name: "last member drops previous comma",
+			in: `{
+  "a": 1,
+  "b": {
+    "x": true
+  } // b
+}`,
+			path: []string{"b"},
+			want: `{
+  "a": 1
+}`,
+		},
+		{
+			name: "trailing commas stay",
+			in: `{
+  "a": 1,
+  "b": 2,
+}`,
+			path: []string{"b"},
+			want: `{
+  "a": 1,
+}`,
+		},
+		{
+			name: "first member",
+			in: `{
+  /* keep */
+  "a": 1,
+  "b": 2
+}`,
+			path: []string{"a"},
+			want: `{
+  /* keep */
+  "b": 2
+}`,
+		},
+		{
+			name: "inline",
+			in:   `{"a": 1, "b": 2}`,
+			path: []string{"b"},
+			want: `{"a": 1}`,
+		},
+		{
+			name: "only member",
+			in: `{
+  "servers": {
+    "a": {"command": "x"}
+  }
+}`,
+			path: []string{"servers", "a"},
+			want: `{
+  "servers": {}
+}`,
+		},
+		{
+			name: "missing",
+			in:   `{"a": 1}`,
+			path: []string{"servers", "b"},
+			want: `{"a": 1}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DeleteJSONC([]byte(tt.in), tt.path)
+			if err != nil {
+				t.Fatalf("DeleteJSONC() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("DeleteJSONC() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
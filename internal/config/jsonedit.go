@@ -0,0 +1,61 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+)
+
+// patchJSON returns data, a JSON or JSONC document that parses to old,
+// edited so that it parses to updated. Only members whose values differ
+// are touched, each with SetJSONC or DeleteJSONC, so the key order,
+// indentation and comments of the rest of the document come out byte for
+// byte. Objects present in both are patched member by member; any other
+// changed value is rewritten whole. New members go after the existing
+// ones, in key order.
+func patchJSON(data []byte, old, updated map[string]interface{}) ([]byte, error) {
+	return patchJSONObject(data, nil, old, updated)
+}
+
+func patchJSONObject(data []byte, path []string, old, updated map[string]interface{}) ([]byte, error) {
+	var err error
+	for _, key := range sortedKeys(old) {
+		if _, ok := updated[key]; ok {
+			continue
+		}
+		if data, err = DeleteJSONC(data, appendKey(path, key)); err != nil {
+			return nil, err
+		}
+	}
+	for _, key := range sortedKeys(updated) {
+		value := updated[key]
+		prev, existed := old[key]
+		prevObj, wasObj := prev.(map[string]interface{})
+		obj, isObj := value.(map[string]interface{})
+		switch {
+		case existed && wasObj && isObj:
+			data, err = patchJSONObject(data, appendKey(path, key), prevObj, obj)
+		case existed && reflect.DeepEqual(prev, value):
+			continue
+		default:
+			data, err = SetJSONC(data, appendKey(path, key), value)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// appendKey returns path with key added, leaving path's array alone
+func appendKey(path []string, key string) []string {
+	return append(path[:len(path):len(path)], key)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func servers(cfg map[string]interface{}) map[string]interface{} {
+	return EnsureMap(cfg, []string{"mcpServers"})
+}
+
+// assertGolden compares got with the golden file, or rewrites the file
+// when the tests run with -update
+func assertGolden(t *testing.T, golden string, got []byte) {
+	t.Helper()
+	if *updateGolden {
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s:\n%s", golden, got)
+	}
+}
+
+func TestUpdateDocumentGolden(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		mutate  func(cfg map[string]interface{})
+	}{
+		{
+			name:    "claude_add",
+			fixture: "claude.json",
+			mutate: func(cfg map[string]interface{}) {
+				servers(cfg)["context7"] = map[string]interface{}{
+					"type": "http",
+					"url":  "https://mcp.context7.com/mcp?a=1&b=2",
+				}
+			},
+		},
+		{
+			name:    "claude_update",
+			fixture: "claude.json",
+			mutate: func(cfg map[string]interface{}) {
+				servers(cfg)["github"] = map[string]interface{}{
+					"type":    "stdio",
+					"command": "npx",
+					"args":    []interface{}{"-y", "@modelcontextprotocol/server-github@latest"},
+					"env":     map[string]interface{}{"GITHUB_TOKEN": "ghp_xxx"},
+					"timeout": json.Number("30000"),
+				}
+			},
+		},
+		{
+			name:    "claude_remove",
+			fixture: "claude.json",
+			mutate: func(cfg map[string]interface{}) {
+				delete(servers(cfg), "github")
+			},
+		},
+		{
+			name:    "cursor_remove_first",
+			fixture: "cursor.json",
+			mutate: func(cfg map[string]interface{}) {
+				delete(servers(cfg), "postgres")
+			},
+		},
+		{
+			name:    "cursor_update_inline",
+			fixture: "cursor.json",
+			mutate: func(cfg map[string]interface{}) {
+				sentry := servers(cfg)["sentry"].(map[string]interface{})
+				sentry["headers"].(map[string]interface{})["Authorization"] = "Bearer xyz"
+			},
+		},
+		{
+			name:    "gemini_add_container",
+			fixture: "gemini.json",
+			mutate: func(cfg map[string]interface{}) {
+				servers(cfg)["fetch"] = map[string]interface{}{"command": "uvx", "args": []interface{}{"mcp-server-fetch"}}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in, err := os.ReadFile(filepath.Join("testdata", "jsonedit", tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(t.TempDir(), tt.fixture)
+			if err := os.WriteFile(path, in, 0644); err != nil {
+				t.Fatal(err)
+			}
+			want, err := ReadConfig(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.mutate(want)
+
+			err = UpdateDocument(path, FormatJSON, func(cfg map[string]interface{}) error {
+				tt.mutate(cfg)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("UpdateDocument() error = %v", err)
+			}
+			out, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assertGolden(t, filepath.Join("testdata", "jsonedit", tt.name+".golden.json"), out)
+
+			// The edit means the same as the mutation did to the map
+			if got, err := ParseJSONC(out); err != nil || !reflect.DeepEqual(got, want) {
+				t.Errorf("edited config = %v, %v; want %v", got, err, want)
+			}
+
+			// Everything outside mcpServers is byte-identical
+			inRest, err := DeleteJSONC(in, []string{"mcpServers"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			outRest, err := DeleteJSONC(out, []string{"mcpServers"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(inRest, outRest) {
+				t.Errorf("content outside mcpServers changed:\n%s", outRest)
+			}
+		})
+	}
+}
+
+func TestUpdateDocumentUnchangedKeepsBytes(t *testing.T) {
+	in, err := os.ReadFile(filepath.Join("testdata", "jsonedit", "claude.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "claude.json")
+	if err := os.WriteFile(path, in, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Re-setting a server to what it already is writes nothing new
+	err = UpdateDocument(path, FormatJSON, func(cfg map[string]interface{}) error {
+		github := servers(cfg)["github"].(map[string]interface{})
+		servers(cfg)["github"] = map[string]interface{}{
+			"type": github["type"], "command": github["command"], "args": github["args"], "env": github["env"],
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateDocument() error = %v", err)
+	}
+	if out, _ := os.ReadFile(path); !bytes.Equal(out, in) {
+		t.Errorf("file changed:\n%s", out)
+	}
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// jsonNumbers are the numbers in testdata/numbers/claude.json, which
+// float64 would round or rewrite in another form
+var jsonNumbers = []string{
+	"1729150000123",
+	"123456789012345678901234567890",
+	"9007199254740993",
+	"0.30000000000000004",
+	"1.50",
+	"1e-7",
+	"6.02e23",
+	"-0",
+}
+
+func copyFixture(t *testing.T, name string) (string, []byte) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "numbers", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path, data
+}
+
+func TestJSONNumbersSurvive(t *testing.T) {
+	path, _ := copyFixture(t, "claude.json")
+	cfg, err := ReadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg["userID"]; got != json.Number("123456789012345678901234567890") {
+		t.Errorf("userID = %#v, want the exact json.Number", got)
+	}
+
+	// An install copies the existing server, numbers and all, under a new
+	// name, so the copy is encoded afresh
+	err = UpdateDocument(path, FormatJSON, func(cfg map[string]interface{}) error {
+		servers := EnsureMap(cfg, []string{"mcpServers"})
+		servers["slow-copy"] = servers["slow"]
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateDocument() error = %v", err)
+	}
+	out, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range jsonNumbers {
+		if !strings.Contains(string(out), ": "+n+",") && !strings.Contains(string(out), ": "+n+"\n") {
+			t.Errorf("%s was rewritten:\n%s", n, out)
+		}
+	}
+	if strings.Count(string(out), `"timeout": 9007199254740993`) != 2 {
+		t.Errorf("the copied server lost its timeout:\n%s", out)
+	}
+
+	// Encoding the whole config afresh keeps every number too
+	if err := WriteConfig(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	out, _ = os.ReadFile(path)
+	for _, n := range jsonNumbers {
+		if !strings.Contains(string(out), ": "+n) {
+			t.Errorf("%s was rewritten by WriteConfig:\n%s", n, out)
+		}
+	}
+}
+
+func TestTOMLTypesSurvive(t *testing.T) {
+	path, in := copyFixture(t, "config.toml")
+	want, err := parseTOML(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = UpdateDocument(path, FormatTOML, func(cfg map[string]interface{}) error {
+		mcpServers(cfg)["docs"] = map[string]interface{}{"url": "https://docs.example.com/mcp"}
+		mcpServers(cfg)["slow"].(map[string]interface{})["startup_timeout_sec"] = int64(300)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateDocument() error = %v", err)
+	}
+	out, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(string(in), "\n") {
+		if line != "startup_timeout_sec = 120" && !strings.Contains(string(out), line) {
+			t.Errorf("line %q was rewritten", line)
+		}
+	}
+	if !strings.Contains(string(out), "startup_timeout_sec = 300\n") {
+		t.Errorf("startup_timeout_sec wasn't updated:\n%s", out)
+	}
+
+	// A value that is rewritten keeps its type and precision
+	for _, table := range []string{"", "notice", "mcp_servers.slow"} {
+		values := GetMap(want, SplitKeyPath(table))
+		for key, value := range values {
+			if _, ok := value.(map[string]interface{}); ok {
+				continue
+			}
+			encoded, err := encodeTOMLValue(value)
+			if err != nil {
+				t.Fatalf("encodeTOMLValue(%v) error = %v", value, err)
+			}
+			got, err := parseTOML([]byte("v = " + encoded))
+			if err != nil {
+				t.Fatalf("%s = %s doesn't parse: %v", key, encoded, err)
+			}
+			if !reflect.DeepEqual(got["v"], value) {
+				t.Errorf("%s = %s reads back as %#v, want %#v", key, encoded, got["v"], value)
+			}
+		}
+	}
+
+	// And so does encoding the whole config afresh
+	encoded, err := encodeDocument(nil, FormatTOML, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parseTOML(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("re-encoded config = %#v\nwant %#v", got, want)
+	}
+}
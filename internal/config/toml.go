@@ -2,26 +2,28 @@ package config
 
 import (
 	"bytes"
-	"os"
-	"path/filepath"
 
 	"github.com/pelletier/go-toml/v2"
 )
 
 // ReadTOMLConfig reads a TOML config file.
 func ReadTOMLConfig(path string) (map[string]interface{}, error) {
-	data, err := os.ReadFile(path)
+	data, err := readFile(path)
 	if err != nil {
 		return nil, err
 	}
+	cfg, err := parseTOML(data)
+	return cfg, inFile(err, path)
+}
 
+func parseTOML(data []byte) (map[string]interface{}, error) {
 	if len(bytes.TrimSpace(data)) == 0 {
 		return map[string]interface{}{}, nil
 	}
 
 	var cfg map[string]interface{}
 	if err := toml.Unmarshal(data, &cfg); err != nil {
-		return nil, err
+		return nil, tomlDecodeError(err)
 	}
 	if cfg == nil {
 		cfg = map[string]interface{}{}
@@ -31,11 +33,6 @@ func ReadTOMLConfig(path string) (map[string]interface{}, error) {
 
 // WriteTOMLConfig writes a TOML config file with pretty formatting.
 func WriteTOMLConfig(path string, cfg map[string]interface{}) error {
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return err
-	}
-
 	if cfg == nil {
 		cfg = map[string]interface{}{}
 	}
@@ -45,5 +42,5 @@ func WriteTOMLConfig(path string, cfg map[string]interface{}) error {
 		return err
 	}
 
-	return os.WriteFile(path, data, 0644)
+	return WriteFile(path, data)
 }
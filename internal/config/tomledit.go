@@ -0,0 +1,617 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// patchTOML returns data, a TOML document that parses to old, edited so
+// that it parses to updated. Like patchJSON it only touches what changed:
+// a changed key has its value rewritten on its own line, a removed table
+// loses its header, body and the comments just above the header, and a new
+// table is added after its last sibling ([mcp_servers.b] after
+// [mcp_servers.a]). Comments, blank lines and the order of everything else
+// are kept.
+func patchTOML(data []byte, old, updated map[string]interface{}) ([]byte, error) {
+	return patchTOMLTable(data, nil, old, updated)
+}
+
+func patchTOMLTable(data []byte, path []string, old, updated map[string]interface{}) ([]byte, error) {
+	var err error
+	for _, key := range sortedKeys(old) {
+		if _, ok := updated[key]; ok {
+			continue
+		}
+		if data, err = deleteTOML(data, appendKey(path, key)); err != nil {
+			return nil, err
+		}
+	}
+	for _, key := range sortedKeys(updated) {
+		value := updated[key]
+		prev, existed := old[key]
+		keyPath := appendKey(path, key)
+		prevTable, wasTable := asTOMLTable(prev)
+		table, isTable := asTOMLTable(value)
+		if existed && (reflect.DeepEqual(prev, value) || wasTable && isTable && reflect.DeepEqual(prevTable, table)) {
+			continue
+		}
+		if existed && wasTable && isTable {
+			doc, err := parseTOMLDocument(data)
+			if err != nil {
+				return nil, err
+			}
+			if doc.entry(keyPath) == nil {
+				// A table of its own rather than an inline value
+				if data, err = patchTOMLTable(data, keyPath, prevTable, table); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+		if data, err = setTOML(data, keyPath, value); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// setTOML sets the key at path to value. An existing key = value line has
+// its value replaced in place; anything else at path is removed and the
+// value is added as a new key or table.
+func setTOML(data []byte, path []string, value interface{}) ([]byte, error) {
+	doc, err := parseTOMLDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	if e := doc.entry(path); e != nil {
+		encoded, err := encodeTOMLValue(value)
+		if err != nil {
+			return nil, err
+		}
+		return splice(data, e.valueStart, e.valueEnd, []byte(encoded)), nil
+	}
+	if doc.defines(path) {
+		if data, err = deleteTOML(data, path); err != nil {
+			return nil, err
+		}
+		if doc, err = parseTOMLDocument(data); err != nil {
+			return nil, err
+		}
+	}
+	if table, ok := asTOMLTable(value); ok {
+		return doc.insertTable(path, table)
+	}
+	return doc.insertEntry(path, value)
+}
+
+// deleteTOML removes the key or table at path along with its sub-tables
+func deleteTOML(data []byte, path []string) ([]byte, error) {
+	doc, err := parseTOMLDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	type span struct{ start, end int }
+	var spans []span
+	for _, t := range doc.tables {
+		if t.header >= 0 && hasKeyPrefix(t.path, path) {
+			spans = append(spans, span{doc.commentStart(t.header), t.end})
+		}
+	}
+	for _, e := range doc.entries {
+		if hasKeyPrefix(e.path, path) {
+			spans = append(spans, span{e.start, e.end})
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	// Merge the lines of a removed table with the table, then cut from the
+	// end so that earlier offsets stay valid
+	var merged []span
+	for _, s := range spans {
+		if n := len(merged); n > 0 && s.start < merged[n-1].end {
+			merged[n-1].end = max(merged[n-1].end, s.end)
+			continue
+		}
+		merged = append(merged, s)
+	}
+	for i := len(merged) - 1; i >= 0; i-- {
+		s := merged[i]
+		if s.end == len(data) {
+			// Don't leave the blank lines that separated it at the end
+			for s.start > 0 && isBlank(data[lineStart(data, s.start-1):s.start]) {
+				s.start = lineStart(data, s.start-1)
+			}
+		}
+		data = splice(data, s.start, s.end, nil)
+	}
+	return data, nil
+}
+
+// tomlDocument is the layout of a TOML document: where its tables and its
+// key = value lines are
+type tomlDocument struct {
+	data    []byte
+	tables  []tomlTable
+	entries []tomlEntry
+}
+
+// tomlTable is the root table (header -1) or a [table] or [[array]] with
+// its body, up to the comments above the next header
+type tomlTable struct {
+	path   []string
+	array  bool
+	header int // offset of the header line
+	end    int
+}
+
+// tomlEntry is a key = value line; path is the full key path, including
+// the table and any dotted key
+type tomlEntry struct {
+	path                 []string
+	start, end           int // the whole line, including its newline
+	valueStart, valueEnd int
+}
+
+func parseTOMLDocument(data []byte) (*tomlDocument, error) {
+	doc := &tomlDocument{data: data}
+	doc.tables = append(doc.tables, tomlTable{header: -1})
+	table := &doc.tables[0]
+	pos := 0
+	for pos < len(data) {
+		start := pos
+		pos = skipTOMLSpace(data, pos)
+		switch {
+		case pos == len(data):
+		case data[pos] == '\n' || data[pos] == '\r' || data[pos] == '#':
+		case data[pos] == '[':
+			array := pos+1 < len(data) && data[pos+1] == '['
+			open := 1
+			if array {
+				open = 2
+			}
+			path, next, err := parseTOMLKey(data, pos+open)
+			if err != nil {
+				return nil, err
+			}
+			if next+open > len(data) || data[next] != ']' || (array && data[next+1] != ']') {
+				return nil, tomlSyntaxError(data, next)
+			}
+			pos = next + open
+			table.end = doc.commentStart(start)
+			doc.tables = append(doc.tables, tomlTable{path: path, array: array, header: start})
+			table = &doc.tables[len(doc.tables)-1]
+		default:
+			key, next, err := parseTOMLKey(data, pos)
+			if err != nil {
+				return nil, err
+			}
+			if next >= len(data) || data[next] != '=' {
+				return nil, tomlSyntaxError(data, next)
+			}
+			valueStart := skipTOMLSpace(data, next+1)
+			valueEnd := skipTOMLValue(data, valueStart)
+			pos = valueEnd
+			path := append(append([]string(nil), table.path...), key...)
+			doc.entries = append(doc.entries, tomlEntry{path: path, start: start, valueStart: valueStart, valueEnd: valueEnd})
+		}
+		// The rest of the line is blank or a comment
+		if end := bytes.IndexByte(data[pos:], '\n'); end >= 0 {
+			pos += end + 1
+		} else {
+			pos = len(data)
+		}
+		if n := len(doc.entries); n > 0 && doc.entries[n-1].start == start {
+			doc.entries[n-1].end = pos
+		}
+	}
+	table.end = len(data)
+	return doc, nil
+}
+
+// entry returns the key = value line for path, or nil
+func (d *tomlDocument) entry(path []string) *tomlEntry {
+	for i := range d.entries {
+		if keyPathEqual(d.entries[i].path, path) {
+			return &d.entries[i]
+		}
+	}
+	return nil
+}
+
+// defines reports whether anything in the document is at or under path
+func (d *tomlDocument) defines(path []string) bool {
+	for _, t := range d.tables {
+		if t.header >= 0 && hasKeyPrefix(t.path, path) {
+			return true
+		}
+	}
+	for _, e := range d.entries {
+		if hasKeyPrefix(e.path, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// insertTable adds the table at path after the last table under the same
+// parent, or at the end of the document
+func (d *tomlDocument) insertTable(path []string, table map[string]interface{}) ([]byte, error) {
+	var text strings.Builder
+	if err := writeTOMLTable(&text, path, table); err != nil {
+		return nil, err
+	}
+	at := len(d.data)
+	if parent := path[:len(path)-1]; len(parent) > 0 {
+		for _, t := range d.tables {
+			if t.header >= 0 && hasKeyPrefix(t.path, parent) {
+				at = t.end
+			}
+		}
+	}
+	return d.insertBlock(at, text.String()), nil
+}
+
+// insertEntry adds a key = value line to the table that holds path
+func (d *tomlDocument) insertEntry(path []string, value interface{}) ([]byte, error) {
+	encoded, err := encodeTOMLValue(value)
+	if err != nil {
+		return nil, err
+	}
+	parent, key := path[:len(path)-1], path[len(path)-1]
+	line := encodeTOMLKey(key) + " = " + encoded + "\n"
+
+	for _, t := range d.tables {
+		if t.array || !keyPathEqual(t.path, parent) {
+			continue
+		}
+		at := -1
+		for _, e := range d.entries {
+			if e.start > t.header && e.start < t.end {
+				at = e.end
+			}
+		}
+		if at < 0 {
+			if t.header < 0 {
+				// The first root key goes before the first table
+				return d.insertBlock(t.end, line), nil
+			}
+			at = lineEnd(d.data, t.header)
+		}
+		if at > 0 && d.data[at-1] != '\n' {
+			line = "\n" + line
+		}
+		return splice(d.data, at, at, []byte(line)), nil
+	}
+	// The parent is only implied by its sub-tables, or missing
+	return d.insertTable(parent, map[string]interface{}{key: value})
+}
+
+// insertBlock adds text at offset at, separated from what is around it by
+// a blank line
+func (d *tomlDocument) insertBlock(at int, text string) []byte {
+	before := d.data[:at]
+	switch {
+	case len(before) == 0:
+	case bytes.HasSuffix(before, []byte("\n\n")):
+	case bytes.HasSuffix(before, []byte("\n")):
+		text = "\n" + text
+	default:
+		text = "\n\n" + text
+	}
+	if at < len(d.data) {
+		text += "\n"
+	}
+	return splice(d.data, at, at, []byte(text))
+}
+
+// commentStart returns the offset of the comment lines directly above the
+// line at pos, or pos if there are none
+func (d *tomlDocument) commentStart(pos int) int {
+	for pos > 0 {
+		prev := lineStart(d.data, pos-1)
+		line := bytes.TrimSpace(d.data[prev:pos])
+		if len(line) == 0 || line[0] != '#' {
+			break
+		}
+		pos = prev
+	}
+	return pos
+}
+
+// writeTOMLTable writes the table at path as a [header] and its keys,
+// followed by its sub-tables. A table with only sub-tables, such as
+// mcp_servers, is left implied by theirs.
+func writeTOMLTable(b *strings.Builder, path []string, table map[string]interface{}) error {
+	var keys, subTables []string
+	for _, key := range sortedKeys(table) {
+		if _, ok := asTOMLTable(table[key]); ok {
+			subTables = append(subTables, key)
+		} else {
+			keys = append(keys, key)
+		}
+	}
+	header := len(keys) > 0 || len(subTables) == 0
+	if header {
+		encoded := make([]string, len(path))
+		for i, key := range path {
+			encoded[i] = encodeTOMLKey(key)
+		}
+		fmt.Fprintf(b, "[%s]\n", strings.Join(encoded, "."))
+	}
+	for _, key := range keys {
+		encoded, err := encodeTOMLValue(table[key])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "%s = %s\n", encodeTOMLKey(key), encoded)
+	}
+	for i, key := range subTables {
+		if header || i > 0 {
+			b.WriteString("\n")
+		}
+		sub, _ := asTOMLTable(table[key])
+		if err := writeTOMLTable(b, appendKey(path, key), sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeTOMLValue encodes value as it appears after key =, with tables
+// inline and strings in double quotes
+func encodeTOMLValue(value interface{}) (string, error) {
+	if s, ok := value.(string); ok {
+		return encodeTOMLString(s), nil
+	}
+	if v, ok := asTOMLTable(value); ok {
+		parts := make([]string, 0, len(v))
+		for _, key := range sortedKeys(v) {
+			encoded, err := encodeTOMLValue(v[key])
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, encodeTOMLKey(key)+" = "+encoded)
+		}
+		if len(parts) == 0 {
+			return "{}", nil
+		}
+		return "{ " + strings.Join(parts, ", ") + " }", nil
+	}
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		parts := make([]string, rv.Len())
+		for i := range parts {
+			encoded, err := encodeTOMLValue(rv.Index(i).Interface())
+			if err != nil {
+				return "", err
+			}
+			parts[i] = encoded
+		}
+		return "[" + strings.Join(parts, ", ") + "]", nil
+	}
+	encoded, err := toml.Marshal(map[string]interface{}{"v": value})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(strings.TrimPrefix(string(encoded), "v = ")), nil
+}
+
+// asTOMLTable returns value as a table if it is a map with string keys, which
+// includes the map[string]string env and headers the agents build
+func asTOMLTable(value interface{}) (map[string]interface{}, bool) {
+	if table, ok := value.(map[string]interface{}); ok {
+		return table, true
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+	table := make(map[string]interface{}, rv.Len())
+	for iter := rv.MapRange(); iter.Next(); {
+		table[iter.Key().String()] = iter.Value().Interface()
+	}
+	return table, true
+}
+
+// encodeTOMLString encodes s as a TOML basic string
+func encodeTOMLString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f || r == utf8.RuneError {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// encodeTOMLKey leaves a bare key as it is and quotes any other
+func encodeTOMLKey(key string) string {
+	if key == "" {
+		return `""`
+	}
+	for _, r := range key {
+		if !isBareKeyChar(r) {
+			return encodeTOMLString(key)
+		}
+	}
+	return key
+}
+
+func isBareKeyChar(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-'
+}
+
+// parseTOMLKey parses a dotted key such as a."b.c".d starting at pos and
+// returns the offset after it and the spaces that follow
+func parseTOMLKey(data []byte, pos int) ([]string, int, error) {
+	var path []string
+	for {
+		pos = skipTOMLSpace(data, pos)
+		if pos >= len(data) {
+			return nil, pos, tomlSyntaxError(data, pos)
+		}
+		switch data[pos] {
+		case '\'':
+			end := skipTOMLValue(data, pos)
+			path = append(path, string(data[pos+1:end-1]))
+			pos = end
+		case '"':
+			end := skipTOMLValue(data, pos)
+			key, err := strconv.Unquote(string(data[pos:end]))
+			if err != nil {
+				return nil, pos, tomlSyntaxError(data, pos)
+			}
+			path = append(path, key)
+			pos = end
+		default:
+			end := pos
+			for end < len(data) && isBareKeyChar(rune(data[end])) {
+				end++
+			}
+			if end == pos {
+				return nil, pos, tomlSyntaxError(data, pos)
+			}
+			path = append(path, string(data[pos:end]))
+			pos = end
+		}
+		pos = skipTOMLSpace(data, pos)
+		if pos >= len(data) || data[pos] != '.' {
+			return path, pos, nil
+		}
+		pos++
+	}
+}
+
+// skipTOMLValue returns the offset just past the value starting at pos
+func skipTOMLValue(data []byte, pos int) int {
+	if pos >= len(data) {
+		return pos
+	}
+	switch data[pos] {
+	case '"', '\'':
+		quote := data[pos]
+		if bytes.HasPrefix(data[pos:], []byte{quote, quote, quote}) {
+			i := pos + 3
+			for i < len(data) {
+				if quote == '"' && data[i] == '\\' {
+					i += 2
+					continue
+				}
+				if bytes.HasPrefix(data[i:], []byte{quote, quote, quote}) {
+					// Up to two more quotes may end the string's content
+					i += 3
+					for n := 0; n < 2 && i < len(data) && data[i] == quote; n++ {
+						i++
+					}
+					return i
+				}
+				i++
+			}
+			return len(data)
+		}
+		for i := pos + 1; i < len(data); i++ {
+			switch {
+			case quote == '"' && data[i] == '\\':
+				i++
+			case data[i] == quote || data[i] == '\n':
+				return i + 1
+			}
+		}
+		return len(data)
+	case '[', '{':
+		depth := 0
+		for i := pos; i < len(data); {
+			switch data[i] {
+			case '[', '{':
+				depth++
+			case ']', '}':
+				depth--
+				if depth == 0 {
+					return i + 1
+				}
+			case '"', '\'':
+				i = skipTOMLValue(data, i)
+				continue
+			case '#':
+				for i < len(data) && data[i] != '\n' {
+					i++
+				}
+				continue
+			}
+			i++
+		}
+		return len(data)
+	}
+	end := pos
+	for end < len(data) && !strings.ContainsRune(" \t\r\n#,]}", rune(data[end])) {
+		end++
+	}
+	// A date and time may be separated by a space: 1979-05-27 07:32:00
+	if end-pos == 10 && data[pos+4] == '-' && end+1 < len(data) && data[end] == ' ' && data[end+1] >= '0' && data[end+1] <= '9' {
+		return skipTOMLValue(data, end+1)
+	}
+	return end
+}
+
+func skipTOMLSpace(data []byte, pos int) int {
+	for pos < len(data) && (data[pos] == ' ' || data[pos] == '\t') {
+		pos++
+	}
+	return pos
+}
+
+// lineEnd returns the offset just past the newline ending the line at pos
+func lineEnd(data []byte, pos int) int {
+	if end := bytes.IndexByte(data[pos:], '\n'); end >= 0 {
+		return pos + end + 1
+	}
+	return len(data)
+}
+
+func tomlSyntaxError(data []byte, pos int) error {
+	line, col := position(data, pos)
+	return &SyntaxError{Line: line, Column: col, Err: errors.New("toml: unexpected content")}
+}
+
+func hasKeyPrefix(path, prefix []string) bool {
+	return len(path) >= len(prefix) && keyPathEqual(path[:len(prefix)], prefix)
+}
+
+func keyPathEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
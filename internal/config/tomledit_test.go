@@ -0,0 +1,208 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func mcpServers(cfg map[string]interface{}) map[string]interface{} {
+	return EnsureMap(cfg, []string{"mcp_servers"})
+}
+
+func TestUpdateDocumentTOMLGolden(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		mutate  func(cfg map[string]interface{})
+	}{
+		{
+			name:    "codex_add",
+			fixture: "codex.toml",
+			mutate: func(cfg map[string]interface{}) {
+				mcpServers(cfg)["sentry"] = map[string]interface{}{
+					"command": "npx",
+					"args":    []interface{}{"-y", "@sentry/mcp-server"},
+					"env":     map[string]interface{}{"SENTRY_ACCESS_TOKEN": "sntrys_xxx"},
+				}
+				mcpServers(cfg)["context7"] = map[string]interface{}{
+					"url":          "https://mcp.context7.com/mcp",
+					"http_headers": map[string]interface{}{"CONTEXT7_API_KEY": "ctx7_\"quoted\""},
+				}
+			},
+		},
+		{
+			name:    "codex_update",
+			fixture: "codex.toml",
+			mutate: func(cfg map[string]interface{}) {
+				github := mcpServers(cfg)["github"].(map[string]interface{})
+				github["args"] = []interface{}{"-y", "@modelcontextprotocol/server-github@latest"}
+				github["cwd"] = "/Users/dev/src"
+				github["env"].(map[string]interface{})["GITHUB_PERSONAL_ACCESS_TOKEN"] = "ghp_yyy"
+				github["env"].(map[string]interface{})["GITHUB_TOOLSETS"] = "repos,issues"
+			},
+		},
+		{
+			name:    "codex_remove",
+			fixture: "codex.toml",
+			mutate: func(cfg map[string]interface{}) {
+				delete(mcpServers(cfg), "staging-db")
+				delete(mcpServers(cfg)["github"].(map[string]interface{}), "startup_timeout_sec")
+			},
+		},
+		{
+			name:    "codex_remove_all",
+			fixture: "codex.toml",
+			mutate: func(cfg map[string]interface{}) {
+				delete(mcpServers(cfg), "github")
+				delete(mcpServers(cfg), "staging-db")
+			},
+		},
+		{
+			name:    "minimal_add",
+			fixture: "minimal.toml",
+			mutate: func(cfg map[string]interface{}) {
+				mcpServers(cfg)["fetch"] = map[string]interface{}{"command": "uvx", "args": []interface{}{"mcp-server-fetch"}}
+				cfg["approval_policy"] = "never"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in, err := os.ReadFile(filepath.Join("testdata", "tomledit", tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(t.TempDir(), "config.toml")
+			if err := os.WriteFile(path, in, 0644); err != nil {
+				t.Fatal(err)
+			}
+			want, err := parseTOML(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.mutate(want)
+
+			err = UpdateDocument(path, FormatTOML, func(cfg map[string]interface{}) error {
+				tt.mutate(cfg)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("UpdateDocument() error = %v", err)
+			}
+			out, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertGolden(t, filepath.Join("testdata", "tomledit", tt.name+".golden.toml"), out)
+
+			got, err := parseTOML(out)
+			if err != nil {
+				t.Fatalf("edited config doesn't parse: %v", err)
+			}
+			if !reflect.DeepEqual(dropEmptyTables(got), dropEmptyTables(want)) {
+				t.Errorf("edited config = %v, want %v", got, want)
+			}
+			// The comments of the fixture all survive, apart from the one
+			// above a removed server
+			for _, line := range strings.Split(string(in), "\n") {
+				if strings.HasPrefix(line, "# ") && line != "# Needs the staging VPN." && !strings.Contains(string(out), line) {
+					t.Errorf("comment %q was lost", line)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateDocumentTOMLNewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	err := UpdateDocument(path, FormatTOML, func(cfg map[string]interface{}) error {
+		mcpServers(cfg)["docs"] = map[string]interface{}{"url": "https://docs.example.com/mcp", "startup_timeout_sec": int64(30)}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateDocument() error = %v", err)
+	}
+	want := `[mcp_servers.docs]
+startup_timeout_sec = 30
+url = "https://docs.example.com/mcp"
+`
+	if out, _ := os.ReadFile(path); string(out) != want {
+		t.Errorf("new config =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestPatchTOMLInlineAndQuotedKeys(t *testing.T) {
+	in := `[mcp_servers."my.server"]
+command = "node" # local build
+env = { TOKEN = "a", MODE = "dev" }
+`
+	old, err := parseTOML([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated, _ := parseTOML([]byte(in))
+	server := mcpServers(updated)["my.server"].(map[string]interface{})
+	server["env"].(map[string]interface{})["TOKEN"] = "b"
+	server["command"] = "bun"
+
+	out, err := patchTOML([]byte(in), old, updated)
+	if err != nil {
+		t.Fatalf("patchTOML() error = %v", err)
+	}
+	want := `[mcp_servers."my.server"]
+command = "bun" # local build
+env = { MODE = "dev", TOKEN = "b" }
+`
+	if string(out) != want {
+		t.Errorf("patchTOML() =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestUpdateDocumentTOMLStringMaps(t *testing.T) {
+	in := `# c1
+model = "o3"  # inline
+
+[mcp_servers.a]
+command = "a"
+
+[projects."/path"]
+trust_level = "trusted"
+`
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(in), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// The agent translators build env and headers as map[string]string
+	err := UpdateDocument(path, FormatTOML, func(cfg map[string]interface{}) error {
+		mcpServers(cfg)["b"] = map[string]interface{}{
+			"command": "npx",
+			"env":     map[string]string{"TOKEN": "x"},
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateDocument() error = %v", err)
+	}
+	want := `# c1
+model = "o3"  # inline
+
+[mcp_servers.a]
+command = "a"
+
+[mcp_servers.b]
+command = "npx"
+
+[mcp_servers.b.env]
+TOKEN = "x"
+
+[projects."/path"]
+trust_level = "trusted"
+`
+	if out, _ := os.ReadFile(path); string(out) != want {
+		t.Errorf("edited config =\n%s\nwant\n%s", out, want)
+	}
+}
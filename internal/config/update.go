@@ -0,0 +1,153 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/agentsdance/agentx/internal/backup"
+	"github.com/agentsdance/agentx/internal/plan"
+	"github.com/agentsdance/agentx/internal/safefile"
+)
+
+// updateAttempts bounds how often UpdateFile re-applies an edit to a file
+// that keeps changing under it
+const updateAttempts = 5
+
+// ErrConflict is returned by UpdateFile when the file changed under every
+// attempt to update it
+var ErrConflict = errors.New("the file kept changing while agentx was updating it")
+
+// errChanged aborts a write whose file changed since it was read
+var errChanged = errors.New("file changed since it was read")
+
+// UpdateFile changes the file at path with a locked read-modify-write.
+// edit gets the current content (nil when the file doesn't exist) and
+// returns the new content; if that is the content it got, nothing is
+// written, and neither is a missing file for which edit returns nil. edit
+// may run more than once, so it must not have side effects.
+//
+// An advisory lock next to the file keeps other agentx processes (the CLI
+// and the TUI, say) from updating it at the same time. Agents such as
+// Claude Code don't take that lock and may rewrite their config while it
+// is held, so just before the new content is renamed into place the file
+// is read again: if it changed, edit is re-applied to the fresh content
+// instead of overwriting what the agent wrote. Missing parent directories
+// are created, a symlink is followed so the link itself is kept, and inside
+// a backup operation the old content is snapshotted first.
+//
+// During a dry run (see package plan) the edit is applied to the planned
+// content of the file and the result recorded in the plan instead.
+func UpdateFile(path string, edit func([]byte) ([]byte, error)) error {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	if p := plan.Active(); p != nil {
+		current, err := readIfExists(path)
+		if err != nil {
+			return err
+		}
+		updated, err := edit(current)
+		if err != nil || updated == nil && current == nil || current != nil && bytes.Equal(updated, current) {
+			return err
+		}
+		return p.WriteFile(path, updated)
+	}
+	if dir := filepath.Dir(path); !fileExists(dir) {
+		// Don't create directories for an edit that writes nothing
+		updated, err := edit(nil)
+		if err != nil || updated == nil {
+			return err
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	unlock, err := safefile.Lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for attempt := 0; attempt < updateAttempts; attempt++ {
+		current, err := readIfExists(path)
+		if err != nil {
+			return err
+		}
+		updated, err := edit(current)
+		if err != nil {
+			return err
+		}
+		if updated == nil && current == nil || current != nil && bytes.Equal(updated, current) {
+			return nil
+		}
+		if err := backup.Save(path); err != nil {
+			return err
+		}
+		err = safefile.Replace(path, updated, 0, func() error {
+			now, err := readIfExists(path)
+			if err != nil {
+				return err
+			}
+			if (now == nil) != (current == nil) || !bytes.Equal(now, current) {
+				return errChanged
+			}
+			return nil
+		})
+		if !errors.Is(err, errChanged) {
+			return err
+		}
+	}
+	return ErrConflict
+}
+
+// WriteFile replaces the file at path with data. It is UpdateFile with an
+// edit that ignores the current content, so it takes the same lock and
+// writes atomically in the same way.
+func WriteFile(path string, data []byte) error {
+	return UpdateFile(path, func([]byte) ([]byte, error) {
+		return data, nil
+	})
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// RemoveFile deletes the file at path, snapshotting it first inside a
+// backup operation. During a dry run the removal is only planned.
+func RemoveFile(path string) error {
+	if p := plan.Active(); p != nil {
+		return p.Remove(path)
+	}
+	if err := backup.Save(path); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// readFile reads the file at path, as the active dry run has left it
+func readFile(path string) ([]byte, error) {
+	if p := plan.Active(); p != nil {
+		return p.ReadFile(path)
+	}
+	return os.ReadFile(path)
+}
+
+// readIfExists returns the content of the file at path, or nil if there is
+// no such file
+func readIfExists(path string) ([]byte, error) {
+	data, err := readFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	return data, nil
+}
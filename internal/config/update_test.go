@@ -0,0 +1,253 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/agentsdance/agentx/internal/plan"
+)
+
+// writerEnvVar makes the test binary act as another agentx process that
+// adds keys to the file it names; see TestUpdateFileAcrossProcesses
+const writerEnvVar = "AGENTX_TEST_UPDATE_WRITER"
+
+func addServer(path, name string) error {
+	return UpdateDocument(path, FormatJSON, func(cfg map[string]interface{}) error {
+		EnsureMap(cfg, []string{"mcpServers"})[name] = map[string]interface{}{"command": name}
+		return nil
+	})
+}
+
+// assertServers checks that path holds exactly the servers named and that
+// no lock or temp file was left next to it
+func assertServers(t *testing.T, path string, names []string) {
+	t.Helper()
+	cfg, err := ReadDocument(path, FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	servers := GetMap(cfg, []string{"mcpServers"})
+	if len(servers) != len(names) {
+		t.Errorf("got %d servers, want %d", len(servers), len(names))
+	}
+	for _, name := range names {
+		if _, ok := servers[name]; !ok {
+			t.Errorf("server %s was lost", name)
+		}
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Name() != filepath.Base(path) {
+			t.Errorf("left %s behind", e.Name())
+		}
+	}
+}
+
+func TestUpdateFileConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp.json")
+	const writers, perWriter = 8, 10
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*perWriter)
+	var names []string
+	for w := 0; w < writers; w++ {
+		for i := 0; i < perWriter; i++ {
+			names = append(names, fmt.Sprintf("w%d-%d", w, i))
+		}
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				errs <- addServer(path, fmt.Sprintf("w%d-%d", w, i))
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("UpdateDocument() error = %v", err)
+		}
+	}
+	assertServers(t, path, names)
+}
+
+func TestUpdateFileAcrossProcesses(t *testing.T) {
+	if path := os.Getenv(writerEnvVar); path != "" {
+		for i := 0; i < 20; i++ {
+			if err := addServer(path, fmt.Sprintf("p%d-%d", os.Getpid(), i)); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		}
+		return
+	}
+
+	path := filepath.Join(t.TempDir(), "mcp.json")
+	var cmds []*exec.Cmd
+	for p := 0; p < 3; p++ {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestUpdateFileAcrossProcesses$")
+		cmd.Env = append(os.Environ(), writerEnvVar+"="+path)
+		if err := cmd.Start(); err != nil {
+			t.Skipf("cannot run the test binary: %v", err)
+		}
+		cmds = append(cmds, cmd)
+	}
+	var names []string
+	for i := 0; i < 20; i++ {
+		name := "self-" + strconv.Itoa(i)
+		if err := addServer(path, name); err != nil {
+			t.Fatalf("UpdateDocument() error = %v", err)
+		}
+		names = append(names, name)
+	}
+	for _, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Fatalf("writer process: %v", err)
+		}
+		for i := 0; i < 20; i++ {
+			names = append(names, fmt.Sprintf("p%d-%d", cmd.Process.Pid, i))
+		}
+	}
+	assertServers(t, path, names)
+}
+
+func TestUpdateFileReappliesEditAfterOutsideWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp.json")
+	if err := addServer(path, "github"); err != nil {
+		t.Fatal(err)
+	}
+
+	// An agent that doesn't take the lock rewrites its config between our
+	// read and our rename; the edit is re-applied on top of its change
+	calls := 0
+	err := UpdateDocument(path, FormatJSON, func(cfg map[string]interface{}) error {
+		calls++
+		if calls == 1 {
+			if err := os.WriteFile(path, []byte(`{"mcpServers": {"github": {}, "agent": {}}}`), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		EnsureMap(cfg, []string{"mcpServers"})["context7"] = map[string]interface{}{}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateDocument() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("mutate ran %d times, want 2", calls)
+	}
+	assertServers(t, path, []string{"github", "agent", "context7"})
+}
+
+func TestUpdateFileConflict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp.json")
+	calls := 0
+	err := UpdateFile(path, func([]byte) ([]byte, error) {
+		calls++
+		if err := os.WriteFile(path, []byte(strconv.Itoa(calls)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return []byte("agentx"), nil
+	})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("UpdateFile() error = %v, want %v", err, ErrConflict)
+	}
+	if calls != updateAttempts {
+		t.Errorf("edit ran %d times, want %d", calls, updateAttempts)
+	}
+	if data, _ := os.ReadFile(path); string(data) != strconv.Itoa(updateAttempts) {
+		t.Errorf("file = %q, want the last outside write kept", data)
+	}
+}
+
+func TestUpdateFileUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "mcp.json")
+	err := UpdateDocument(path, FormatJSON, func(cfg map[string]interface{}) error {
+		return ErrUnchanged
+	})
+	if err != nil {
+		t.Fatalf("UpdateDocument() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Dir(path)); !os.IsNotExist(err) {
+		t.Error("an update that changed nothing created the directory")
+	}
+}
+
+func TestUpdateDocumentNewFileKeepsURLs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp.json")
+	url := "https://mcp.example.com/sse?team=a&token=<id>"
+	err := UpdateDocument(path, FormatJSON, func(cfg map[string]interface{}) error {
+		EnsureMap(cfg, []string{"mcpServers"})["example"] = map[string]interface{}{"url": url}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateDocument() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), url) {
+		t.Errorf("new file = %s, want the URL written as is", data)
+	}
+}
+
+func TestUpdateFileDryRun(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mcp.json")
+	if err := os.WriteFile(path, []byte("{\n  // keep me\n  \"theme\": \"dark\"\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	created := filepath.Join(dir, "missing", "settings.json")
+
+	p := plan.Begin()
+	if err := addServer(path, "github"); err != nil {
+		t.Fatal(err)
+	}
+	// A second edit sees the first one
+	if err := addServer(path, "context7"); err != nil {
+		t.Fatal(err)
+	}
+	if err := addServer(created, "github"); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := ReadConfig(path)
+	p.End()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(GetMap(cfg, []string{"mcpServers"})) != 2 {
+		t.Errorf("ReadConfig() in the dry run = %v, want both servers", cfg)
+	}
+
+	// Nothing was written, locked or created
+	assertServers(t, path, nil)
+	if _, err := os.Stat(filepath.Dir(created)); !os.IsNotExist(err) {
+		t.Error("the dry run created a directory")
+	}
+
+	changes := p.Changes()
+	if len(changes) != 2 || changes[0].Path != path || changes[1].Path != created {
+		t.Fatalf("Changes() = %+v, want %s and %s", changes, path, created)
+	}
+	diff := changes[0].Diff()
+	for _, want := range []string{"   // keep me\n", "+  \"mcpServers\": {", "+    \"context7\": {"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff lacks %q:\n%s", want, diff)
+		}
+	}
+	if changes[1].Existed {
+		t.Errorf("%s is planned as existing", created)
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+const original = `{"mcpServers": {"github": {"command": "npx"}}, "oauthAccount": {"token": "secret"}}`
+
+func writeOriginal(t *testing.T, mode os.FileMode) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".claude.json")
+	if err := os.WriteFile(path, []byte(original), mode); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestWriteFileKeepsMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows has no Unix permission bits")
+	}
+	path := writeOriginal(t, 0600)
+	if err := WriteFile(path, []byte("{}")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode().Perm(); got != 0600 {
+		t.Errorf("mode = %v, want 0600", got)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "{}" {
+		t.Errorf("file = %q, want %q", data, "{}")
+	}
+}
+
+func TestWriteFileFollowsSymlink(t *testing.T) {
+	target := writeOriginal(t, 0644)
+	link := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	if err := WriteFile(link, []byte("{}")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("%s is no longer a symlink", link)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "{}" {
+		t.Errorf("target = %q, want %q", data, "{}")
+	}
+}
+
+func TestWriteFileCreatesDirectories(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a", "b", "config.toml")
+	if err := WriteFile(path, []byte("x = 1\n")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "x = 1\n" {
+		t.Errorf("file = %q", data)
+	}
+}
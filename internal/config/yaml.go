@@ -0,0 +1,206 @@
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReadYAMLConfig reads a YAML config file
+func ReadYAMLConfig(path string) (map[string]interface{}, error) {
+	data, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseYAML(data)
+}
+
+func parseYAML(data []byte) (map[string]interface{}, error) {
+	var cfg map[string]interface{}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		cfg = map[string]interface{}{}
+	}
+	return cfg, nil
+}
+
+// WriteYAMLConfig writes a YAML config file. When the file exists, cfg is
+// merged into it: keys keep their order, comments and quoting, values that
+// didn't change are left untouched and only new keys are appended (sorted).
+func WriteYAMLConfig(path string, cfg map[string]interface{}) error {
+	return UpdateFile(path, func(existing []byte) ([]byte, error) {
+		return encodeYAML(existing, cfg)
+	})
+}
+
+// encodeYAML encodes cfg, merged into the existing YAML document
+func encodeYAML(existing []byte, cfg map[string]interface{}) ([]byte, error) {
+	if cfg == nil {
+		cfg = map[string]interface{}{}
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(existing, &doc); err != nil {
+		return nil, err
+	}
+	var root *yaml.Node
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		root = doc.Content[0]
+	}
+	root, err := mergeYAML(root, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode {
+		doc = yaml.Node{Kind: yaml.DocumentNode}
+	}
+	doc.Content = []*yaml.Node{root}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(yamlIndent(existing))
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// mergeYAML returns node updated to hold value, reusing as much of node as
+// still matches
+func mergeYAML(node *yaml.Node, value interface{}) (*yaml.Node, error) {
+	fresh := &yaml.Node{}
+	if err := fresh.Encode(value); err != nil {
+		return nil, err
+	}
+	if node == nil {
+		return fresh, nil
+	}
+	if sameYAML(node, fresh) {
+		return node, nil
+	}
+
+	switch {
+	case node.Kind == yaml.MappingNode && fresh.Kind == yaml.MappingNode:
+		m, _ := value.(map[string]interface{})
+		if m == nil {
+			break
+		}
+		content := make([]*yaml.Node, 0, len(fresh.Content))
+		seen := make(map[string]bool, len(m))
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			v, ok := m[key]
+			if !ok || seen[key] {
+				continue
+			}
+			merged, err := mergeYAML(node.Content[i+1], v)
+			if err != nil {
+				return nil, err
+			}
+			content = append(content, node.Content[i], merged)
+			seen[key] = true
+		}
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			if !seen[key] {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			merged, err := mergeYAML(nil, map[string]interface{}{key: m[key]})
+			if err != nil {
+				return nil, err
+			}
+			content = append(content, merged.Content...)
+		}
+		node.Content = content
+		return node, nil
+
+	case node.Kind == yaml.SequenceNode && fresh.Kind == yaml.SequenceNode:
+		items := reflect.ValueOf(value)
+		if items.Kind() != reflect.Slice {
+			break
+		}
+		content := make([]*yaml.Node, 0, items.Len())
+		for i := 0; i < items.Len(); i++ {
+			item := items.Index(i).Interface()
+			merged, err := mergeYAML(matchYAMLItem(node.Content, fresh.Content[i], i), item)
+			if err != nil {
+				return nil, err
+			}
+			content = append(content, merged)
+		}
+		node.Content = content
+		return node, nil
+	}
+
+	fresh.HeadComment = node.HeadComment
+	fresh.LineComment = node.LineComment
+	fresh.FootComment = node.FootComment
+	return fresh, nil
+}
+
+// matchYAMLItem returns the existing sequence item to merge a new item
+// into: one with the same "name", else the item at the same index
+func matchYAMLItem(existing []*yaml.Node, item *yaml.Node, index int) *yaml.Node {
+	if name := yamlMappingValue(item, "name"); name != "" {
+		for _, candidate := range existing {
+			if yamlMappingValue(candidate, "name") == name {
+				return candidate
+			}
+		}
+		return nil
+	}
+	if index < len(existing) {
+		return existing[index]
+	}
+	return nil
+}
+
+// yamlMappingValue returns the scalar value of key in a mapping node
+func yamlMappingValue(node *yaml.Node, key string) string {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return ""
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key && node.Content[i+1].Kind == yaml.ScalarNode {
+			return node.Content[i+1].Value
+		}
+	}
+	return ""
+}
+
+// sameYAML reports whether two nodes decode to the same value
+func sameYAML(a, b *yaml.Node) bool {
+	var av, bv interface{}
+	if a.Decode(&av) != nil || b.Decode(&bv) != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+// yamlIndent returns the indentation of the first indented line in data,
+// or 2
+func yamlIndent(data []byte) int {
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		trimmed := bytes.TrimLeft(line, " ")
+		if len(trimmed) == 0 || trimmed[0] == '#' {
+			continue
+		}
+		if n := len(line) - len(trimmed); n > 0 {
+			if trimmed[0] == '-' && n < 2 {
+				continue
+			}
+			return n
+		}
+	}
+	return 2
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteYAMLConfigMerges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	original := `# settings
+zeta: 1 # first
+alpha: "quoted"
+servers:
+    - name: one
+      command: a
+    - name: two
+      command: b # keep
+`
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := ReadYAMLConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	servers := cfg["servers"].([]interface{})
+	cfg["servers"] = []interface{}{servers[1], map[string]interface{}{"name": "three", "command": "c"}}
+	cfg["beta"] = true
+	if err := WriteYAMLConfig(path, cfg); err != nil {
+		t.Fatalf("WriteYAMLConfig() error = %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# settings
+zeta: 1 # first
+alpha: "quoted"
+servers:
+    - name: two
+      command: b # keep
+    - command: c
+      name: three
+beta: true
+`
+	if string(got) != want {
+		t.Errorf("WriteYAMLConfig() wrote\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,60 @@
+// Package home resolves the home directory agentx works in. It is the
+// user's home directory unless agentx is re-rooted with --root, AGENTX_HOME
+// or SetRoot, which moves every agent config, skills and plugins directory
+// and cache under another directory for sandboxes, provisioning and tests.
+package home
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/agentsdance/agentx/adapter/protocol"
+)
+
+// EnvVar re-roots agentx when set
+const EnvVar = protocol.HomeEnvVar
+
+// SetRoot re-roots agentx under dir, or restores the real home directory
+// when dir is empty. The root is exported as AGENTX_HOME so that adapters
+// and other child processes see it too.
+func SetRoot(dir string) error {
+	if dir == "" {
+		return os.Unsetenv(EnvVar)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	return os.Setenv(EnvVar, abs)
+}
+
+// Root returns the directory agentx is re-rooted under, or ""
+func Root() string {
+	root := os.Getenv(EnvVar)
+	if root == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(root); err == nil {
+		return abs
+	}
+	return root
+}
+
+// Dir returns the home directory: the root when re-rooted, else the
+// user's home directory
+func Dir() (string, error) {
+	if root := Root(); root != "" {
+		return root, nil
+	}
+	return os.UserHomeDir()
+}
+
+// Getenv returns an environment variable that relocates agent paths, such
+// as CODEX_HOME. When re-rooted it returns "", so that overrides meant for
+// the real environment can't point outside the root.
+func Getenv(key string) string {
+	if Root() != "" {
+		return ""
+	}
+	return os.Getenv(key)
+}
@@ -0,0 +1,45 @@
+package home
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSetRoot(t *testing.T) {
+	t.Setenv(EnvVar, "")
+	t.Setenv("HOME", "/home/dev")
+	t.Setenv("CODEX_HOME", "/opt/codex")
+
+	if dir, _ := Dir(); dir != "/home/dev" || Root() != "" {
+		t.Errorf("Dir() = %q, Root() = %q before re-rooting", dir, Root())
+	}
+	if got := Getenv("CODEX_HOME"); got != "/opt/codex" {
+		t.Errorf("Getenv() = %q before re-rooting", got)
+	}
+
+	root := t.TempDir()
+	if err := SetRoot(root); err != nil {
+		t.Fatal(err)
+	}
+	if dir, _ := Dir(); dir != root {
+		t.Errorf("Dir() = %q, want %q", dir, root)
+	}
+	if got := Getenv("CODEX_HOME"); got != "" {
+		t.Errorf("Getenv() = %q, want overrides ignored when re-rooted", got)
+	}
+
+	t.Chdir(root)
+	if err := SetRoot("sandbox"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := Root(), filepath.Join(root, "sandbox"); got != want {
+		t.Errorf("Root() = %q, want relative roots made absolute (%q)", got, want)
+	}
+
+	if err := SetRoot(""); err != nil {
+		t.Fatal(err)
+	}
+	if dir, _ := Dir(); dir != "/home/dev" {
+		t.Errorf("Dir() = %q after resetting the root", dir)
+	}
+}
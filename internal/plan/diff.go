@@ -0,0 +1,198 @@
+package plan
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// contextLines is how many unchanged lines surround each hunk
+const contextLines = 3
+
+// maxLCSCells bounds the table diffLines builds; files that differ in more
+// lines than that are shown as one replacement
+const maxLCSCells = 4 << 20
+
+// edit is one line of a diff: ' ' kept, '-' removed or '+' added
+type edit struct {
+	op   byte
+	line string
+}
+
+// Diff returns the change as a unified diff, with /dev/null standing for
+// the file when it is created or removed
+func (c Change) Diff() string {
+	if c.Note != "" {
+		return fmt.Sprintf("# %s: %s\n", c.Path, c.Note)
+	}
+	from, to := c.Path, c.Path
+	if !c.Existed {
+		from = "/dev/null"
+	}
+	if !c.Exists {
+		to = "/dev/null"
+	}
+	if bytes.IndexByte(c.Before, 0) >= 0 || bytes.IndexByte(c.After, 0) >= 0 {
+		return fmt.Sprintf("Binary files %s and %s differ\n", from, to)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", from, to)
+	writeHunks(&b, diffLines(splitLines(c.Before), splitLines(c.After)))
+	return b.String()
+}
+
+// WriteDiff writes the diff of every change in the plan to w
+func (p *Plan) WriteDiff(w io.Writer) error {
+	for _, c := range p.Changes() {
+		if _, err := io.WriteString(w, c.Diff()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitLines splits data after each newline; the last line lacks one if
+// the file doesn't end with a newline
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns the edits that turn a into b, keeping the longest
+// common subsequence of lines
+func diffLines(a, b []string) []edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]edit, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		edits = append(edits, edit{' ', line})
+	}
+	edits = append(edits, lcsEdits(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		edits = append(edits, edit{' ', line})
+	}
+	return edits
+}
+
+func lcsEdits(a, b []string) []edit {
+	var edits []edit
+	if (len(a)+1)*(len(b)+1) > maxLCSCells {
+		for _, line := range a {
+			edits = append(edits, edit{'-', line})
+		}
+		for _, line := range b {
+			edits = append(edits, edit{'+', line})
+		}
+		return edits
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			edits = append(edits, edit{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			edits = append(edits, edit{'-', a[i]})
+			i++
+		default:
+			edits = append(edits, edit{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		edits = append(edits, edit{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		edits = append(edits, edit{'+', b[j]})
+	}
+	return edits
+}
+
+// writeHunks writes the changed edits with contextLines of context,
+// merging hunks whose context would overlap
+func writeHunks(b *strings.Builder, edits []edit) {
+	// oldLine[k] and newLine[k] count the lines of each side before edits[k]
+	oldLine := make([]int, len(edits)+1)
+	newLine := make([]int, len(edits)+1)
+	for k, e := range edits {
+		oldLine[k+1], newLine[k+1] = oldLine[k], newLine[k]
+		if e.op != '+' {
+			oldLine[k+1]++
+		}
+		if e.op != '-' {
+			newLine[k+1]++
+		}
+	}
+
+	for k := 0; k < len(edits); {
+		if edits[k].op == ' ' {
+			k++
+			continue
+		}
+		start := max(k-contextLines, 0)
+		last := k
+		for next := last + 1; next < len(edits) && next-last <= 2*contextLines+1; next++ {
+			if edits[next].op != ' ' {
+				last = next
+			}
+		}
+		stop := min(last+contextLines+1, len(edits))
+
+		fmt.Fprintf(b, "@@ -%s +%s @@\n",
+			hunkRange(oldLine[start], oldLine[stop]-oldLine[start]),
+			hunkRange(newLine[start], newLine[stop]-newLine[start]))
+		for _, e := range edits[start:stop] {
+			b.WriteByte(e.op)
+			b.WriteString(e.line)
+			if !strings.HasSuffix(e.line, "\n") {
+				b.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		k = stop
+	}
+}
+
+// hunkRange formats the start and length of one side of a hunk; an empty
+// side starts at the line before it
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", before+1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
@@ -0,0 +1,288 @@
+// Package plan runs agentx's changes against in-memory copies of the files
+// they would touch, for --dry-run and the TUI's plan step. While a plan is
+// active, the config writers and the skill and plugin managers record the
+// new content of each file in it instead of writing to disk, and the config
+// readers see that content, so a command that edits one file twice plans
+// both edits. Changes then lists every file that would change, with a
+// unified diff of each.
+//
+// Commands wrap their changes in Begin and End. Code that writes files asks
+// Active for the plan and writes through it when there is one.
+package plan
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Plan holds the planned content of the files changed since Begin
+type Plan struct {
+	parent *Plan
+	files  map[string]*file
+	// removed are directories removed whole; a file under one that the
+	// plan doesn't track reads as missing
+	removed []string
+	notes   []Change
+}
+
+// file is a path the plan has touched
+type file struct {
+	before  []byte
+	existed bool
+	after   []byte
+	exists  bool
+}
+
+var (
+	mu     sync.Mutex
+	active *Plan
+)
+
+// Begin starts a plan; until the matching End, changes are recorded in it
+// instead of being made. Begin inside another plan starts one on top of
+// it, which sees the outer plan's changes as the files' current content
+// and is discarded by End without touching the outer plan.
+func Begin() *Plan {
+	mu.Lock()
+	defer mu.Unlock()
+	active = &Plan{parent: active, files: make(map[string]*file)}
+	return active
+}
+
+// End stops recording into the plan. Its changes are still available.
+func (p *Plan) End() {
+	mu.Lock()
+	defer mu.Unlock()
+	if active == p {
+		active = p.parent
+	}
+}
+
+// Active returns the plan changes go to, or nil when they go to disk
+func Active() *Plan {
+	mu.Lock()
+	defer mu.Unlock()
+	return active
+}
+
+// ReadFile returns the content the file at path has in the plan, reading
+// it from disk if the plan hasn't changed it
+func (p *Plan) ReadFile(path string) ([]byte, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	return p.read(clean(path))
+}
+
+func (p *Plan) read(path string) ([]byte, error) {
+	if f, ok := p.files[path]; ok {
+		if !f.exists {
+			return nil, notExist(path)
+		}
+		return bytes.Clone(f.after), nil
+	}
+	for _, dir := range p.removed {
+		if within(path, dir) {
+			return nil, notExist(path)
+		}
+	}
+	if p.parent != nil {
+		return p.parent.read(path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	return data, nil
+}
+
+// track returns the plan's record of path, reading its current content
+// the first time
+func (p *Plan) track(path string) (*file, error) {
+	if f, ok := p.files[path]; ok {
+		return f, nil
+	}
+	data, err := p.read(path)
+	switch {
+	case err == nil:
+	case os.IsNotExist(err):
+		data = nil
+	default:
+		return nil, err
+	}
+	f := &file{before: data, existed: err == nil, after: data, exists: err == nil}
+	p.files[path] = f
+	return f, nil
+}
+
+// WriteFile records data as the new content of the file at path
+func (p *Plan) WriteFile(path string, data []byte) error {
+	mu.Lock()
+	defer mu.Unlock()
+	f, err := p.track(clean(path))
+	if err != nil {
+		return err
+	}
+	f.after, f.exists = bytes.Clone(data), true
+	return nil
+}
+
+// Remove records the removal of the file at path, which must exist
+func (p *Plan) Remove(path string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	path = clean(path)
+	f, err := p.track(path)
+	if err != nil {
+		return err
+	}
+	if !f.exists {
+		return notExist(path)
+	}
+	f.after, f.exists = nil, false
+	return nil
+}
+
+// RemoveAll records the removal of path and, if it is a directory,
+// everything in it
+func (p *Plan) RemoveAll(path string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	path = clean(path)
+	for _, file := range p.tree(path) {
+		f, err := p.track(file)
+		if err != nil {
+			return err
+		}
+		f.after, f.exists = nil, false
+	}
+	p.removed = append(p.removed, path)
+	return nil
+}
+
+// tree returns the files at or under path, on disk or in the plan and
+// the plans under it
+func (p *Plan) tree(path string) []string {
+	seen := make(map[string]bool)
+	for q := p; q != nil; q = q.parent {
+		for file, f := range q.files {
+			if f.exists && within(file, path) {
+				seen[file] = true
+			}
+		}
+	}
+	filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			seen[file] = true
+		}
+		return nil
+	})
+	files := make([]string, 0, len(seen))
+	for file := range seen {
+		if _, err := p.read(file); err == nil {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// CopyFile records a copy of the file at src, which is read from disk, as
+// the new content of dst
+func (p *Plan) CopyFile(dst, src string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return p.WriteFile(dst, data)
+}
+
+// CopyTree records a copy of the directory tree at src under dst,
+// skipping directories named in skip
+func (p *Plan) CopyTree(dst, src string, skip ...string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			for _, name := range skip {
+				if d.Name() == name && path != src {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		return p.CopyFile(filepath.Join(dst, rel), path)
+	})
+}
+
+// Note records a change to path that agentx can't make in memory, such as
+// one an external adapter makes, described by description
+func (p *Plan) Note(path, description string) {
+	mu.Lock()
+	defer mu.Unlock()
+	p.notes = append(p.notes, Change{Path: path, Note: description})
+}
+
+// Change is a file the plan changes
+type Change struct {
+	Path string
+	// Before and After are the content before and after the plan; Existed
+	// and Exists are false when the file is created or removed
+	Before, After   []byte
+	Existed, Exists bool
+	// Note describes a change that has no diff, and is set instead of the
+	// fields above
+	Note string
+}
+
+// Changes returns the files the plan changes, by path, followed by the
+// changes it only has notes for
+func (p *Plan) Changes() []Change {
+	mu.Lock()
+	defer mu.Unlock()
+	var changes []Change
+	for path, f := range p.files {
+		if f.exists == f.existed && bytes.Equal(f.before, f.after) {
+			continue
+		}
+		changes = append(changes, Change{
+			Path:    path,
+			Before:  f.before,
+			After:   f.after,
+			Existed: f.existed,
+			Exists:  f.exists,
+		})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return append(changes, p.notes...)
+}
+
+// clean makes path absolute, so that each file has one entry
+func clean(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+func notExist(path string) error {
+	return &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+}
+
+// within reports whether path is dir or inside it
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,194 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPlanChangesNothingOnDisk(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "mcp.json")
+	skill := filepath.Join(dir, "skills", "pdf")
+	source := filepath.Join(dir, "source")
+	writeFile(t, config, "{}\n")
+	writeFile(t, filepath.Join(skill, "SKILL.md"), "# pdf\n")
+	writeFile(t, filepath.Join(source, "SKILL.md"), "# docx\n")
+	writeFile(t, filepath.Join(source, ".git", "HEAD"), "ref: main\n")
+
+	p := Begin()
+	if Active() != p {
+		t.Fatal("Active() isn't the plan Begin started")
+	}
+	if err := p.WriteFile(config, []byte("{\"a\": 1}\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.RemoveAll(skill); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.CopyTree(filepath.Join(dir, "skills", "docx"), source, ".git"); err != nil {
+		t.Fatal(err)
+	}
+	p.End()
+	if Active() != nil {
+		t.Fatal("End() left the plan active")
+	}
+
+	// The plan reads back its own changes
+	if data, err := p.ReadFile(config); err != nil || string(data) != "{\"a\": 1}\n" {
+		t.Errorf("ReadFile(config) = %q, %v", data, err)
+	}
+	if _, err := p.ReadFile(filepath.Join(skill, "SKILL.md")); !os.IsNotExist(err) {
+		t.Errorf("ReadFile(removed skill) error = %v, want not exist", err)
+	}
+
+	// ... and the disk is untouched
+	if data, _ := os.ReadFile(config); string(data) != "{}\n" {
+		t.Errorf("config was written: %q", data)
+	}
+	if _, err := os.Stat(skill); err != nil {
+		t.Errorf("skill was removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "skills", "docx")); !os.IsNotExist(err) {
+		t.Errorf("skill was copied: %v", err)
+	}
+
+	var paths []string
+	for _, c := range p.Changes() {
+		rel, _ := filepath.Rel(dir, c.Path)
+		paths = append(paths, rel)
+	}
+	want := []string{"mcp.json", "skills/docx/SKILL.md", "skills/pdf/SKILL.md"}
+	if strings.Join(paths, " ") != strings.Join(want, " ") {
+		t.Errorf("Changes() = %v, want %v", paths, want)
+	}
+}
+
+func TestPlanUnchangedFileIsNoChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp.json")
+	writeFile(t, path, "{}\n")
+	p := Begin()
+	defer p.End()
+	p.WriteFile(path, []byte("{\"a\": 1}\n"))
+	p.WriteFile(path, []byte("{}\n"))
+	if changes := p.Changes(); len(changes) != 0 {
+		t.Errorf("Changes() = %v, want none", changes)
+	}
+}
+
+func TestNestedPlan(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp.json")
+	outer := Begin()
+	defer outer.End()
+	outer.WriteFile(path, []byte("outer\n"))
+
+	inner := Begin()
+	if data, _ := inner.ReadFile(path); string(data) != "outer\n" {
+		t.Errorf("inner plan reads %q, want the outer plan's content", data)
+	}
+	inner.WriteFile(path, []byte("inner\n"))
+	inner.End()
+
+	if Active() != outer {
+		t.Fatal("End() of the inner plan didn't go back to the outer one")
+	}
+	if data, _ := outer.ReadFile(path); string(data) != "outer\n" {
+		t.Errorf("outer plan reads %q after the inner plan ended", data)
+	}
+	changes := inner.Changes()
+	if len(changes) != 1 || !changes[0].Existed || string(changes[0].Before) != "outer\n" {
+		t.Errorf("inner Changes() = %+v, want a change from the outer content", changes)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	var before, after strings.Builder
+	for i := 1; i <= 20; i++ {
+		line := "line " + string(rune('a'+i-1)) + "\n"
+		before.WriteString(line)
+		switch i {
+		case 2:
+			after.WriteString("changed b\n")
+		case 5:
+		case 18:
+			after.WriteString(line)
+			after.WriteString("added\n")
+		default:
+			after.WriteString(line)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		change Change
+		want   string
+	}{
+		{
+			name:   "hunks",
+			change: Change{Path: "/c.txt", Before: []byte(before.String()), After: []byte(after.String()), Existed: true, Exists: true},
+			want: `--- /c.txt
++++ /c.txt
+@@ -1,8 +1,7 @@
+ line a
+-line b
++changed b
+ line c
+ line d
+-line e
+ line f
+ line g
+ line h
+@@ -16,5 +15,6 @@
+ line p
+ line q
+ line r
++added
+ line s
+ line t
+`,
+		},
+		{
+			name:   "created",
+			change: Change{Path: "/new.json", After: []byte("{\n}"), Exists: true},
+			want: `--- /dev/null
++++ /new.json
+@@ -0,0 +1,2 @@
++{
++}
+\ No newline at end of file
+`,
+		},
+		{
+			name:   "removed",
+			change: Change{Path: "/old.md", Before: []byte("# old\n"), Existed: true},
+			want: `--- /old.md
++++ /dev/null
+@@ -1 +0,0 @@
+-# old
+`,
+		},
+		{
+			name:   "note",
+			change: Change{Path: "/adapter", Note: "acme would install MCP server context7 (user scope)"},
+			want:   "# /adapter: acme would install MCP server context7 (user scope)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.change.Diff(); got != tt.want {
+				t.Errorf("Diff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
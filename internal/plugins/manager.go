@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/agentsdance/agentx/internal/backup"
+	"github.com/agentsdance/agentx/internal/plan"
 	"github.com/agentsdance/agentx/internal/skills"
 )
 
@@ -83,6 +85,12 @@ func (m *DefaultPluginManager) Remove(name string) error {
 	if _, err := os.Stat(pluginPath); err != nil {
 		return fmt.Errorf("plugin not found: %s", name)
 	}
+	if p := plan.Active(); p != nil {
+		return p.RemoveAll(pluginPath)
+	}
+	if err := backup.Save(pluginPath); err != nil {
+		return err
+	}
 
 	return os.RemoveAll(pluginPath)
 }
@@ -315,7 +323,7 @@ func (m *DefaultPluginManager) installPluginDir(sourcePath string) (*Plugin, err
 	}
 
 	// Ensure plugins directory exists
-	if err := os.MkdirAll(pluginsDir, 0755); err != nil {
+	if err := skills.EnsureDir(pluginsDir); err != nil {
 		return nil, err
 	}
 
@@ -326,6 +334,9 @@ func (m *DefaultPluginManager) installPluginDir(sourcePath string) (*Plugin, err
 	if _, err := os.Stat(targetPath); err == nil {
 		return nil, fmt.Errorf("plugin already exists: %s", plugin.Name)
 	}
+	if err := backup.Save(targetPath); err != nil {
+		return nil, err
+	}
 
 	if err := copyDir(sourcePath, targetPath); err != nil {
 		return nil, fmt.Errorf("failed to copy plugin: %w", err)
@@ -335,8 +346,11 @@ func (m *DefaultPluginManager) installPluginDir(sourcePath string) (*Plugin, err
 	return plugin, nil
 }
 
-// copyDir copies a directory recursively
+// copyDir copies a directory recursively; a dry run plans the copy
 func copyDir(src, dst string) error {
+	if p := plan.Active(); p != nil {
+		return p.CopyTree(dst, src, ".git")
+	}
 	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -363,8 +377,11 @@ func copyDir(src, dst string) error {
 	})
 }
 
-// copyFile copies a single file
+// copyFile copies a single file; a dry run plans the copy
 func copyFile(src, dst string) error {
+	if p := plan.Active(); p != nil {
+		return p.CopyFile(dst, src)
+	}
 	srcFile, err := os.Open(src)
 	if err != nil {
 		return err
@@ -3,15 +3,17 @@ package plugins
 import (
 	"os"
 	"path/filepath"
+
+	"github.com/agentsdance/agentx/internal/home"
 )
 
 // GetPluginsDir returns the plugins directory path (~/.agentx/plugins)
 func GetPluginsDir() (string, error) {
-	home, err := os.UserHomeDir()
+	dir, err := home.Dir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(home, ".agentx", "plugins"), nil
+	return filepath.Join(dir, ".agentx", "plugins"), nil
 }
 
 // GetPluginManifestPath returns the path to plugin.json for a plugin
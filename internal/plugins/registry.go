@@ -8,6 +8,8 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/agentsdance/agentx/internal/home"
 )
 
 // DefaultRegistryURL is the default URL for the plugin registry
@@ -163,11 +165,11 @@ func cacheRegistry(data []byte) error {
 
 // getRegistryCachePath returns the path to the cached registry
 func getRegistryCachePath() (string, error) {
-	home, err := os.UserHomeDir()
+	dir, err := home.Dir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(home, ".agentx", "cache", "plugin-registry.json"), nil
+	return filepath.Join(dir, ".agentx", "cache", "plugin-registry.json"), nil
 }
 
 // ComponentsSummary returns a human-readable summary of components
@@ -0,0 +1,8 @@
+//go:build !unix && !windows
+
+package safefile
+
+// Lock is a no-op where there is no file locking
+func Lock(path string) (func(), error) {
+	return func() {}, nil
+}
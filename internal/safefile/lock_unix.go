@@ -0,0 +1,39 @@
+//go:build unix
+
+package safefile
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockSuffix names the lock file agentx keeps next to a file it updates
+const lockSuffix = ".agentx.lock"
+
+// Lock takes an exclusive advisory lock for the file at path and
+// returns a function that releases it. The lock file only exists while
+// the lock is held.
+func Lock(path string) (func(), error) {
+	name := path + lockSuffix
+	for {
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0600)
+		if err != nil {
+			return nil, err
+		}
+		if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+			f.Close()
+			return nil, err
+		}
+		// The previous holder removes the lock file when it is done, so
+		// the file locked here may no longer be the one at name
+		held, err1 := f.Stat()
+		current, err2 := os.Stat(name)
+		if err1 == nil && err2 == nil && os.SameFile(held, current) {
+			return func() {
+				os.Remove(name)
+				f.Close()
+			}, nil
+		}
+		f.Close()
+	}
+}
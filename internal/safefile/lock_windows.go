@@ -0,0 +1,41 @@
+//go:build windows
+
+package safefile
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockSuffix names the lock file agentx keeps next to a file it updates
+const lockSuffix = ".agentx.lock"
+
+// Lock takes an exclusive lock for the file at path and returns a
+// function that releases it
+func Lock(path string) (func(), error) {
+	name := path + lockSuffix
+	for {
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0600)
+		if err != nil {
+			return nil, err
+		}
+		overlapped := new(windows.Overlapped)
+		if err := windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, overlapped); err != nil {
+			f.Close()
+			return nil, err
+		}
+		held, err1 := f.Stat()
+		current, err2 := os.Stat(name)
+		if err1 == nil && err2 == nil && os.SameFile(held, current) {
+			return func() {
+				windows.UnlockFileEx(windows.Handle(f.Fd()), 0, 1, 0, overlapped)
+				f.Close()
+				// Fails while another process has the file open, which
+				// is fine: it is reused
+				os.Remove(name)
+			}, nil
+		}
+		f.Close()
+	}
+}
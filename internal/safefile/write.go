@@ -0,0 +1,101 @@
+// Package safefile writes files without ever leaving them half written, and
+// locks them against other agentx processes. The config writers and the
+// backup restore both go through it, so every file agentx replaces gets the
+// same locking, fsync and mode and owner preservation.
+package safefile
+
+import (
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// defaultMode is the mode of files Replace creates, before the umask is
+// applied, when the caller doesn't ask for one
+const defaultMode os.FileMode = 0644
+
+// The steps of Replace that touch the disk, replaced in tests to simulate a
+// full disk or a crash part way through a write
+var (
+	writeTemp  = func(f *os.File, data []byte) (int, error) { return f.Write(data) }
+	syncTemp   = func(f *os.File) error { return f.Sync() }
+	renameTemp = os.Rename
+)
+
+// Replace replaces the file at path with data without ever leaving a
+// partly written file behind. The data goes to a temp file in the same
+// directory, which is synced and then renamed over path, so a crash or a
+// full disk leaves either the old or the new content. check, if not nil,
+// runs just before the rename and aborts it by returning an error. An
+// existing file keeps its mode and, where the platform allows, its owner;
+// a new one gets perm, or 0644 when perm is zero.
+func Replace(path string, data []byte, perm os.FileMode, check func() error) error {
+	dir := filepath.Dir(path)
+	mode := perm
+	if mode == 0 {
+		mode = defaultMode
+	}
+	info, err := os.Stat(path)
+	switch {
+	case err == nil:
+		mode = info.Mode().Perm()
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	tmp, err := createTemp(dir, filepath.Base(path), mode)
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if info != nil {
+		// The temp file was created with the umask applied; give it the
+		// exact mode and owner of the file it replaces
+		if err := tmp.Chmod(mode); err != nil {
+			return err
+		}
+		keepOwner(tmp, info)
+	}
+	if _, err := writeTemp(tmp, data); err != nil {
+		return err
+	}
+	if err := syncTemp(tmp); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if check != nil {
+		if err := check(); err != nil {
+			return err
+		}
+	}
+	if err := renameTemp(tmpPath, path); err != nil {
+		return err
+	}
+	committed = true
+	syncDir(dir)
+	return nil
+}
+
+// createTemp creates a new file next to a file named base, with perm
+// subject to the umask like any other new file
+func createTemp(dir, base string, perm os.FileMode) (*os.File, error) {
+	for i := 0; ; i++ {
+		name := filepath.Join(dir, "."+base+"."+strconv.FormatUint(uint64(rand.Uint32()), 36)+".tmp")
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+		if err == nil || !errors.Is(err, os.ErrExist) || i == 100 {
+			return f, err
+		}
+	}
+}
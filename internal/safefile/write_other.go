@@ -0,0 +1,11 @@
+//go:build !unix
+
+package safefile
+
+import "os"
+
+// keepOwner is a no-op where files have no Unix owner
+func keepOwner(f *os.File, info os.FileInfo) {}
+
+// syncDir is a no-op where directories can't be synced
+func syncDir(dir string) {}
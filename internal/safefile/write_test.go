@@ -0,0 +1,112 @@
+package safefile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"testing"
+)
+
+const original = `{"mcpServers": {"github": {"command": "npx"}}, "oauthAccount": {"token": "secret"}}`
+
+func writeOriginal(t *testing.T, mode os.FileMode) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".claude.json")
+	if err := os.WriteFile(path, []byte(original), mode); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// assertOriginal checks that path still holds the original content and
+// that no temp file was left next to it
+func assertOriginal(t *testing.T, path string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != original {
+		t.Errorf("file = %q, want the original content", data)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("directory holds %v, want only the config", names)
+	}
+}
+
+func TestReplaceFailures(t *testing.T) {
+	errDiskFull := syscall.ENOSPC
+	errChanged := errors.New("changed")
+	tests := []struct {
+		name  string
+		fail  func()
+		check func() error
+		want  error
+	}{
+		{name: "disk full mid-write", fail: func() {
+			writeTemp = func(f *os.File, data []byte) (int, error) {
+				n, _ := f.Write(data[:len(data)/2])
+				return n, errDiskFull
+			}
+		}, want: errDiskFull},
+		{name: "sync fails", fail: func() {
+			syncTemp = func(*os.File) error { return errDiskFull }
+		}, want: errDiskFull},
+		{name: "rename fails", fail: func() {
+			renameTemp = func(string, string) error { return errDiskFull }
+		}, want: errDiskFull},
+		{name: "check fails", fail: func() {}, check: func() error { return errChanged }, want: errChanged},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(w func(*os.File, []byte) (int, error), s func(*os.File) error, r func(string, string) error) {
+				writeTemp, syncTemp, renameTemp = w, s, r
+			}(writeTemp, syncTemp, renameTemp)
+			tt.fail()
+
+			path := writeOriginal(t, 0600)
+			if err := Replace(path, []byte("{}"), 0, tt.check); !errors.Is(err, tt.want) {
+				t.Fatalf("Replace() error = %v, want %v", err, tt.want)
+			}
+			assertOriginal(t, path)
+		})
+	}
+}
+
+func TestReplaceModes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows has no Unix permission bits")
+	}
+	path := writeOriginal(t, 0600)
+	if err := Replace(path, []byte("{}"), 0644, nil); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+	if info, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	} else if info.Mode().Perm() != 0600 {
+		t.Errorf("replaced file mode = %v, want the existing 0600", info.Mode().Perm())
+	}
+
+	created := filepath.Join(t.TempDir(), "new.json")
+	if err := Replace(created, []byte("{}"), 0600, nil); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+	if info, err := os.Stat(created); err != nil {
+		t.Fatal(err)
+	} else if info.Mode().Perm() != 0600 {
+		t.Errorf("new file mode = %v, want 0600", info.Mode().Perm())
+	}
+}
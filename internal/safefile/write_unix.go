@@ -0,0 +1,29 @@
+//go:build unix
+
+package safefile
+
+import (
+	"os"
+	"syscall"
+)
+
+// keepOwner gives f the owner and group of the file described by info. It
+// only succeeds where the process may change ownership (usually as root,
+// e.g. under sudo); otherwise f keeps the process's owner.
+func keepOwner(f *os.File, info os.FileInfo) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || (int(st.Uid) == os.Geteuid() && int(st.Gid) == os.Getegid()) {
+		return
+	}
+	_ = f.Chown(int(st.Uid), int(st.Gid))
+}
+
+// syncDir flushes dir so that a rename into it survives a crash
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	d.Close()
+}
@@ -6,6 +6,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/agentsdance/agentx/internal/backup"
+	"github.com/agentsdance/agentx/internal/plan"
 )
 
 // DefaultSkillManager implements SkillManager
@@ -42,6 +45,30 @@ func NewDroidSkillManager() *DefaultSkillManager {
 	}
 }
 
+// NewSkillManagerForBase creates a skill manager whose skills live in
+// <base>/skills and commands in <base>/commands, for the given personal and
+// project base directories
+func NewSkillManagerForBase(personal, project string, supportsCommands bool) *DefaultSkillManager {
+	baseFor := func(scope SkillScope) string {
+		if scope == ScopeProject {
+			return project
+		}
+		return personal
+	}
+	m := &DefaultSkillManager{
+		getSkillsDir: func(scope SkillScope) (string, error) {
+			return filepath.Join(baseFor(scope), "skills"), nil
+		},
+		supportsCommands: supportsCommands,
+	}
+	if supportsCommands {
+		m.getCommandsDir = func(scope SkillScope) (string, error) {
+			return filepath.Join(baseFor(scope), "commands"), nil
+		}
+	}
+	return m
+}
+
 func (m *DefaultSkillManager) commandsDir(scope SkillScope) (string, error) {
 	if m.getCommandsDir == nil {
 		return "", fmt.Errorf("commands directory resolver not configured")
@@ -148,7 +175,7 @@ func (m *DefaultSkillManager) Remove(name string, scope SkillScope) error {
 		commandsDir, _ := m.commandsDir(scope)
 		commandPath := filepath.Join(commandsDir, name+".md")
 		if _, err := os.Stat(commandPath); err == nil {
-			return os.Remove(commandPath)
+			return removePath(commandPath)
 		}
 	}
 
@@ -156,12 +183,24 @@ func (m *DefaultSkillManager) Remove(name string, scope SkillScope) error {
 	skillsDir, _ := m.skillsDir(scope)
 	skillPath := filepath.Join(skillsDir, name)
 	if _, err := os.Stat(skillPath); err == nil {
-		return os.RemoveAll(skillPath)
+		return removePath(skillPath)
 	}
 
 	return fmt.Errorf("skill not found: %s", name)
 }
 
+// removePath deletes an installed skill or command after backing it up; a
+// dry run plans the removal
+func removePath(path string) error {
+	if p := plan.Active(); p != nil {
+		return p.RemoveAll(path)
+	}
+	if err := backup.Save(path); err != nil {
+		return err
+	}
+	return os.RemoveAll(path)
+}
+
 // Check verifies skills installation status
 func (m *DefaultSkillManager) Check() ([]SkillStatus, error) {
 	skills, err := m.List()
@@ -416,6 +455,9 @@ func (m *DefaultSkillManager) installSkillDir(sourcePath string, scope SkillScop
 	if _, err := os.Stat(targetPath); err == nil {
 		return nil, fmt.Errorf("skill already exists: %s", skill.Name)
 	}
+	if err := backup.Save(targetPath); err != nil {
+		return nil, err
+	}
 
 	if err := copyDir(sourcePath, targetPath); err != nil {
 		return nil, fmt.Errorf("failed to copy skill: %w", err)
@@ -454,6 +496,9 @@ func (m *DefaultSkillManager) installCommandFile(sourcePath string, scope SkillS
 	if _, err := os.Stat(targetPath); err == nil {
 		return nil, fmt.Errorf("command already exists: %s", skill.Name)
 	}
+	if err := backup.Save(targetPath); err != nil {
+		return nil, err
+	}
 
 	if err := copyFile(sourcePath, targetPath); err != nil {
 		return nil, fmt.Errorf("failed to copy command: %w", err)
@@ -463,8 +508,11 @@ func (m *DefaultSkillManager) installCommandFile(sourcePath string, scope SkillS
 	return skill, nil
 }
 
-// copyDir copies a directory recursively
+// copyDir copies a directory recursively; a dry run plans the copy
 func copyDir(src, dst string) error {
+	if p := plan.Active(); p != nil {
+		return p.CopyTree(dst, src, ".git")
+	}
 	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -491,8 +539,11 @@ func copyDir(src, dst string) error {
 	})
 }
 
-// copyFile copies a single file
+// copyFile copies a single file; a dry run plans the copy
 func copyFile(src, dst string) error {
+	if p := plan.Active(); p != nil {
+		return p.CopyFile(dst, src)
+	}
 	srcFile, err := os.Open(src)
 	if err != nil {
 		return err
@@ -3,17 +3,23 @@ package skills
 import (
 	"os"
 	"path/filepath"
+
+	"github.com/agentsdance/agentx/internal/home"
+	"github.com/agentsdance/agentx/internal/plan"
 )
 
-// GetClaudeBasePaths returns the base paths for Claude Code configuration
+// GetClaudeBasePaths returns the base paths for Claude Code configuration,
+// honouring CLAUDE_CONFIG_DIR
 func GetClaudeBasePaths() (personal, project string, err error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", "", err
+	personal = home.Getenv("CLAUDE_CONFIG_DIR")
+	if personal == "" {
+		dir, err := home.Dir()
+		if err != nil {
+			return "", "", err
+		}
+		personal = filepath.Join(dir, ".claude")
 	}
 
-	personal = filepath.Join(home, ".claude")
-
 	// Project path is relative to current directory
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -26,13 +32,13 @@ func GetClaudeBasePaths() (personal, project string, err error) {
 
 // GetCodexBasePaths returns the base paths for Codex configuration
 func GetCodexBasePaths() (personal, project string, err error) {
-	codexHome := os.Getenv("CODEX_HOME")
+	codexHome := home.Getenv("CODEX_HOME")
 	if codexHome == "" {
-		home, err := os.UserHomeDir()
+		dir, err := home.Dir()
 		if err != nil {
 			return "", "", err
 		}
-		codexHome = filepath.Join(home, ".codex")
+		codexHome = filepath.Join(dir, ".codex")
 	}
 
 	personal = codexHome
@@ -105,12 +111,12 @@ func GetCodexSkillsDir(scope SkillScope) (string, error) {
 
 // GetDroidBasePaths returns the base paths for Factory Droid configuration
 func GetDroidBasePaths() (personal, project string, err error) {
-	home, err := os.UserHomeDir()
+	dir, err := home.Dir()
 	if err != nil {
 		return "", "", err
 	}
 
-	personal = filepath.Join(home, ".factory")
+	personal = filepath.Join(dir, ".factory")
 
 	// Project path is relative to current directory
 	cwd, err := os.Getwd()
@@ -136,7 +142,11 @@ func GetDroidSkillsDir(scope SkillScope) (string, error) {
 	return filepath.Join(base, "skills"), nil
 }
 
-// EnsureDir creates a directory if it doesn't exist
+// EnsureDir creates a directory if it doesn't exist. A dry run creates
+// nothing.
 func EnsureDir(path string) error {
+	if plan.Active() != nil {
+		return nil
+	}
 	return os.MkdirAll(path, 0755)
 }
@@ -8,6 +8,8 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/agentsdance/agentx/internal/home"
 )
 
 // DefaultSkillsRegistryURL is the default URL for the skills registry
@@ -163,9 +165,9 @@ func cacheSkillsRegistry(data []byte) error {
 
 // getSkillsRegistryCachePath returns the path to the cached skills registry
 func getSkillsRegistryCachePath() (string, error) {
-	home, err := os.UserHomeDir()
+	dir, err := home.Dir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(home, ".agentx", "cache", "skills-registry.json"), nil
+	return filepath.Join(dir, ".agentx", "cache", "skills-registry.json"), nil
 }
@@ -0,0 +1,28 @@
+package version
+
+import "strings"
+
+// ExtractSemver returns the first semantic version found in output, such as
+// "1.0.30" in "1.0.30 (Claude Code)" or "codex-cli 0.46.0", without a
+// leading "v".
+func ExtractSemver(output string) (string, bool) {
+	_, normalized, ok := parseSemver(output)
+	if !ok {
+		return "", false
+	}
+	return strings.TrimPrefix(normalized, "v"), true
+}
+
+// CompareSemver compares two semantic versions, returning -1, 0 or 1. It
+// reports false when either version does not parse.
+func CompareSemver(a, b string) (int, bool) {
+	parsedA, _, ok := parseSemver(a)
+	if !ok {
+		return 0, false
+	}
+	parsedB, _, ok := parseSemver(b)
+	if !ok {
+		return 0, false
+	}
+	return compareSemver(parsedA, parsedB), true
+}
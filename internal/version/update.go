@@ -10,6 +10,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/agentsdance/agentx/internal/home"
 )
 
 const (
@@ -347,11 +349,11 @@ func saveUpdateCache(cache updateCache) error {
 }
 
 func getUpdateCachePath() (string, error) {
-	home, err := os.UserHomeDir()
+	dir, err := home.Dir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(home, ".agentx", "cache", "update.json"), nil
+	return filepath.Join(dir, ".agentx", "cache", "update.json"), nil
 }
 
 func upgradeCommand() string {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/agentsdance/agentx/internal/plan"
 	"github.com/agentsdance/agentx/internal/version"
 	"github.com/agentsdance/agentx/ui/components"
 	"github.com/agentsdance/agentx/ui/theme"
@@ -40,6 +41,15 @@ type AppModel struct {
 	// State
 	quitting bool
 
+	// Plan step: while planning, an action first runs against an in-memory
+	// plan and its diff is shown for review; pending is the key that
+	// started it, replayed when the change is applied. Under --dry-run
+	// every action is planned and applying adds it to the dry run.
+	planning bool
+	dryRun   bool
+	review   *views.PlanView
+	pending  tea.KeyMsg
+
 	// Update state
 	updateAvailable bool
 	updateNotice    version.UpdateNotice
@@ -86,6 +96,7 @@ func NewAppModel() AppModel {
 	// Initialize footer
 	footer := components.NewFooter(mcpView.ShortHelp())
 
+	dryRun := plan.Active() != nil
 	return AppModel{
 		mcpView:     mcpView,
 		skillsView:  skillsView,
@@ -96,13 +107,15 @@ func NewAppModel() AppModel {
 		sidebar:     sidebar,
 		footer:      footer,
 		activeTab:   TabMCP,
+		planning:    dryRun,
+		dryRun:      dryRun,
 	}
 }
 
 // Init implements tea.Model
 func (m AppModel) Init() tea.Cmd {
 	// Request initial window size
-	return tea.Batch(tea.WindowSize(), checkForUpdateCmd())
+	return tea.Batch(tea.WindowSize(), checkForUpdateCmd(), m.agentsView.Init())
 }
 
 // Update implements tea.Model
@@ -114,16 +127,29 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.updateNotice = msg.notice
 		}
 
+	case views.AgentsDetectedMsg:
+		m.agentsView.Update(msg)
+		if m.activeTab == TabAgents {
+			m.sidebar.SetSections(m.agentsView.GetSidebarSections())
+		}
+		m.updateHeaderStats()
+
 	case tea.WindowSizeMsg:
 		m.layout = CalculateLayout(msg.Width, msg.Height)
 		m.updateDimensions()
 
 	case tea.KeyMsg:
+		if m.review != nil {
+			return m.updateReview(msg)
+		}
 		switch msg.String() {
 		case "q", "ctrl+c":
 			m.quitting = true
 			return m, tea.Quit
 
+		case "p":
+			m.togglePlanning()
+
 		case "1":
 			m.switchTab(TabMCP)
 		case "2":
@@ -145,7 +171,11 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		default:
 			// Pass to active view
-			m.updateActiveView(msg)
+			if m.planning && m.activeTab != TabAgents {
+				m.planActiveView(msg)
+			} else {
+				m.updateActiveView(msg)
+			}
 		}
 	}
 
@@ -241,17 +271,15 @@ func (m *AppModel) buildInnerFooter(width int) string {
 	separatorStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#4B5563"))
 
-	// Get actions from active view
-	var actions []components.FooterAction
-	switch m.activeTab {
-	case TabMCP:
-		actions = m.mcpView.ShortHelp()
-	case TabSkills:
-		actions = m.skillsView.ShortHelp()
-	case TabPlugins:
-		actions = m.pluginsView.ShortHelp()
-	case TabAgents:
-		actions = m.agentsView.ShortHelp()
+	// Get actions from active view, or the plan under review
+	actions := m.activeShortHelp()
+	if m.review != nil {
+		actions = m.review.ShortHelp()
+	}
+	for i := range actions {
+		if actions[i].Key == "p" && m.planning {
+			actions[i].Label = "plan: on"
+		}
 	}
 
 	var parts []string
@@ -266,6 +294,21 @@ func (m *AppModel) buildInnerFooter(width int) string {
 	return lipgloss.JoinHorizontal(lipgloss.Top, parts...)
 }
 
+// activeShortHelp returns the keyboard shortcuts of the active view
+func (m *AppModel) activeShortHelp() []components.FooterAction {
+	switch m.activeTab {
+	case TabMCP:
+		return m.mcpView.ShortHelp()
+	case TabSkills:
+		return m.skillsView.ShortHelp()
+	case TabPlugins:
+		return m.pluginsView.ShortHelp()
+	case TabAgents:
+		return m.agentsView.ShortHelp()
+	}
+	return nil
+}
+
 func (m *AppModel) switchTab(tab int) {
 	m.activeTab = tab
 	m.tabBar.SetActive(tab)
@@ -316,7 +359,81 @@ func (m *AppModel) updateActiveView(msg tea.Msg) {
 	}
 }
 
+// togglePlanning turns the plan step on or off; a dry run always plans
+func (m *AppModel) togglePlanning() {
+	if m.activeTab == TabAgents {
+		return
+	}
+	message := "Plan step off: changes are applied right away"
+	switch {
+	case m.dryRun:
+		message = "Dry run: every change is planned, nothing is written"
+	case !m.planning:
+		m.planning = true
+		message = "Plan step on: changes are shown as a diff before they are applied"
+	default:
+		m.planning = false
+	}
+	m.refreshActiveView(message)
+}
+
+// planActiveView passes msg to the active view with a plan active and, if
+// the action it starts would change files, holds it for review
+func (m *AppModel) planActiveView(msg tea.KeyMsg) {
+	p := plan.Begin()
+	m.updateActiveView(msg)
+	p.End()
+	changes := p.Changes()
+	if len(changes) == 0 {
+		return
+	}
+	m.pending = msg
+	m.review = views.NewPlanView(changes, m.dryRun)
+	m.review.SetDimensions(m.layout.MainWidth, m.layout.MainHeight)
+}
+
+// updateReview handles keys while a planned change is shown: applying it
+// replays its key for real, discarding it drops the plan
+func (m AppModel) updateReview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "y", "enter":
+		m.review = nil
+		// The view shows the planned state; start from what is on disk
+		m.refreshActiveView("")
+		m.updateActiveView(m.pending)
+	case "n", "esc":
+		m.review = nil
+		m.refreshActiveView("Discarded the planned change")
+	default:
+		m.review.Update(msg)
+	}
+	return m, nil
+}
+
+// refreshActiveView re-reads the active view's status and shows message
+func (m *AppModel) refreshActiveView(message string) {
+	switch m.activeTab {
+	case TabMCP:
+		m.mcpView.Refresh(message)
+		m.sidebar.SetSections(m.mcpView.GetSidebarSections())
+	case TabSkills:
+		m.skillsView.Refresh(message)
+		m.sidebar.SetSections(m.skillsView.GetSidebarSections())
+	case TabPlugins:
+		m.pluginsView.Refresh(message)
+		m.sidebar.SetSections(m.pluginsView.GetSidebarSections())
+	}
+	m.footer.SetMessage(message)
+	m.updateHeaderStats()
+}
+
 func (m *AppModel) getActiveViewContent() string {
+	if m.review != nil {
+		return m.review.View()
+	}
 	switch m.activeTab {
 	case TabMCP:
 		return m.mcpView.View()
@@ -341,6 +458,9 @@ func (m *AppModel) updateDimensions() {
 	m.skillsView.SetDimensions(m.layout.MainWidth, m.layout.MainHeight)
 	m.pluginsView.SetDimensions(m.layout.MainWidth, m.layout.MainHeight)
 	m.agentsView.SetDimensions(m.layout.MainWidth, m.layout.MainHeight)
+	if m.review != nil {
+		m.review.SetDimensions(m.layout.MainWidth, m.layout.MainHeight)
+	}
 }
 
 func (m *AppModel) updateHeaderStats() {
@@ -26,7 +26,7 @@ func NewModel() Model {
 	statuses := make([]AgentStatus, len(agents))
 
 	for i, a := range agents {
-		installed, err := a.HasPlaywright()
+		installed, err := hasPlaywright(a)
 		statuses[i] = AgentStatus{
 			Agent:     a,
 			Installed: installed,
@@ -44,9 +44,17 @@ func NewModel() Model {
 // refreshStatus refreshes the status of all agents
 func (m *Model) refreshStatus() {
 	for i := range m.agents {
-		installed, err := m.agents[i].Agent.HasPlaywright()
+		installed, err := hasPlaywright(m.agents[i].Agent)
 		m.agents[i].Installed = installed
 		m.agents[i].Exists = m.agents[i].Agent.Exists()
 		m.agents[i].Error = err
 	}
 }
+
+func hasPlaywright(a agent.Agent) (bool, error) {
+	host, err := agent.AsMCPHost(a)
+	if err != nil {
+		return false, err
+	}
+	return host.HasMCP("playwright", agent.MCPScopeUser)
+}
@@ -16,6 +16,14 @@ type CodeAgentInfo struct {
 	Agent      agent.Agent
 	Exists     bool
 	ConfigPath string
+	// Detection is nil until the agent's binaries have been probed
+	Detection *agent.DetectionResult
+}
+
+// AgentsDetectedMsg carries detection results for the agents view, in the
+// order of its agents
+type AgentsDetectedMsg struct {
+	Results []agent.DetectionResult
 }
 
 // AgentsView displays code agent status
@@ -46,12 +54,22 @@ func NewAgentsView() *AgentsView {
 	}
 }
 
+// Init probes agent binaries in the background, since running --version
+// for every agent can take a moment
 func (v *AgentsView) Init() tea.Cmd {
-	return nil
+	agents := make([]agent.Agent, len(v.agents))
+	for i, info := range v.agents {
+		agents[i] = info.Agent
+	}
+	return func() tea.Msg {
+		return AgentsDetectedMsg{Results: agent.DetectAll(agents)}
+	}
 }
 
 func (v *AgentsView) Update(msg tea.Msg) (View, tea.Cmd) {
 	switch msg := msg.(type) {
+	case AgentsDetectedMsg:
+		v.setDetections(msg.Results)
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "up", "k":
@@ -68,7 +86,11 @@ func (v *AgentsView) Update(msg tea.Msg) (View, tea.Cmd) {
 		case "o":
 			// Open config in editor (placeholder for future)
 			if len(v.agents) > 0 {
-				v.message = fmt.Sprintf("Config: %s", v.agents[v.cursor].ConfigPath)
+				info := v.agents[v.cursor]
+				v.message = fmt.Sprintf("Config: %s", info.ConfigPath)
+				if info.Detection != nil && info.Detection.Binary != "" {
+					v.message += fmt.Sprintf("  Binary: %s", info.Detection.Binary)
+				}
 			}
 		}
 	}
@@ -89,6 +111,9 @@ func (v *AgentsView) View() string {
 	activeStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#10B981"))
 
+	warningStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F59E0B"))
+
 	inactiveStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#6B7280"))
 
@@ -122,12 +147,25 @@ func (v *AgentsView) View() string {
 
 		// Status
 		var statusStr string
-		if info.Exists {
-			statusStr = activeStyle.Render("● configured")
-		} else {
-			statusStr = inactiveStyle.Render("○ not found")
+		switch {
+		case info.Detection != nil && info.Detection.Status == agent.DetectionInstalled:
+			statusStr = activeStyle.Render("● installed  ")
+		case info.Detection != nil && info.Detection.Status == agent.DetectionConfigOnly:
+			statusStr = warningStyle.Render("◐ config only")
+		case info.Exists:
+			statusStr = activeStyle.Render("● configured ")
+		default:
+			statusStr = inactiveStyle.Render("○ not found  ")
 		}
-		row.WriteString(fmt.Sprintf("%-16s", statusStr))
+		row.WriteString(statusStr)
+		row.WriteString("  ")
+
+		// Version
+		row.WriteString(mutedStyle.Render(fmt.Sprintf("%-10s", versionLabel(info.Detection))))
+		row.WriteString("  ")
+
+		// Capabilities
+		row.WriteString(mutedStyle.Render(fmt.Sprintf("%-28s", capabilityList(info.Agent.Capabilities()))))
 		row.WriteString("  ")
 
 		// Config path
@@ -145,6 +183,34 @@ func (v *AgentsView) View() string {
 	return b.String()
 }
 
+// versionLabel returns the version to show for a detection result
+func versionLabel(r *agent.DetectionResult) string {
+	switch {
+	case r == nil:
+		return "…"
+	case r.Version != "":
+		return r.Version
+	case r.Binary != "":
+		return "unknown"
+	}
+	return "-"
+}
+
+func capabilityList(caps agent.Capabilities) string {
+	var names []string
+	for _, capability := range []agent.Capability{
+		agent.CapabilityMCP,
+		agent.CapabilitySkills,
+		agent.CapabilityPlugins,
+		agent.CapabilityInstructions,
+	} {
+		if caps.Has(capability) {
+			names = append(names, string(capability))
+		}
+	}
+	return strings.Join(names, " ")
+}
+
 func (v *AgentsView) SetDimensions(width, height int) {
 	v.width = width
 	v.height = height
@@ -183,8 +249,21 @@ func (v *AgentsView) Message() string {
 }
 
 func (v *AgentsView) refreshStatus() {
+	agents := make([]agent.Agent, len(v.agents))
+	for i, info := range v.agents {
+		agents[i] = info.Agent
+	}
+	v.setDetections(agent.DetectAll(agents))
+}
+
+func (v *AgentsView) setDetections(results []agent.DetectionResult) {
 	for i := range v.agents {
-		v.agents[i].Exists = v.agents[i].Agent.Exists()
+		if i >= len(results) {
+			break
+		}
+		result := results[i]
+		v.agents[i].Detection = &result
+		v.agents[i].Exists = result.Found()
 	}
 }
 
@@ -1,12 +1,13 @@
 package views
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
 
 	"github.com/agentsdance/agentx/internal/agent"
-	"github.com/agentsdance/agentx/internal/config"
+	"github.com/agentsdance/agentx/internal/backup"
 	"github.com/agentsdance/agentx/ui/components"
 	"github.com/agentsdance/agentx/ui/theme"
 	tea "github.com/charmbracelet/bubbletea"
@@ -34,10 +35,12 @@ var embeddedMCPServers = []MCPServer{
 
 // AgentMCPStatus represents an agent's MCP installation status
 type AgentMCPStatus struct {
-	Agent     agent.Agent
-	Exists    bool
-	Installed map[string]bool
-	Errors    map[string]error
+	Agent       agent.MCPHost
+	Exists      bool
+	Unsupported bool // the agent has no config for the current scope
+	Installed   map[string]bool
+	Specs       map[string]agent.MCPServerSpec // the servers as the agent has them
+	Errors      map[string]error
 }
 
 // MCPView displays MCP server installation status across code agents
@@ -45,6 +48,7 @@ type MCPView struct {
 	agents        []AgentMCPStatus
 	servers       []MCPServer
 	serverConfigs map[string]agent.MCPConfigEntry
+	scope         agent.MCPScope
 	cursorRow     int // MCP server row
 	cursorCol     int // Agent column
 	width         int
@@ -54,34 +58,20 @@ type MCPView struct {
 
 // NewMCPView creates a new MCP view
 func NewMCPView() *MCPView {
-	agents := agent.GetAllAgents()
-	serverConfigs := agent.CollectMCPConfigs(agents)
-	servers := buildMCPServerList(serverConfigs)
+	agents := agent.MCPHosts(agent.GetAllAgents())
 	statuses := make([]AgentMCPStatus, len(agents))
-
 	for i, a := range agents {
-		installed := make(map[string]bool)
-		errors := make(map[string]error)
-		for _, srv := range servers {
-			ok, err := a.HasMCP(srv.Name)
-			installed[srv.Name] = ok
-			errors[srv.Name] = err
-		}
-		statuses[i] = AgentMCPStatus{
-			Agent:     a,
-			Exists:    a.Exists(),
-			Installed: installed,
-			Errors:    errors,
-		}
+		statuses[i] = AgentMCPStatus{Agent: a}
 	}
 
-	return &MCPView{
-		agents:        statuses,
-		servers:       servers,
-		serverConfigs: serverConfigs,
-		cursorRow:     0,
-		cursorCol:     0,
+	v := &MCPView{
+		agents:    statuses,
+		scope:     agent.MCPScopeUser,
+		cursorRow: 0,
+		cursorCol: 0,
 	}
+	v.refreshStatus()
+	return v
 }
 
 func (v *MCPView) Init() tea.Cmd {
@@ -114,6 +104,8 @@ func (v *MCPView) Update(msg tea.Msg) (View, tea.Cmd) {
 			v.installAllForSelectedMCP()
 		case "r":
 			v.removeSelected()
+		case "s":
+			v.toggleScope()
 		case "c":
 			v.refreshStatus()
 			v.message = "Status refreshed"
@@ -122,6 +114,22 @@ func (v *MCPView) Update(msg tea.Msg) (View, tea.Cmd) {
 	return v, nil
 }
 
+// mcpScopes are the scopes the matrix cycles through
+var mcpScopes = []agent.MCPScope{agent.MCPScopeUser, agent.MCPScopeLocal, agent.MCPScopeProject}
+
+// toggleScope switches the matrix to the next scope
+func (v *MCPView) toggleScope() {
+	next := 0
+	for i, scope := range mcpScopes {
+		if scope == v.scope {
+			next = (i + 1) % len(mcpScopes)
+		}
+	}
+	v.scope = mcpScopes[next]
+	v.refreshStatus()
+	v.message = fmt.Sprintf("Showing %s-scoped MCP servers", v.scope)
+}
+
 func buildMCPServerList(discovered map[string]agent.MCPConfigEntry) []MCPServer {
 	servers := make([]MCPServer, 0, len(embeddedMCPServers))
 	servers = append(servers, embeddedMCPServers...)
@@ -159,18 +167,31 @@ func (v *MCPView) installSelected() {
 	status := &v.agents[v.cursorCol]
 	agentName := status.Agent.Name()
 	serverName := v.servers[v.cursorRow].Name
+	if status.Unsupported {
+		v.message = fmt.Sprintf("%s has no %s-scoped MCP config", agentName, v.scope)
+		return
+	}
 	if status.Installed[serverName] {
+		if status.Specs[serverName].Disabled {
+			v.message = fmt.Sprintf("%s has %s, but it is disabled", agentName, serverName)
+			return
+		}
 		v.message = fmt.Sprintf("%s already has %s", agentName, serverName)
 		return
 	}
 
-	cfg := v.configForServer(serverName)
-	if cfg == nil {
+	spec, ok := v.specForServer(serverName)
+	if !ok {
 		v.message = fmt.Sprintf("No config found for %s", serverName)
 		return
 	}
 
-	if err := status.Agent.InstallMCP(serverName, cfg); err != nil {
+	if err := agent.CheckMCPFeatures(status.Agent, spec, v.scope); err != nil {
+		v.message = err.Error()
+		return
+	}
+	defer backup.Begin("install " + serverName + " to " + agentName).Commit()
+	if err := status.Agent.InstallMCP(serverName, spec, v.scope); err != nil {
 		v.message = fmt.Sprintf("Failed to install %s: %v", serverName, err)
 		return
 	}
@@ -181,22 +202,34 @@ func (v *MCPView) installSelected() {
 func (v *MCPView) installAllForSelectedMCP() {
 	installed := 0
 	mcpName := v.servers[v.cursorRow].Name
-	cfg := v.configForServer(mcpName)
-	if cfg == nil {
+	spec, ok := v.specForServer(mcpName)
+	if !ok {
 		v.message = fmt.Sprintf("No config found for %s", mcpName)
 		return
 	}
 
+	var failures []string
+	op := backup.Begin("install " + mcpName + " to all agents")
 	for i := range v.agents {
-		if v.agents[i].Installed[mcpName] {
+		if v.agents[i].Unsupported || v.agents[i].Installed[mcpName] {
 			continue
 		}
-		if err := v.agents[i].Agent.InstallMCP(mcpName, cfg); err == nil {
-			installed++
+		err := agent.CheckMCPFeatures(v.agents[i].Agent, spec, v.scope)
+		if err == nil {
+			err = v.agents[i].Agent.InstallMCP(mcpName, spec, v.scope)
 		}
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", v.agents[i].Agent.Name(), err))
+			continue
+		}
+		installed++
 	}
+	op.Commit()
 	v.refreshStatus()
 	v.message = fmt.Sprintf("Installed %s to %d agent(s)", mcpName, installed)
+	if len(failures) > 0 {
+		v.message += fmt.Sprintf("; failed to install to %s", strings.Join(failures, "; "))
+	}
 }
 
 func (v *MCPView) removeSelected() {
@@ -207,7 +240,8 @@ func (v *MCPView) removeSelected() {
 		v.message = fmt.Sprintf("%s doesn't have %s", agentName, serverName)
 		return
 	}
-	if err := status.Agent.RemoveMCP(serverName); err != nil {
+	defer backup.Begin("remove " + serverName + " from " + agentName).Commit()
+	if err := status.Agent.RemoveMCP(serverName, v.scope); err != nil {
 		v.message = fmt.Sprintf("Failed to remove %s: %v", serverName, err)
 		return
 	}
@@ -215,21 +249,8 @@ func (v *MCPView) removeSelected() {
 	v.refreshStatus()
 }
 
-func (v *MCPView) configForServer(name string) map[string]interface{} {
-	if entry, ok := v.serverConfigs[name]; ok && entry.Config != nil {
-		return entry.Config
-	}
-
-	switch name {
-	case "playwright":
-		return config.PlaywrightMCPConfig
-	case "context7":
-		return config.Context7MCPConfig
-	case "remix-icon":
-		return config.RemixIconMCPConfig
-	default:
-		return nil
-	}
+func (v *MCPView) specForServer(name string) (agent.MCPServerSpec, bool) {
+	return agent.ResolveMCPSpec(name, v.serverConfigs)
 }
 
 func displayMCPName(name string) string {
@@ -286,6 +307,10 @@ func (v *MCPView) View() string {
 		Foreground(lipgloss.Color("#6B7280")).
 		Width(cellWidth)
 
+	disabledStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F59E0B")).
+		Width(cellWidth)
+
 	errorStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#EF4444")).
 		Width(cellWidth)
@@ -299,7 +324,7 @@ func (v *MCPView) View() string {
 		Width(cellWidth)
 
 	// Header
-	b.WriteString(headerStyle.Render("  MCP Server Status"))
+	b.WriteString(headerStyle.Render(fmt.Sprintf("  MCP Server Status (%s)", v.scope)))
 	b.WriteString("\n")
 	b.WriteString(borderStyle.Render("  " + strings.Repeat("─", 70)))
 	b.WriteString("\n")
@@ -338,7 +363,7 @@ func (v *MCPView) View() string {
 		for agentIdx, status := range v.agents {
 			var installed bool
 			var err error
-			var notFound bool = !status.Exists
+			var notFound bool = !status.Exists || status.Unsupported
 
 			installed = status.Installed[mcp.Name]
 			err = status.Errors[mcp.Name]
@@ -349,6 +374,9 @@ func (v *MCPView) View() string {
 			if err != nil {
 				cellContent = "✗ error"
 				style = errorStyle
+			} else if installed && status.Specs[mcp.Name].Disabled {
+				cellContent = "◌ disabled"
+				style = disabledStyle
 			} else if installed {
 				cellContent = "✓ installed"
 				style = installedStyle
@@ -376,6 +404,61 @@ func (v *MCPView) View() string {
 		b.WriteString("\n")
 	}
 
+	if detail := v.selectedDetail(); detail != "" {
+		b.WriteString("\n")
+		b.WriteString(detail)
+	}
+
+	return b.String()
+}
+
+// selectedDetail describes the server under the cursor as the selected
+// agent has it, or why its status couldn't be read; it returns "" when
+// the agent doesn't have the server
+func (v *MCPView) selectedDetail() string {
+	if len(v.servers) == 0 || len(v.agents) == 0 {
+		return ""
+	}
+	status := v.agents[v.cursorCol]
+	name := v.servers[v.cursorRow].Name
+	spec, ok := status.Specs[name]
+	err := status.Errors[name]
+	if !ok && err == nil {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFFFFF"))
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#9CA3AF")).Width(15)
+	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#D1D5DB"))
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(fmt.Sprintf("  %s in %s", name, status.Agent.Name())))
+	b.WriteString("\n")
+	line := func(label, value string) {
+		b.WriteString("  " + labelStyle.Render(label) + valueStyle.Render(value) + "\n")
+	}
+	if err != nil {
+		// Such as a settings file that doesn't parse, with its position
+		line("Error", err.Error())
+		return b.String()
+	}
+	if spec.IsRemote() {
+		line("URL", spec.URL)
+		if spec.Transport != "" {
+			line("Transport", string(spec.Transport))
+		}
+	} else {
+		line("Command", strings.Join(append([]string{spec.Command}, spec.Args...), " "))
+	}
+	if spec.Timeout > 0 {
+		line("Timeout", spec.Timeout.String())
+	}
+	if spec.Disabled {
+		line("Status", "disabled")
+	}
+	if len(spec.AutoApprove) > 0 {
+		line("Auto-approve", strings.Join(spec.AutoApprove, ", "))
+	}
 	return b.String()
 }
 
@@ -407,7 +490,9 @@ func (v *MCPView) ShortHelp() []components.FooterAction {
 		{Key: "r", Label: "remove"},
 		{Key: "←→", Label: "select agent"},
 		{Key: "↑↓", Label: "select MCP"},
+		{Key: "s", Label: "scope"},
 		{Key: "c", Label: "check"},
+		{Key: "p", Label: "plan"},
 		{Key: "q", Label: "quit"},
 	}
 }
@@ -433,8 +518,15 @@ func (v *MCPView) Message() string {
 	return v.message
 }
 
+// Refresh re-reads the installation status and shows message, as after a
+// planned change that the TUI discarded
+func (v *MCPView) Refresh(message string) {
+	v.refreshStatus()
+	v.message = message
+}
+
 func (v *MCPView) refreshStatus() {
-	agents := make([]agent.Agent, 0, len(v.agents))
+	agents := make([]agent.MCPHost, 0, len(v.agents))
 	for _, status := range v.agents {
 		agents = append(agents, status.Agent)
 	}
@@ -449,13 +541,25 @@ func (v *MCPView) refreshStatus() {
 
 	for i := range v.agents {
 		v.agents[i].Installed = make(map[string]bool)
+		v.agents[i].Specs = make(map[string]agent.MCPServerSpec)
 		v.agents[i].Errors = make(map[string]error)
+		v.agents[i].Unsupported = !agent.SupportsMCPScope(v.agents[i].Agent, v.scope)
+		v.agents[i].Exists = v.agents[i].Agent.Exists()
+		if v.agents[i].Unsupported {
+			continue
+		}
 		for _, server := range v.servers {
-			ok, err := v.agents[i].Agent.HasMCP(server.Name)
+			ok, err := v.agents[i].Agent.HasMCP(server.Name, v.scope)
+			if errors.Is(err, agent.ErrUnsupported) {
+				v.agents[i].Unsupported = true
+				break
+			}
 			v.agents[i].Installed[server.Name] = ok
 			v.agents[i].Errors[server.Name] = err
 		}
-		v.agents[i].Exists = v.agents[i].Agent.Exists()
+		if specs, err := v.agents[i].Agent.ListMCPs(v.scope); err == nil {
+			v.agents[i].Specs = specs
+		}
 	}
 }
 
@@ -474,5 +578,11 @@ func (v *MCPView) GetInstalledCount() int {
 
 // GetTotalCount returns total possible installations (agents × MCP servers)
 func (v *MCPView) GetTotalCount() int {
-	return len(v.agents) * len(v.servers)
+	supporting := 0
+	for _, s := range v.agents {
+		if !s.Unsupported {
+			supporting++
+		}
+	}
+	return supporting * len(v.servers)
 }
@@ -0,0 +1,125 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/agentsdance/agentx/internal/plan"
+	"github.com/agentsdance/agentx/ui/components"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// PlanView shows the unified diff of a planned change, for review before
+// it is applied
+type PlanView struct {
+	lines  []string
+	files  int
+	dryRun bool // applying adds the change to the --dry-run plan
+	offset int
+	width  int
+	height int
+}
+
+// NewPlanView creates a view of the diff of changes
+func NewPlanView(changes []plan.Change, dryRun bool) *PlanView {
+	var diff strings.Builder
+	for _, c := range changes {
+		diff.WriteString(c.Diff())
+	}
+	return &PlanView{
+		lines:  strings.Split(strings.TrimSuffix(diff.String(), "\n"), "\n"),
+		files:  len(changes),
+		dryRun: dryRun,
+	}
+}
+
+func (v *PlanView) Init() tea.Cmd {
+	return nil
+}
+
+// Update scrolls the diff; applying or discarding it is up to the app
+func (v *PlanView) Update(msg tea.Msg) (View, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "up", "k":
+			v.offset--
+		case "down", "j":
+			v.offset++
+		case "pgup":
+			v.offset -= v.pageSize()
+		case "pgdown":
+			v.offset += v.pageSize()
+		}
+		v.offset = max(min(v.offset, len(v.lines)-v.pageSize()), 0)
+	}
+	return v, nil
+}
+
+// pageSize is how many diff lines fit under the title
+func (v *PlanView) pageSize() int {
+	return max(v.height-4, 1)
+}
+
+func (v *PlanView) View() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFFFFF"))
+	fileStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#E5E7EB"))
+	hunkStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#8B5CF6"))
+	addStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#10B981"))
+	removeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#EF4444"))
+	contextStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#9CA3AF"))
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Plan: %d file(s) would change", v.files)))
+	b.WriteString("\n\n")
+
+	end := min(v.offset+v.pageSize(), len(v.lines))
+	for _, line := range v.lines[v.offset:end] {
+		line = truncateMCPName(line, v.width-6)
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "#"):
+			line = fileStyle.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			line = hunkStyle.Render(line)
+		case strings.HasPrefix(line, "+"):
+			line = addStyle.Render(line)
+		case strings.HasPrefix(line, "-"):
+			line = removeStyle.Render(line)
+		default:
+			line = contextStyle.Render(line)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (v *PlanView) SetDimensions(width, height int) {
+	v.width = width
+	v.height = height
+}
+
+func (v *PlanView) Title() string {
+	return "Plan"
+}
+
+func (v *PlanView) ShortHelp() []components.FooterAction {
+	apply := "apply"
+	if v.dryRun {
+		apply = "add to dry run"
+	}
+	return []components.FooterAction{
+		{Key: "y/↵", Label: apply},
+		{Key: "n/esc", Label: "discard"},
+		{Key: "↑↓", Label: "scroll"},
+		{Key: "q", Label: "quit"},
+	}
+}
+
+func (v *PlanView) GetSidebarSections() []components.SidebarSection {
+	return nil
+}
+
+func (v *PlanView) Message() string {
+	return ""
+}
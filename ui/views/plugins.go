@@ -7,6 +7,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/agentsdance/agentx/internal/agent"
+	"github.com/agentsdance/agentx/internal/backup"
 	"github.com/agentsdance/agentx/internal/plugins"
 	"github.com/agentsdance/agentx/ui/components"
 	"github.com/agentsdance/agentx/ui/theme"
@@ -51,6 +52,7 @@ func InitAvailablePlugins() {
 type AgentPluginStatus struct {
 	Agent          agent.Agent
 	Exists         bool
+	Host           agent.PluginHost // nil when the agent lacks the capability
 	SupportsPlugin bool
 	PluginStatus   map[string]bool // pluginName -> installed
 	PluginError    map[string]error
@@ -75,17 +77,23 @@ func NewPluginsView() *PluginsView {
 	statuses := make([]AgentPluginStatus, len(agents))
 
 	for i, a := range agents {
+		host, _ := agent.AsPluginHost(a)
 		statuses[i] = AgentPluginStatus{
 			Agent:          a,
 			Exists:         a.Exists(),
-			SupportsPlugin: a.SupportsPlugins(),
+			Host:           host,
+			SupportsPlugin: host != nil,
 			PluginStatus:   make(map[string]bool),
 			PluginError:    make(map[string]error),
 		}
 
+		if host == nil {
+			continue
+		}
+
 		// Check each plugin
 		for _, plugin := range AvailablePlugins {
-			installed, err := a.HasPlugin(plugin.Name)
+			installed, err := host.HasPlugin(plugin.Name)
 			statuses[i].PluginStatus[plugin.Name] = installed
 			if err != nil {
 				statuses[i].PluginError[plugin.Name] = err
@@ -162,7 +170,12 @@ func (v *PluginsView) installSelected() {
 		return
 	}
 
-	if err := status.Agent.InstallPlugin(plugin.Name, plugin.Source); err != nil {
+	if err := agent.CheckFeature(status.Agent, agent.FeaturePlugins); err != nil {
+		v.message = err.Error()
+		return
+	}
+	defer backup.Begin("plugins install " + plugin.Name + " to " + agentName).Commit()
+	if err := status.Host.InstallPlugin(plugin.Name, plugin.Source); err != nil {
 		v.message = fmt.Sprintf("Failed to install %s: %v", plugin.Name, err)
 	} else {
 		v.message = fmt.Sprintf("Installed %s to %s", plugin.Name, agentName)
@@ -179,16 +192,21 @@ func (v *PluginsView) installAllForSelectedPlugin() {
 	installed := 0
 	plugin := AvailablePlugins[v.cursorRow]
 
+	op := backup.Begin("plugins install " + plugin.Name + " to all agents")
 	for i := range v.agents {
 		if !v.agents[i].SupportsPlugin {
 			continue
 		}
+		if agent.CheckFeature(v.agents[i].Agent, agent.FeaturePlugins) != nil {
+			continue
+		}
 		if !v.agents[i].PluginStatus[plugin.Name] {
-			if err := v.agents[i].Agent.InstallPlugin(plugin.Name, plugin.Source); err == nil {
+			if err := v.agents[i].Host.InstallPlugin(plugin.Name, plugin.Source); err == nil {
 				installed++
 			}
 		}
 	}
+	op.Commit()
 	v.refreshStatus()
 	v.message = fmt.Sprintf("Installed %s to %d agent(s)", plugin.Name, installed)
 }
@@ -213,7 +231,8 @@ func (v *PluginsView) removeSelected() {
 		return
 	}
 
-	if err := status.Agent.RemovePlugin(plugin.Name); err != nil {
+	defer backup.Begin("plugins remove " + plugin.Name + " from " + agentName).Commit()
+	if err := status.Host.RemovePlugin(plugin.Name); err != nil {
 		v.message = fmt.Sprintf("Failed to remove %s: %v", plugin.Name, err)
 	} else {
 		v.message = fmt.Sprintf("Removed %s from %s", plugin.Name, agentName)
@@ -364,6 +383,7 @@ func (v *PluginsView) ShortHelp() []components.FooterAction {
 		{Key: "r", Label: "remove"},
 		{Key: "arrows", Label: "navigate"},
 		{Key: "c", Label: "refresh"},
+		{Key: "p", Label: "plan"},
 		{Key: "q", Label: "quit"},
 	}
 }
@@ -389,13 +409,25 @@ func (v *PluginsView) Message() string {
 	return v.message
 }
 
+// Refresh re-reads the installation status and shows message, as after a
+// planned change that the TUI discarded
+func (v *PluginsView) Refresh(message string) {
+	v.refreshStatus()
+	v.message = message
+}
+
 func (v *PluginsView) refreshStatus() {
 	for i := range v.agents {
 		v.agents[i].Exists = v.agents[i].Agent.Exists()
-		v.agents[i].SupportsPlugin = v.agents[i].Agent.SupportsPlugins()
+		host, _ := agent.AsPluginHost(v.agents[i].Agent)
+		v.agents[i].Host = host
+		v.agents[i].SupportsPlugin = host != nil
+		if host == nil {
+			continue
+		}
 
 		for _, plugin := range AvailablePlugins {
-			installed, err := v.agents[i].Agent.HasPlugin(plugin.Name)
+			installed, err := host.HasPlugin(plugin.Name)
 			v.agents[i].PluginStatus[plugin.Name] = installed
 			if err != nil {
 				v.agents[i].PluginError[plugin.Name] = err
@@ -7,6 +7,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/agentsdance/agentx/internal/agent"
+	"github.com/agentsdance/agentx/internal/backup"
 	"github.com/agentsdance/agentx/internal/skills"
 	"github.com/agentsdance/agentx/ui/components"
 	"github.com/agentsdance/agentx/ui/theme"
@@ -58,6 +59,7 @@ func InitAvailableSkills() {
 type AgentSkillStatus struct {
 	Agent         agent.Agent
 	Exists        bool
+	Host          agent.SkillHost // nil when the agent lacks the capability
 	SupportsSkill bool
 	SkillStatus   map[string]bool // skillName -> installed
 	SkillError    map[string]error
@@ -82,17 +84,23 @@ func NewSkillsView() *SkillsView {
 	statuses := make([]AgentSkillStatus, len(agents))
 
 	for i, a := range agents {
+		host, _ := agent.AsSkillHost(a)
 		statuses[i] = AgentSkillStatus{
 			Agent:         a,
 			Exists:        a.Exists(),
-			SupportsSkill: a.SupportsSkills(),
+			Host:          host,
+			SupportsSkill: host != nil,
 			SkillStatus:   make(map[string]bool),
 			SkillError:    make(map[string]error),
 		}
 
+		if host == nil {
+			continue
+		}
+
 		// Check each skill
 		for _, skill := range AvailableSkills {
-			installed, err := a.HasSkill(skill.Name)
+			installed, err := host.HasSkill(skill.Name)
 			statuses[i].SkillStatus[skill.Name] = installed
 			if err != nil {
 				statuses[i].SkillError[skill.Name] = err
@@ -170,7 +178,12 @@ func (v *SkillsView) installSelected() {
 		return
 	}
 
-	if err := status.Agent.InstallSkill(skill.Name, skill.Source); err != nil {
+	if err := agent.CheckFeature(status.Agent, agent.FeatureSkills); err != nil {
+		v.message = err.Error()
+		return
+	}
+	defer backup.Begin("skills install " + skill.Name + " to " + agentName).Commit()
+	if err := status.Host.InstallSkill(skill.Name, skill.Source); err != nil {
 		v.message = fmt.Sprintf("Failed to install %s: %v", skill.Name, err)
 	} else {
 		v.message = fmt.Sprintf("Installed %s to %s", skill.Name, agentName)
@@ -187,16 +200,21 @@ func (v *SkillsView) installAllForSelectedSkill() {
 	installed := 0
 	skill := AvailableSkills[v.cursorRow]
 
+	op := backup.Begin("skills install " + skill.Name + " to all agents")
 	for i := range v.agents {
 		if !v.agents[i].SupportsSkill {
 			continue
 		}
+		if agent.CheckFeature(v.agents[i].Agent, agent.FeatureSkills) != nil {
+			continue
+		}
 		if !v.agents[i].SkillStatus[skill.Name] {
-			if err := v.agents[i].Agent.InstallSkill(skill.Name, skill.Source); err == nil {
+			if err := v.agents[i].Host.InstallSkill(skill.Name, skill.Source); err == nil {
 				installed++
 			}
 		}
 	}
+	op.Commit()
 	v.refreshStatus()
 	v.message = fmt.Sprintf("Installed %s to %d agent(s)", skill.Name, installed)
 }
@@ -221,7 +239,8 @@ func (v *SkillsView) removeSelected() {
 		return
 	}
 
-	if err := status.Agent.RemoveSkill(skill.Name); err != nil {
+	defer backup.Begin("skills remove " + skill.Name + " from " + agentName).Commit()
+	if err := status.Host.RemoveSkill(skill.Name); err != nil {
 		v.message = fmt.Sprintf("Failed to remove %s: %v", skill.Name, err)
 	} else {
 		v.message = fmt.Sprintf("Removed %s from %s", skill.Name, agentName)
@@ -372,6 +391,7 @@ func (v *SkillsView) ShortHelp() []components.FooterAction {
 		{Key: "↑↓", Label: "select skill"},
 		{Key: "c", Label: "check"},
 		{Key: "R", Label: "refresh"},
+		{Key: "p", Label: "plan"},
 		{Key: "q", Label: "quit"},
 	}
 }
@@ -397,13 +417,25 @@ func (v *SkillsView) Message() string {
 	return v.message
 }
 
+// Refresh re-reads the installation status and shows message, as after a
+// planned change that the TUI discarded
+func (v *SkillsView) Refresh(message string) {
+	v.refreshStatus()
+	v.message = message
+}
+
 func (v *SkillsView) refreshStatus() {
 	for i := range v.agents {
 		v.agents[i].Exists = v.agents[i].Agent.Exists()
-		v.agents[i].SupportsSkill = v.agents[i].Agent.SupportsSkills()
+		host, _ := agent.AsSkillHost(v.agents[i].Agent)
+		v.agents[i].Host = host
+		v.agents[i].SupportsSkill = host != nil
+		if host == nil {
+			continue
+		}
 
 		for _, skill := range AvailableSkills {
-			installed, err := v.agents[i].Agent.HasSkill(skill.Name)
+			installed, err := host.HasSkill(skill.Name)
 			v.agents[i].SkillStatus[skill.Name] = installed
 			if err != nil {
 				v.agents[i].SkillError[skill.Name] = err